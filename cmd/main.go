@@ -1,12 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/scheduler"
 	"github.com/Caknoooo/go-gin-clean-starter/providers"
 	"github.com/Caknoooo/go-gin-clean-starter/script"
 	"github.com/samber/do"
@@ -24,7 +36,29 @@ func args(injector *do.Injector) bool {
 	return true
 }
 
-func run(server *gin.Engine) {
+// startScheduler registers every module's jobs and starts running them,
+// unless SCHEDULER_ENABLED is false.
+func startScheduler(injector *do.Injector) {
+	if os.Getenv("SCHEDULER_ENABLED") == "false" {
+		return
+	}
+
+	jobScheduler := do.MustInvokeNamed[*scheduler.Scheduler](injector, constants.Scheduler)
+	if err := auth.RegisterJobs(jobScheduler, injector); err != nil {
+		log.Fatalf("error registering jobs: %v", err)
+	}
+	if err := user.RegisterJobs(jobScheduler, injector); err != nil {
+		log.Fatalf("error registering jobs: %v", err)
+	}
+
+	if err := jobScheduler.Start(); err != nil {
+		log.Fatalf("error starting scheduler: %v", err)
+	}
+}
+
+// run serves until SIGINT or SIGTERM, then drains in-flight requests and shuts
+// down the injector, which stops the scheduler and background workers.
+func run(server *gin.Engine, injector *do.Injector) {
 	server.Static("/assets", "./assets")
 
 	port := os.Getenv("GOLANG_PORT")
@@ -42,8 +76,32 @@ func run(server *gin.Engine) {
 	myFigure := figure.NewColorFigure("Caknoo", "", "green", true)
 	myFigure.Print()
 
-	if err := server.Run(serve); err != nil {
-		log.Fatalf("error running server: %v", err)
+	httpServer := &http.Server{
+		Addr:    serve,
+		Handler: server,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("error running server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("error shutting down server: %v", err)
+	}
+
+	if err := injector.Shutdown(); err != nil {
+		log.Printf("error shutting down: %v", err)
 	}
 }
 
@@ -64,6 +122,12 @@ func main() {
 	// Register module routes
 	user.RegisterRoutes(server, injector)
 	auth.RegisterRoutes(server, injector)
+	admin.RegisterRoutes(server, injector)
+	invitation.RegisterRoutes(server, injector)
+	rbac.RegisterRoutes(server, injector)
+	organization.RegisterRoutes(server, injector)
+
+	startScheduler(injector)
 
-	run(server)
+	run(server, injector)
 }
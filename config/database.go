@@ -32,7 +32,8 @@ func SetUpDatabaseConnection() *gorm.DB {
 		DSN:                  dsn,
 		PreferSimpleProtocol: true,
 	}), &gorm.Config{
-		Logger: SetupLogger(),
+		Logger:         SetupLogger(),
+		TranslateError: true,
 	})
 	if err != nil {
 		panic(err)
@@ -55,7 +56,9 @@ func SetUpTestDatabaseConnection() *gorm.DB {
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN:                  dsn,
 		PreferSimpleProtocol: true,
-	}), &gorm.Config{})
+	}), &gorm.Config{
+		TranslateError: true,
+	})
 	if err != nil {
 		panic(err)
 	}
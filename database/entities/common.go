@@ -2,6 +2,8 @@ package entities
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Timestamp struct {
@@ -9,7 +11,13 @@ type Timestamp struct {
 	UpdatedAt time.Time `gorm:"type:timestamp with time zone" json:"updated_at"`
 }
 
+// SoftDelete makes GORM mark rows as deleted instead of removing them. Queries
+// skip deleted rows unless they are made with Unscoped.
+type SoftDelete struct {
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
 type Authorization struct {
 	Token string `json:"token" binding:"required"`
-	Role  string `json:"role" binding:"required,oneof=user admin"`
+	Role  string `json:"role" binding:"required,max=50"`
 }
@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invitation lets someone register with a pre-assigned role. Like other
+// single-use secrets only the SHA-256 hash of the token is stored; the raw
+// value is emailed to the invitee.
+type Invitation struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Email          string     `gorm:"type:varchar(255);not null;index" json:"email"`
+	Role           string     `gorm:"type:varchar(50);not null;default:'user'" json:"role"`
+	TokenHash      string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	InvitedByID    *uuid.UUID `gorm:"type:uuid;index" json:"invited_by_id"`
+	ExpiresAt      time.Time  `gorm:"type:timestamp with time zone;not null" json:"expires_at"`
+	AcceptedAt     *time.Time `gorm:"type:timestamp with time zone" json:"accepted_at"`
+	AcceptedUserID *uuid.UUID `gorm:"type:uuid" json:"accepted_user_id"`
+	RevokedAt      *time.Time `gorm:"type:timestamp with time zone" json:"revoked_at"`
+	InvitedBy      *User      `gorm:"foreignKey:InvitedByID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;" json:"-"`
+
+	Timestamp
+}
@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"github.com/google/uuid"
+)
+
+// LoginAttempt records a failed password login. Rows are counted per email
+// and per client IP to throttle guessing, whether or not the email exists.
+type LoginAttempt struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Email     string    `gorm:"type:varchar(255);not null;index" json:"email"`
+	IPAddress string    `gorm:"type:varchar(45);not null;index" json:"ip_address"`
+
+	Timestamp
+}
@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthState remembers an authorization request between the redirect to the
+// provider and its callback. The state parameter itself is only stored as a
+// SHA-256 hash.
+type OAuthState struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	StateHash    string    `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Provider     string    `gorm:"type:varchar(50);not null" json:"provider"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null" json:"-"`
+	Nonce        string    `gorm:"type:varchar(128);not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"type:timestamp with time zone;not null;index" json:"expires_at"`
+
+	Timestamp
+}
@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OneTimeToken is a single-use secret bound to a user and a purpose
+// (email verification, password reset, ...). Only the SHA-256 hash of the
+// token is stored; the raw value is sent to the user once.
+type OneTimeToken struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose    string     `gorm:"type:varchar(50);not null;index" json:"purpose"`
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt  time.Time  `gorm:"type:timestamp with time zone;not null" json:"expires_at"`
+	ConsumedAt *time.Time `gorm:"type:timestamp with time zone" json:"consumed_at"`
+	Attempts   int        `gorm:"not null;default:0" json:"attempts"`
+	// Payload binds the token to a value, e.g. the address an email change
+	// confirmation was sent to.
+	Payload string `gorm:"type:varchar(255);not null;default:''" json:"-"`
+	User    User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
@@ -0,0 +1,16 @@
+package entities
+
+import (
+	"github.com/google/uuid"
+)
+
+// PasswordHistory keeps the hashes of a user's previous passwords so the
+// password policy can refuse to reuse them.
+type PasswordHistory struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
+	User         User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
@@ -0,0 +1,25 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalAccessToken is a long-lived API key a user creates for scripts and
+// integrations. Only the SHA-256 hash of the secret is stored; Prefix keeps
+// the first characters so users can tell their tokens apart.
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"`
+	Scopes     []string   `gorm:"type:text;serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time `gorm:"type:timestamp with time zone" json:"expires_at"`
+	LastUsedAt *time.Time `gorm:"type:timestamp with time zone" json:"last_used_at"`
+	LastUsedIP string     `gorm:"type:varchar(45)" json:"last_used_ip"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
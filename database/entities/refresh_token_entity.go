@@ -6,12 +6,29 @@ import (
 	"github.com/google/uuid"
 )
 
+// RefreshToken belongs to a family: every rotation creates a new token with
+// the same FamilyID and marks the previous one as rotated. Presenting a
+// rotated token again means it was replayed, and the whole family is revoked.
+// Only the keyed hash of the token is stored; the raw value is returned to the
+// client once and never persisted. A family is exposed to users as a session,
+// so the device metadata is carried over on every rotation.
 type RefreshToken struct {
-	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
-	Token     string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"token"`
-	ExpiresAt time.Time `gorm:"type:timestamp with time zone;not null" json:"expires_at"`
-	User      User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	FamilyID  uuid.UUID  `gorm:"type:uuid;index" json:"family_id"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"type:timestamp with time zone;not null" json:"expires_at"`
+	RotatedAt *time.Time `gorm:"type:timestamp with time zone" json:"rotated_at"`
+	User      User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Name             string     `gorm:"type:varchar(100)" json:"name"`
+	UserAgent        string     `gorm:"type:varchar(255)" json:"user_agent"`
+	IPAddress        string     `gorm:"type:varchar(45)" json:"ip_address"`
+	SessionCreatedAt time.Time  `gorm:"type:timestamp with time zone" json:"session_created_at"`
+	LastUsedAt       *time.Time `gorm:"type:timestamp with time zone" json:"last_used_at"`
+	// OrganizationID is the active organization of the session, carried in
+	// the access tokens it issues.
+	OrganizationID *uuid.UUID `gorm:"type:uuid" json:"organization_id"`
 
 	Timestamp
 }
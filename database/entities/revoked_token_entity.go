@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"time"
+)
+
+// RevokedToken is a denylist entry used by the database revocation store.
+// Key identifies what was revoked (a token id, a session or a user) and the
+// row is useless once ExpiresAt passes, because every token it could match
+// has expired by then.
+type RevokedToken struct {
+	Key       string    `gorm:"type:varchar(255);primaryKey" json:"key"`
+	RevokedAt time.Time `gorm:"type:timestamp with time zone;not null" json:"revoked_at"`
+	ExpiresAt time.Time `gorm:"type:timestamp with time zone;not null;index" json:"expires_at"`
+}
@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is a named set of permissions. A user's primary role is User.Role,
+// referencing Role.Name; UserRole grants additional roles. System roles are
+// the built-in ones seeded from constants.RolePermissions.
+type Role struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name        string    `gorm:"type:varchar(50);not null;uniqueIndex" json:"name"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	IsSystem    bool      `gorm:"not null;default:false" json:"is_system"`
+
+	Timestamp
+}
+
+// Permission is a permission string declared in constants.Permissions, such
+// as "users:write".
+type Permission struct {
+	ID   uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+
+	Timestamp
+}
+
+type RolePermission struct {
+	RoleID       uuid.UUID  `gorm:"type:uuid;primaryKey" json:"role_id"`
+	PermissionID uuid.UUID  `gorm:"type:uuid;primaryKey;index" json:"permission_id"`
+	CreatedAt    time.Time  `gorm:"type:timestamp with time zone" json:"created_at"`
+	Role         Role       `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Permission   Permission `gorm:"foreignKey:PermissionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+type UserRole struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	RoleID    uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"role_id"`
+	CreatedAt time.Time `gorm:"type:timestamp with time zone" json:"created_at"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	Role      Role      `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
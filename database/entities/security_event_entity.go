@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"github.com/google/uuid"
+)
+
+// SecurityEvent is an append-only record of security relevant activity such
+// as refresh token reuse. Rows are kept after the user is deleted.
+type SecurityEvent struct {
+	ID     uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	// ActorID is the user who caused the event when it is not UserID, such as
+	// an admin impersonating them.
+	ActorID   *uuid.UUID `gorm:"type:uuid;index" json:"actor_id"`
+	Type      string     `gorm:"type:varchar(50);not null;index" json:"type"`
+	IPAddress string     `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent string     `gorm:"type:varchar(255)" json:"user_agent"`
+	Details   string     `gorm:"type:text" json:"details"`
+
+	Timestamp
+}
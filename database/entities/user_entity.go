@@ -1,8 +1,9 @@
 package entities
 
 import (
+	"time"
+
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
-	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -10,26 +11,28 @@ import (
 type User struct {
 	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
 	Name       string    `gorm:"type:varchar(100);not null" json:"name"`
-	Email      string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	Email      string    `gorm:"type:varchar(255);uniqueIndex:idx_users_email_active,where:deleted_at IS NULL;not null" json:"email"`
 	TelpNumber string    `gorm:"type:varchar(20);index" json:"telp_number"`
 	Password   string    `gorm:"type:varchar(255);not null" json:"password"`
 	Role       string    `gorm:"type:varchar(50);not null;default:'user'" json:"role"`
 	ImageUrl   string    `gorm:"type:varchar(255)" json:"image_url"`
 	IsVerified bool      `gorm:"default:false" json:"is_verified"`
+	IsActive   bool      `gorm:"not null;default:true" json:"is_active"`
+	// DisabledAt and DisabledReason record when and why an admin disabled
+	// the account. Both are cleared when it is enabled again.
+	DisabledAt     *time.Time `gorm:"type:timestamp with time zone" json:"disabled_at"`
+	DisabledReason *string    `gorm:"type:varchar(255)" json:"disabled_reason"`
+	// PendingEmail is the new address of a requested email change until it is
+	// confirmed through the link sent to it.
+	PendingEmail *string `gorm:"type:varchar(255)" json:"pending_email"`
 
 	Timestamp
+	SoftDelete
 }
 
-// BeforeCreate hook to hash password and set defaults
+// BeforeCreate hook to set defaults. Passwords must already be hashed with
+// helpers.HashPassword.
 func (u *User) BeforeCreate(_ *gorm.DB) (err error) {
-	// Hash password
-	if u.Password != "" {
-		u.Password, err = helpers.HashPassword(u.Password)
-		if err != nil {
-			return err
-		}
-	}
-
 	// Ensure UUID is set
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"github.com/google/uuid"
+)
+
+// UserIdentity links a user to an account at an external OAuth/OIDC
+// provider, identified by the provider's stable subject id.
+type UserIdentity struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject  string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"subject"`
+	Email    string    `gorm:"type:varchar(255)" json:"email"`
+	User     User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
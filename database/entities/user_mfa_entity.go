@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA holds a user's TOTP secret, encrypted at rest. Two-factor
+// authentication is only enforced once ConfirmedAt is set.
+type UserMFA struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	SecretEncrypted string     `gorm:"type:text;not null" json:"-"`
+	ConfirmedAt     *time.Time `gorm:"type:timestamp with time zone" json:"confirmed_at"`
+	LastUsedStep    int64      `gorm:"not null;default:0" json:"-"`
+	// FailedAttempts counts wrong codes since LastFailedAt began a new
+	// lockout window, across every challenge of the user.
+	FailedAttempts int        `gorm:"not null;default:0" json:"-"`
+	LastFailedAt   *time.Time `gorm:"type:timestamp with time zone" json:"-"`
+	User           User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode is a single-use fallback for a lost authenticator. Only the
+// SHA-256 hash of the code is stored.
+type MFARecoveryCode struct {
+	ID       uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt   *time.Time `gorm:"type:timestamp with time zone" json:"used_at"`
+	User     User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+
+	Timestamp
+}
@@ -10,6 +10,24 @@ func Migrate(db *gorm.DB) error {
 		&entities.Migration{},
 		&entities.User{},
 		&entities.RefreshToken{},
+		&entities.OneTimeToken{},
+		&entities.SecurityEvent{},
+		&entities.RevokedToken{},
+		&entities.UserMFA{},
+		&entities.MFARecoveryCode{},
+		&entities.LoginAttempt{},
+		&entities.UserIdentity{},
+		&entities.OAuthState{},
+		&entities.PersonalAccessToken{},
+		&entities.PasswordHistory{},
+		&entities.Invitation{},
+		&entities.Role{},
+		&entities.Permission{},
+		&entities.RolePermission{},
+		&entities.UserRole{},
+		&entities.Organization{},
+		&entities.OrganizationMember{},
+		&entities.OrganizationInvitation{},
 	); err != nil {
 		return err
 	}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018090000_create_one_time_tokens_table", Up20261018090000CreateOneTimeTokensTable, Down20261018090000CreateOneTimeTokensTable)
+}
+
+func Up20261018090000CreateOneTimeTokensTable(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.OneTimeToken{})
+}
+
+func Down20261018090000CreateOneTimeTokensTable(db *gorm.DB) error {
+	return db.Migrator().DropTable(&entities.OneTimeToken{})
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018100000_add_family_to_refresh_tokens", Up20261018100000AddFamilyToRefreshTokens, Down20261018100000AddFamilyToRefreshTokens)
+}
+
+func Up20261018100000AddFamilyToRefreshTokens(db *gorm.DB) error {
+	if err := db.AutoMigrate(&entities.RefreshToken{}); err != nil {
+		return err
+	}
+
+	// Existing tokens each start their own family.
+	return db.Exec("UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL").Error
+}
+
+func Down20261018100000AddFamilyToRefreshTokens(db *gorm.DB) error {
+	if err := db.Migrator().DropColumn(&entities.RefreshToken{}, "rotated_at"); err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&entities.RefreshToken{}, "family_id")
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018100001_create_security_events_table", Up20261018100001CreateSecurityEventsTable, Down20261018100001CreateSecurityEventsTable)
+}
+
+func Up20261018100001CreateSecurityEventsTable(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.SecurityEvent{})
+}
+
+func Down20261018100001CreateSecurityEventsTable(db *gorm.DB) error {
+	return db.Migrator().DropTable(&entities.SecurityEvent{})
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018110000_hash_refresh_tokens", Up20261018110000HashRefreshTokens, Down20261018110000HashRefreshTokens)
+}
+
+// Up20261018110000HashRefreshTokens replaces the plaintext token column with
+// token_hash. Stored plaintext tokens are treated as compromised and deleted,
+// so affected users have to log in again.
+func Up20261018110000HashRefreshTokens(db *gorm.DB) error {
+	if err := db.AutoMigrate(&entities.RefreshToken{}); err != nil {
+		return err
+	}
+
+	if !db.Migrator().HasColumn(&entities.RefreshToken{}, "token") {
+		return nil
+	}
+
+	if err := db.Exec("DELETE FROM refresh_tokens WHERE token_hash IS NULL").Error; err != nil {
+		return err
+	}
+
+	return db.Migrator().DropColumn(&entities.RefreshToken{}, "token")
+}
+
+// Down20261018110000HashRefreshTokens cannot recover raw tokens, it only
+// restores the column and drops every session.
+func Down20261018110000HashRefreshTokens(db *gorm.DB) error {
+	if err := db.Exec("DELETE FROM refresh_tokens").Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS token varchar(255)").Error; err != nil {
+		return err
+	}
+
+	return db.Migrator().DropColumn(&entities.RefreshToken{}, "token_hash")
+}
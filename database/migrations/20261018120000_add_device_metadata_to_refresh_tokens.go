@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018120000_add_device_metadata_to_refresh_tokens", Up20261018120000AddDeviceMetadataToRefreshTokens, Down20261018120000AddDeviceMetadataToRefreshTokens)
+}
+
+func Up20261018120000AddDeviceMetadataToRefreshTokens(db *gorm.DB) error {
+	if err := db.AutoMigrate(&entities.RefreshToken{}); err != nil {
+		return err
+	}
+
+	return db.Exec("UPDATE refresh_tokens SET session_created_at = created_at WHERE session_created_at IS NULL").Error
+}
+
+func Down20261018120000AddDeviceMetadataToRefreshTokens(db *gorm.DB) error {
+	for _, column := range []string{"name", "user_agent", "ip_address", "session_created_at", "last_used_at"} {
+		if err := db.Migrator().DropColumn(&entities.RefreshToken{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
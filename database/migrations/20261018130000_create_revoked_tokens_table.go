@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018130000_create_revoked_tokens_table", Up20261018130000CreateRevokedTokensTable, Down20261018130000CreateRevokedTokensTable)
+}
+
+func Up20261018130000CreateRevokedTokensTable(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.RevokedToken{})
+}
+
+func Down20261018130000CreateRevokedTokensTable(db *gorm.DB) error {
+	return db.Migrator().DropTable(&entities.RevokedToken{})
+}
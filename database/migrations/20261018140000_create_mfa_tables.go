@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018140000_create_mfa_tables", Up20261018140000CreateMFATables, Down20261018140000CreateMFATables)
+}
+
+func Up20261018140000CreateMFATables(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.UserMFA{}, &entities.MFARecoveryCode{}, &entities.OneTimeToken{})
+}
+
+func Down20261018140000CreateMFATables(db *gorm.DB) error {
+	if err := db.Migrator().DropColumn(&entities.OneTimeToken{}, "attempts"); err != nil {
+		return err
+	}
+	return db.Migrator().DropTable(&entities.MFARecoveryCode{}, &entities.UserMFA{})
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018150000_create_login_attempts_table", Up20261018150000CreateLoginAttemptsTable, Down20261018150000CreateLoginAttemptsTable)
+}
+
+func Up20261018150000CreateLoginAttemptsTable(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.LoginAttempt{})
+}
+
+func Down20261018150000CreateLoginAttemptsTable(db *gorm.DB) error {
+	return db.Migrator().DropTable(&entities.LoginAttempt{})
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018160000_create_oauth_tables", Up20261018160000CreateOAuthTables, Down20261018160000CreateOAuthTables)
+}
+
+func Up20261018160000CreateOAuthTables(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.UserIdentity{}, &entities.OAuthState{})
+}
+
+func Down20261018160000CreateOAuthTables(db *gorm.DB) error {
+	return db.Migrator().DropTable(&entities.OAuthState{}, &entities.UserIdentity{})
+}
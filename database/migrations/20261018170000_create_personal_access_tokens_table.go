@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018170000_create_personal_access_tokens_table", Up20261018170000CreatePersonalAccessTokensTable, Down20261018170000CreatePersonalAccessTokensTable)
+}
+
+func Up20261018170000CreatePersonalAccessTokensTable(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.PersonalAccessToken{})
+}
+
+func Down20261018170000CreatePersonalAccessTokensTable(db *gorm.DB) error {
+	return db.Migrator().DropTable(&entities.PersonalAccessToken{})
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018180000_create_password_histories_table", Up20261018180000CreatePasswordHistoriesTable, Down20261018180000CreatePasswordHistoriesTable)
+}
+
+func Up20261018180000CreatePasswordHistoriesTable(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.PasswordHistory{})
+}
+
+func Down20261018180000CreatePasswordHistoriesTable(db *gorm.DB) error {
+	return db.Migrator().DropTable(&entities.PasswordHistory{})
+}
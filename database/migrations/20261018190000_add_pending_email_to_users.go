@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018190000_add_pending_email_to_users", Up20261018190000AddPendingEmailToUsers, Down20261018190000AddPendingEmailToUsers)
+}
+
+func Up20261018190000AddPendingEmailToUsers(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.User{}, &entities.OneTimeToken{})
+}
+
+func Down20261018190000AddPendingEmailToUsers(db *gorm.DB) error {
+	if err := db.Migrator().DropColumn(&entities.OneTimeToken{}, "payload"); err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&entities.User{}, "pending_email")
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018200000_add_is_active_to_users", Up20261018200000AddIsActiveToUsers, Down20261018200000AddIsActiveToUsers)
+}
+
+func Up20261018200000AddIsActiveToUsers(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.User{})
+}
+
+func Down20261018200000AddIsActiveToUsers(db *gorm.DB) error {
+	return db.Migrator().DropColumn(&entities.User{}, "is_active")
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018210000_add_soft_delete_to_users", Up20261018210000AddSoftDeleteToUsers, Down20261018210000AddSoftDeleteToUsers)
+}
+
+// Up20261018210000AddSoftDeleteToUsers adds deleted_at and replaces the
+// unique email index with one that ignores deleted users, so their address
+// can be registered again.
+func Up20261018210000AddSoftDeleteToUsers(db *gorm.DB) error {
+	if err := db.AutoMigrate(&entities.User{}); err != nil {
+		return err
+	}
+
+	if db.Migrator().HasIndex(&entities.User{}, "idx_users_email") {
+		return db.Migrator().DropIndex(&entities.User{}, "idx_users_email")
+	}
+
+	return nil
+}
+
+func Down20261018210000AddSoftDeleteToUsers(db *gorm.DB) error {
+	if err := db.Exec("DELETE FROM users WHERE deleted_at IS NOT NULL").Error; err != nil {
+		return err
+	}
+
+	if err := db.Migrator().DropIndex(&entities.User{}, "idx_users_email_active"); err != nil {
+		return err
+	}
+
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email)").Error; err != nil {
+		return err
+	}
+
+	return db.Migrator().DropColumn(&entities.User{}, "deleted_at")
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018220000_add_actor_to_security_events", Up20261018220000AddActorToSecurityEvents, Down20261018220000AddActorToSecurityEvents)
+}
+
+func Up20261018220000AddActorToSecurityEvents(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.SecurityEvent{})
+}
+
+func Down20261018220000AddActorToSecurityEvents(db *gorm.DB) error {
+	return db.Migrator().DropColumn(&entities.SecurityEvent{}, "actor_id")
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018230000_create_invitations_table", Up20261018230000CreateInvitationsTable, Down20261018230000CreateInvitationsTable)
+}
+
+func Up20261018230000CreateInvitationsTable(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.Invitation{})
+}
+
+func Down20261018230000CreateInvitationsTable(db *gorm.DB) error {
+	return db.Migrator().DropTable(&entities.Invitation{})
+}
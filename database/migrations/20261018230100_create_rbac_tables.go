@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/database/seeders/seeds"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018230100_create_rbac_tables", Up20261018230100CreateRBACTables, Down20261018230100CreateRBACTables)
+}
+
+// Up20261018230100CreateRBACTables also stores the built-in roles, which the
+// role column of existing users refers to. Without them nobody, admins
+// included, would be granted any permission.
+func Up20261018230100CreateRBACTables(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&entities.Role{},
+		&entities.Permission{},
+		&entities.RolePermission{},
+		&entities.UserRole{},
+	); err != nil {
+		return err
+	}
+
+	return seeds.RBACSeeder(db)
+}
+
+func Down20261018230100CreateRBACTables(db *gorm.DB) error {
+	return db.Migrator().DropTable(
+		&entities.UserRole{},
+		&entities.RolePermission{},
+		&entities.Permission{},
+		&entities.Role{},
+	)
+}
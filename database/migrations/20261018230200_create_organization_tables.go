@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018230200_create_organization_tables", Up20261018230200CreateOrganizationTables, Down20261018230200CreateOrganizationTables)
+}
+
+func Up20261018230200CreateOrganizationTables(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&entities.Organization{},
+		&entities.OrganizationMember{},
+		&entities.OrganizationInvitation{},
+		&entities.RefreshToken{},
+	)
+}
+
+func Down20261018230200CreateOrganizationTables(db *gorm.DB) error {
+	if err := db.Migrator().DropColumn(&entities.RefreshToken{}, "organization_id"); err != nil {
+		return err
+	}
+
+	return db.Migrator().DropTable(
+		&entities.OrganizationInvitation{},
+		&entities.OrganizationMember{},
+		&entities.Organization{},
+	)
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018230300_add_disabled_state_to_users", Up20261018230300AddDisabledStateToUsers, Down20261018230300AddDisabledStateToUsers)
+}
+
+func Up20261018230300AddDisabledStateToUsers(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.User{})
+}
+
+func Down20261018230300AddDisabledStateToUsers(db *gorm.DB) error {
+	for _, column := range []string{"disabled_reason", "disabled_at"} {
+		if err := db.Migrator().DropColumn(&entities.User{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
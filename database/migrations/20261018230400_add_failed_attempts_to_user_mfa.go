@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/database"
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+func init() {
+	database.RegisterMigration("20261018230400_add_failed_attempts_to_user_mfa", Up20261018230400AddFailedAttemptsToUserMFA, Down20261018230400AddFailedAttemptsToUserMFA)
+}
+
+func Up20261018230400AddFailedAttemptsToUserMFA(db *gorm.DB) error {
+	return db.AutoMigrate(&entities.UserMFA{})
+}
+
+func Down20261018230400AddFailedAttemptsToUserMFA(db *gorm.DB) error {
+	for _, column := range []string{"last_failed_at", "failed_attempts"} {
+		if err := db.Migrator().DropColumn(&entities.UserMFA{}, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
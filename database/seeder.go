@@ -6,6 +6,10 @@ import (
 )
 
 func Seeder(db *gorm.DB) error {
+	if err := seeds.RBACSeeder(db); err != nil {
+		return err
+	}
+
 	if err := seeds.ListUserSeeder(db); err != nil {
 		return err
 	}
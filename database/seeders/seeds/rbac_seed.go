@@ -0,0 +1,59 @@
+package seeds
+
+import (
+	"slices"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RBACSeeder stores every declared permission and the built-in roles with
+// their permissions. It is safe to run repeatedly, e.g. after a module
+// declares new permissions; custom roles are left untouched.
+func RBACSeeder(db *gorm.DB) error {
+	names := append([]string{constants.PERMISSION_ALL}, constants.Permissions...)
+	for _, name := range names {
+		permission := entities.Permission{ID: uuid.New(), Name: name}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&permission).Error; err != nil {
+			return err
+		}
+	}
+
+	roleNames := make([]string, 0, len(constants.RolePermissions))
+	for name := range constants.RolePermissions {
+		roleNames = append(roleNames, name)
+	}
+	slices.Sort(roleNames)
+
+	for _, name := range roleNames {
+		role := entities.Role{ID: uuid.New(), Name: name, IsSystem: true}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&role).Error; err != nil {
+			return err
+		}
+		if err := db.Where("name = ?", name).Take(&role).Error; err != nil {
+			return err
+		}
+
+		grants := constants.RolePermissions[name]
+		if len(grants) == 0 {
+			continue
+		}
+
+		var permissions []entities.Permission
+		if err := db.Where("name IN ?", grants).Find(&permissions).Error; err != nil {
+			return err
+		}
+
+		for _, permission := range permissions {
+			rolePermission := entities.RolePermission{RoleID: role.ID, PermissionID: permission.ID}
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rolePermission).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
 	"gorm.io/gorm"
 )
 
@@ -42,6 +43,11 @@ func ListUserSeeder(db *gorm.DB) error {
 
 		isData := db.Find(&user, "email = ?", data.Email).RowsAffected
 		if isData == 0 {
+			data.Password, err = helpers.HashPassword(data.Password)
+			if err != nil {
+				return err
+			}
+
 			if err := db.Create(&data).Error; err != nil {
 				return err
 			}
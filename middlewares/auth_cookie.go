@@ -0,0 +1,205 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/gin-gonic/gin"
+)
+
+// refreshTokenCookiePath limits the refresh token cookie to the endpoints
+// that consume it, /api/auth/refresh and /api/auth/logout.
+const refreshTokenCookiePath = "/api/auth"
+
+// oauthCookiePath limits the OAuth state cookie to the OAuth endpoints.
+const oauthCookiePath = "/api/auth/oauth"
+
+// AuthCookies delivers login tokens as cookies for browser clients. It is
+// configured by AUTH_TOKEN_TRANSPORT: "bearer" (default) returns tokens in the
+// response body only, "cookie" only sets HttpOnly cookies and "both" does
+// both. Cookies are Secure unless AUTH_COOKIE_SECURE=false, use
+// AUTH_COOKIE_SAMESITE (lax, strict or none; default lax) and are scoped to
+// AUTH_COOKIE_DOMAIN when set.
+type AuthCookies struct {
+	transport string
+	domain    string
+	secure    bool
+	sameSite  http.SameSite
+}
+
+func NewAuthCookies() *AuthCookies {
+	transport := os.Getenv("AUTH_TOKEN_TRANSPORT")
+	if transport != constants.TOKEN_TRANSPORT_COOKIE && transport != constants.TOKEN_TRANSPORT_BOTH {
+		transport = constants.TOKEN_TRANSPORT_BEARER
+	}
+
+	sameSite := http.SameSiteLaxMode
+	switch strings.ToLower(os.Getenv("AUTH_COOKIE_SAMESITE")) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+
+	return &AuthCookies{
+		transport: transport,
+		domain:    os.Getenv("AUTH_COOKIE_DOMAIN"),
+		secure:    os.Getenv("AUTH_COOKIE_SECURE") != "false",
+		sameSite:  sameSite,
+	}
+}
+
+// Enabled reports whether logins set cookies.
+func (c *AuthCookies) Enabled() bool {
+	return c.transport != constants.TOKEN_TRANSPORT_BEARER
+}
+
+// Respond sets the auth cookies when enabled and returns the tokens to put in
+// the response body, stripped of the secrets in cookie-only mode. Responses
+// that only carry an MFA challenge are returned unchanged, and those without
+// a refresh token only replace the access token cookie.
+func (c *AuthCookies) Respond(ctx *gin.Context, tokens authDto.TokenResponse) (authDto.TokenResponse, error) {
+	if !c.Enabled() || tokens.AccessToken == "" {
+		return tokens, nil
+	}
+
+	maxAge := int(time.Until(tokens.RefreshTokenExpiresAt).Seconds())
+	c.setCookie(ctx, constants.ACCESS_TOKEN_COOKIE, tokens.AccessToken, "/", maxAge, true)
+	if tokens.RefreshToken != "" {
+		c.setCookie(ctx, constants.REFRESH_TOKEN_COOKIE, tokens.RefreshToken, refreshTokenCookiePath, maxAge, true)
+		if _, err := c.issueCSRFToken(ctx, maxAge); err != nil {
+			return authDto.TokenResponse{}, err
+		}
+	}
+
+	if c.transport == constants.TOKEN_TRANSPORT_COOKIE {
+		tokens.AccessToken = ""
+		tokens.RefreshToken = ""
+	}
+
+	return tokens, nil
+}
+
+// Clear removes the auth and CSRF cookies.
+func (c *AuthCookies) Clear(ctx *gin.Context) {
+	if !c.Enabled() {
+		return
+	}
+
+	c.setCookie(ctx, constants.ACCESS_TOKEN_COOKIE, "", "/", -1, true)
+	c.setCookie(ctx, constants.REFRESH_TOKEN_COOKIE, "", refreshTokenCookiePath, -1, true)
+	c.setCookie(ctx, constants.CSRF_TOKEN_COOKIE, "", "/", -1, false)
+}
+
+// SetOAuthState stores the state of an OAuth flow in the browser that starts
+// it. It is set whatever the token transport, and always SameSite=Lax so it
+// comes back with the provider's redirect to the callback.
+func (c *AuthCookies) SetOAuthState(ctx *gin.Context, state string, expiresAt time.Time) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     constants.OAUTH_STATE_COOKIE,
+		Value:    state,
+		Path:     oauthCookiePath,
+		Domain:   c.domain,
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+		Secure:   c.secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// TakeOAuthState returns the state cookie set by SetOAuthState and removes it,
+// since a state is only valid for one callback.
+func (c *AuthCookies) TakeOAuthState(ctx *gin.Context) string {
+	state, err := ctx.Cookie(constants.OAUTH_STATE_COOKIE)
+	if err != nil {
+		return ""
+	}
+
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     constants.OAUTH_STATE_COOKIE,
+		Path:     oauthCookiePath,
+		Domain:   c.domain,
+		MaxAge:   -1,
+		Secure:   c.secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return state
+}
+
+// RefreshToken returns the refresh token cookie sent with the request, if
+// any. Like cookie authenticated requests, it must pass the CSRF check.
+func (c *AuthCookies) RefreshToken(ctx *gin.Context) (string, bool, error) {
+	if !c.Enabled() {
+		return "", false, nil
+	}
+
+	token, err := ctx.Cookie(constants.REFRESH_TOKEN_COOKIE)
+	if err != nil || token == "" {
+		return "", false, nil
+	}
+
+	if !validCSRF(ctx) {
+		return "", false, authDto.ErrCSRFTokenInvalid
+	}
+
+	return token, true, nil
+}
+
+// CSRFToken returns the current CSRF token, issuing one if the request has
+// none, so a browser client can bootstrap before its first unsafe request.
+func (c *AuthCookies) CSRFToken(ctx *gin.Context) (string, error) {
+	if token, err := ctx.Cookie(constants.CSRF_TOKEN_COOKIE); err == nil && token != "" {
+		return token, nil
+	}
+
+	return c.issueCSRFToken(ctx, 0)
+}
+
+// issueCSRFToken sets a CSRF cookie readable by JavaScript, which the client
+// echoes in the X-CSRF-Token header (double-submit cookie).
+func (c *AuthCookies) issueCSRFToken(ctx *gin.Context, maxAge int) (string, error) {
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	c.setCookie(ctx, constants.CSRF_TOKEN_COOKIE, token, "/", maxAge, false)
+	return token, nil
+}
+
+func (c *AuthCookies) setCookie(ctx *gin.Context, name string, value string, path string, maxAge int, httpOnly bool) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.domain,
+		MaxAge:   maxAge,
+		Secure:   c.secure,
+		HttpOnly: httpOnly,
+		SameSite: c.sameSite,
+	})
+}
+
+// validCSRF checks the double-submit token of unsafe requests. Safe methods
+// always pass.
+func validCSRF(ctx *gin.Context) bool {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	cookie, err := ctx.Cookie(constants.CSRF_TOKEN_COOKIE)
+	header := ctx.GetHeader(constants.CSRF_TOKEN_HEADER)
+	if err != nil || cookie == "" || header == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
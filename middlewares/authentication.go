@@ -1,54 +1,86 @@
 package middlewares
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
-func Authenticate(jwtService service.JWTService) gin.HandlerFunc {
+// Authenticate accepts either a JWT access token or a personal access token,
+// sent as "Authorization: Bearer pat_..." or in the X-API-Key header. Without
+// either, the access token cookie set in cookie mode is used, in which case
+// unsafe requests must also pass the CSRF check.
+func Authenticate(authenticator service.Authenticator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
+		apiKey := ctx.GetHeader("X-API-Key")
 
-		if authHeader == "" {
+		if authHeader == "" && apiKey == "" {
+			if cookie, err := ctx.Cookie(constants.ACCESS_TOKEN_COOKIE); err == nil && cookie != "" {
+				if !validCSRF(ctx) {
+					response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DENIED_ACCESS, authDto.ErrCSRFTokenInvalid.Error(), nil)
+					ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+					return
+				}
+				authHeader = "Bearer " + cookie
+			}
+		}
+
+		if authHeader == "" && apiKey == "" {
 			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_NOT_FOUND, nil)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
 
-		if !strings.Contains(authHeader, "Bearer ") {
+		if apiKey == "" && !strings.Contains(authHeader, "Bearer ") {
 			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_NOT_VALID, nil)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
 
 		authHeader = strings.Replace(authHeader, "Bearer ", "", -1)
-		token, err := jwtService.ValidateToken(authHeader)
-		if err != nil {
-			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_NOT_VALID, nil)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
-			return
+		if apiKey == "" && strings.HasPrefix(authHeader, authDto.PERSONAL_ACCESS_TOKEN_PREFIX) {
+			apiKey = authHeader
+		}
+
+		var principal authDto.Principal
+		var err error
+		if apiKey != "" {
+			principal, err = authenticator.AuthenticateAPIKey(ctx.Request.Context(), apiKey, ctx.ClientIP())
+		} else {
+			principal, err = authenticator.AuthenticateAccessToken(ctx.Request.Context(), authHeader)
 		}
 
-		if !token.Valid {
-			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_DENIED_ACCESS, nil)
+		if errors.Is(err, authDto.ErrTokenRevoked) || errors.Is(err, authDto.ErrAPIKeyExpired) {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
-
-		userId, err := jwtService.GetUserIDByToken(authHeader)
+		if errors.Is(err, authDto.ErrAccountDisabled) {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DENIED_ACCESS, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
 		if err != nil {
-			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.MESSAGE_FAILED_TOKEN_NOT_VALID, nil)
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
 
-		ctx.Set("token", authHeader)
-		ctx.Set("user_id", userId)
+		if apiKey == "" {
+			ctx.Set("token", authHeader)
+		}
+		ctx.Set("user_id", principal.UserID)
+		ctx.Set("role", principal.Role)
+		ctx.Set("session_id", principal.SessionID)
+		ctx.Set(principalKey, principal)
 		ctx.Next()
 	}
 }
@@ -0,0 +1,133 @@
+package middlewares
+
+import (
+	"net/http"
+
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "principal"
+
+// GetPrincipal returns the caller stored in the context by Authenticate.
+func GetPrincipal(ctx *gin.Context) (authDto.Principal, bool) {
+	value, exists := ctx.Get(principalKey)
+	if !exists {
+		return authDto.Principal{}, false
+	}
+
+	principal, ok := value.(authDto.Principal)
+	return principal, ok
+}
+
+// RequireRole only lets the request through when the caller has one of the given roles.
+// It must be registered after Authenticate.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, authDto.ErrPrincipalNotFound.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		if !principal.HasRole(roles...) {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DENIED_ACCESS, authDto.ErrInsufficientRole.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequirePermission only lets the request through when the caller holds every given permission.
+// It must be registered after Authenticate.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, authDto.ErrPrincipalNotFound.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		for _, permission := range permissions {
+			if !principal.HasPermission(permission) {
+				response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DENIED_ACCESS, authDto.ErrInsufficientPermission.Error(), nil)
+				ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+				return
+			}
+		}
+
+		ctx.Next()
+	}
+}
+
+// RequireSelfOrPermission lets the request through when the path parameter
+// names the caller, or when the caller holds the given permission.
+// It must be registered after Authenticate.
+func RequireSelfOrPermission(param string, permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, authDto.ErrPrincipalNotFound.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		if ctx.Param(param) != principal.UserID && !principal.HasPermission(permission) {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DENIED_ACCESS, authDto.ErrInsufficientPermission.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// DenyAPIKey rejects callers authenticated with a personal access token, for
+// account actions that need an interactive login such as managing API keys.
+// It must be registered after Authenticate.
+func DenyAPIKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, authDto.ErrPrincipalNotFound.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		if principal.APIKeyID != "" {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DENIED_ACCESS, authDto.ErrAPIKeyNotAllowed.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// DenyImpersonation rejects callers using an impersonation token, for actions
+// only the account owner may take such as changing their password.
+// It must be registered after Authenticate.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, authDto.ErrPrincipalNotFound.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		if principal.ImpersonatorID != "" {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DENIED_ACCESS, authDto.ErrImpersonationForbidden.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -2,16 +2,34 @@ package middlewares
 
 import (
 	"net/http"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// CORSMiddleware allows any origin by default. Browsers refuse credentialed
+// requests to a wildcard origin, so clients on another origin that use cookie
+// authentication must be listed in CORS_ALLOWED_ORIGINS (comma separated).
 func CORSMiddleware() gin.HandlerFunc {
+	var allowedOrigins []string
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			allowedOrigins = append(allowedOrigins, origin)
+		}
+	}
+
 	return func(c *gin.Context) {
 
-		c.Header("Access-Control-Allow-Origin", "*")
+		if len(allowedOrigins) == 0 {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else if origin := c.GetHeader("Origin"); slices.Contains(allowedOrigins, origin) {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Vary", "Origin")
+		}
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-API-Key, Authorization, accept, origin, Cache-Control, X-Requested-With")
 		c.Header("Access-Control-Allow-Methods", "POST, HEAD, PATCH, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == http.MethodOptions {
@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"net/http"
+
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	orgDto "github.com/Caknoooo/go-gin-clean-starter/modules/organization/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/tenant"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequireOrganization only lets the request through when the caller's session
+// has an active organization and, if roles are given, one of those roles in
+// it. The organization is put in the request context for tenant.Scope.
+// Membership comes from the access token, so a removed member keeps access
+// until the token expires. It must be registered after Authenticate.
+func RequireOrganization(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, authDto.ErrPrincipalNotFound.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		organizationID, err := uuid.Parse(principal.OrganizationID)
+		if err != nil {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DENIED_ACCESS, tenant.ErrNoOrganization.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		if len(roles) > 0 && !principal.HasOrganizationRole(roles...) {
+			response := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DENIED_ACCESS, orgDto.ErrOrganizationRoleDenied.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		ctx.Set("organization_id", principal.OrganizationID)
+		ctx.Request = ctx.Request.WithContext(tenant.WithOrganizationID(ctx.Request.Context(), organizationID))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin/service"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	rbacDto "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/dto"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/query"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/Caknoooo/go-pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+)
+
+type (
+	AdminController interface {
+		GetUsers(ctx *gin.Context)
+		GetDeletedUsers(ctx *gin.Context)
+		GetUser(ctx *gin.Context)
+		CreateUser(ctx *gin.Context)
+		UpdateUser(ctx *gin.Context)
+		VerifyUser(ctx *gin.Context)
+		DisableUser(ctx *gin.Context)
+		EnableUser(ctx *gin.Context)
+		DeleteUser(ctx *gin.Context)
+		RestoreUser(ctx *gin.Context)
+		ResetPassword(ctx *gin.Context)
+		RevokeSessions(ctx *gin.Context)
+		ImpersonateUser(ctx *gin.Context)
+	}
+
+	adminController struct {
+		adminService service.AdminService
+		db           *gorm.DB
+	}
+)
+
+func NewAdminController(injector *do.Injector, as service.AdminService) AdminController {
+	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
+	return &adminController{
+		adminService: as,
+		db:           db,
+	}
+}
+
+// errorStatus maps the errors of AdminService to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, userDto.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, userDto.ErrEmailAlreadyExists):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrCannotModifySelf), errors.Is(err, dto.ErrCannotImpersonate),
+		errors.Is(err, rbacDto.ErrGrantDenied):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (c *adminController) GetUsers(ctx *gin.Context) {
+	c.listUsers(ctx, false)
+}
+
+func (c *adminController) GetDeletedUsers(ctx *gin.Context) {
+	c.listUsers(ctx, true)
+}
+
+func (c *adminController) listUsers(ctx *gin.Context, deleted bool) {
+	var filter = &query.UserFilter{}
+	filter.BindPagination(ctx)
+
+	ctx.ShouldBindQuery(filter)
+	filter.Deleted = deleted
+
+	users, total, err := pagination.PaginatedQueryWithIncludable[query.User](c.db, filter)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_USERS, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	paginationResponse := pagination.CalculatePagination(filter.Pagination, total)
+	response := pagination.NewPaginatedResponse(http.StatusOK, dto.MESSAGE_SUCCESS_GET_USERS, users, paginationResponse)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *adminController) GetUser(ctx *gin.Context) {
+	result, err := c.adminService.GetUser(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_USER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_USER, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *adminController) CreateUser(ctx *gin.Context) {
+	var req dto.AdminUserCreateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	result, err := c.adminService.CreateUser(ctx.Request.Context(), principal, req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_USER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_USER, result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *adminController) UpdateUser(ctx *gin.Context) {
+	var req dto.AdminUserUpdateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	result, err := c.adminService.UpdateUser(ctx.Request.Context(), principal, ctx.Param("id"), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_USER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_USER, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *adminController) VerifyUser(ctx *gin.Context) {
+	result, err := c.adminService.VerifyUser(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_VERIFY_USER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_VERIFY_USER, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// DisableUser accepts an empty body when no reason is given.
+func (c *adminController) DisableUser(ctx *gin.Context) {
+	var req dto.AdminDisableUserRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBind(&req); err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	if err := c.adminService.DisableUser(ctx.Request.Context(), principal, ctx.Param("id"), req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DISABLE_USER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DISABLE_USER, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *adminController) EnableUser(ctx *gin.Context) {
+	if err := c.adminService.EnableUser(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_ENABLE_USER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_ENABLE_USER, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *adminController) DeleteUser(ctx *gin.Context) {
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	if err := c.adminService.DeleteUser(ctx.Request.Context(), principal, ctx.Param("id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_USER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_USER, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *adminController) RestoreUser(ctx *gin.Context) {
+	result, err := c.adminService.RestoreUser(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_RESTORE_USER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_RESTORE_USER, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *adminController) ResetPassword(ctx *gin.Context) {
+	var req dto.AdminResetPasswordRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	if err := c.adminService.ResetPassword(ctx.Request.Context(), principal, ctx.Param("id"), req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_RESET_PASSWORD, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_RESET_PASSWORD, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *adminController) RevokeSessions(ctx *gin.Context) {
+	if err := c.adminService.RevokeSessions(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REVOKE_SESSIONS, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REVOKE_SESSIONS, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *adminController) ImpersonateUser(ctx *gin.Context) {
+	var req dto.AdminImpersonateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+	client := authDto.ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+
+	result, err := c.adminService.ImpersonateUser(ctx.Request.Context(), principal, ctx.Param("id"), req, client)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_IMPERSONATE, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_IMPERSONATE, result)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
+
+	MESSAGE_FAILED_GET_USERS       = "failed get users"
+	MESSAGE_FAILED_GET_USER        = "failed get user"
+	MESSAGE_FAILED_CREATE_USER     = "failed create user"
+	MESSAGE_FAILED_UPDATE_USER     = "failed update user"
+	MESSAGE_FAILED_VERIFY_USER     = "failed verify user"
+	MESSAGE_FAILED_DISABLE_USER    = "failed disable user"
+	MESSAGE_FAILED_ENABLE_USER     = "failed enable user"
+	MESSAGE_FAILED_DELETE_USER     = "failed delete user"
+	MESSAGE_FAILED_RESTORE_USER    = "failed restore user"
+	MESSAGE_FAILED_RESET_PASSWORD  = "failed reset user password"
+	MESSAGE_FAILED_REVOKE_SESSIONS = "failed revoke user sessions"
+	MESSAGE_FAILED_IMPERSONATE     = "failed impersonate user"
+
+	MESSAGE_SUCCESS_GET_USERS       = "success get users"
+	MESSAGE_SUCCESS_GET_USER        = "success get user"
+	MESSAGE_SUCCESS_CREATE_USER     = "success create user"
+	MESSAGE_SUCCESS_UPDATE_USER     = "success update user"
+	MESSAGE_SUCCESS_VERIFY_USER     = "success verify user"
+	MESSAGE_SUCCESS_DISABLE_USER    = "success disable user"
+	MESSAGE_SUCCESS_ENABLE_USER     = "success enable user"
+	MESSAGE_SUCCESS_DELETE_USER     = "success delete user"
+	MESSAGE_SUCCESS_RESTORE_USER    = "success restore user"
+	MESSAGE_SUCCESS_RESET_PASSWORD  = "success reset user password"
+	MESSAGE_SUCCESS_REVOKE_SESSIONS = "success revoke user sessions"
+	MESSAGE_SUCCESS_IMPERSONATE     = "success impersonate user"
+)
+
+var (
+	ErrCannotModifySelf  = errors.New("admins cannot disable, delete or demote their own account")
+	ErrCannotImpersonate = errors.New("admins and disabled accounts cannot be impersonated")
+)
+
+type (
+	AdminUserCreateRequest struct {
+		Name       string `json:"name" binding:"required,min=2,max=100"`
+		Email      string `json:"email" binding:"required,email"`
+		TelpNumber string `json:"telp_number" binding:"omitempty,min=8,max=20"`
+		Password   string `json:"password" binding:"required"`
+		Role       string `json:"role" binding:"omitempty,max=50"`
+		IsVerified bool   `json:"is_verified"`
+	}
+
+	AdminUserUpdateRequest struct {
+		Name       string `json:"name" binding:"omitempty,min=2,max=100"`
+		Email      string `json:"email" binding:"omitempty,email"`
+		TelpNumber string `json:"telp_number" binding:"omitempty,min=8,max=20"`
+		Role       string `json:"role" binding:"omitempty,max=50"`
+	}
+
+	// AdminResetPasswordRequest sets the given password, or emails the user a
+	// password reset token when it is left empty.
+	AdminResetPasswordRequest struct {
+		Password string `json:"password"`
+	}
+
+	// AdminImpersonateRequest records why support needs to act as the user.
+	AdminImpersonateRequest struct {
+		Reason string `json:"reason" binding:"required,max=255"`
+	}
+
+	// AdminDisableUserRequest optionally records why the account is disabled.
+	AdminDisableUserRequest struct {
+		Reason string `json:"reason" binding:"max=255"`
+	}
+
+	AdminImpersonationResponse struct {
+		AccessToken string            `json:"access_token"`
+		ExpiresAt   time.Time         `json:"expires_at"`
+		User        AdminUserResponse `json:"user"`
+	}
+
+	AdminUserResponse struct {
+		ID             string     `json:"id"`
+		Name           string     `json:"name"`
+		Email          string     `json:"email"`
+		PendingEmail   string     `json:"pending_email,omitempty"`
+		TelpNumber     string     `json:"telp_number"`
+		Role           string     `json:"role"`
+		ImageUrl       string     `json:"image_url"`
+		IsVerified     bool       `json:"is_verified"`
+		IsActive       bool       `json:"is_active"`
+		DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+		DisabledReason string     `json:"disabled_reason,omitempty"`
+		CreatedAt      time.Time  `json:"created_at"`
+		UpdatedAt      time.Time  `json:"updated_at"`
+	}
+)
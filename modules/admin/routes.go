@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+)
+
+func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
+	adminController := do.MustInvoke[controller.AdminController](injector)
+	authenticator := do.MustInvokeNamed[service.Authenticator](injector, constants.Authenticator)
+
+	read := middlewares.RequirePermission(constants.PERMISSION_USER_READ)
+	write := middlewares.RequirePermission(constants.PERMISSION_USER_WRITE)
+
+	userRoutes := server.Group("/api/admin/users", middlewares.Authenticate(authenticator))
+	{
+		userRoutes.GET("", read, adminController.GetUsers)
+		userRoutes.POST("", write, adminController.CreateUser)
+		userRoutes.GET("/deleted", read, adminController.GetDeletedUsers)
+		userRoutes.GET("/:id", read, adminController.GetUser)
+		userRoutes.PATCH("/:id", write, adminController.UpdateUser)
+		userRoutes.DELETE("/:id", write, adminController.DeleteUser)
+		userRoutes.POST("/:id/restore", write, adminController.RestoreUser)
+		userRoutes.POST("/:id/verify", write, adminController.VerifyUser)
+		userRoutes.POST("/:id/disable", write, adminController.DisableUser)
+		userRoutes.POST("/:id/enable", write, adminController.EnableUser)
+		userRoutes.POST("/:id/reset-password", write, adminController.ResetPassword)
+		userRoutes.DELETE("/:id/sessions", write, adminController.RevokeSessions)
+		userRoutes.POST("/:id/impersonate", write, middlewares.DenyAPIKey(), adminController.ImpersonateUser)
+	}
+}
@@ -0,0 +1,523 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin/dto"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	rbacDto "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/dto"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdminService manages any user account on behalf of an administrator.
+// actor is the admin making the request; they cannot lock themselves out.
+// Roles can only be assigned, and accounts only be changed, by an actor
+// holding all of their permissions.
+type AdminService interface {
+	CreateUser(ctx context.Context, actor authDto.Principal, req dto.AdminUserCreateRequest) (dto.AdminUserResponse, error)
+	GetUser(ctx context.Context, userId string) (dto.AdminUserResponse, error)
+	UpdateUser(ctx context.Context, actor authDto.Principal, userId string, req dto.AdminUserUpdateRequest) (dto.AdminUserResponse, error)
+	VerifyUser(ctx context.Context, userId string) (dto.AdminUserResponse, error)
+	DisableUser(ctx context.Context, actor authDto.Principal, userId string, req dto.AdminDisableUserRequest) error
+	EnableUser(ctx context.Context, userId string) error
+	DeleteUser(ctx context.Context, actor authDto.Principal, userId string) error
+	RestoreUser(ctx context.Context, userId string) (dto.AdminUserResponse, error)
+	ResetPassword(ctx context.Context, actor authDto.Principal, userId string, req dto.AdminResetPasswordRequest) error
+	RevokeSessions(ctx context.Context, userId string) error
+	ImpersonateUser(
+		ctx context.Context,
+		actor authDto.Principal,
+		userId string,
+		req dto.AdminImpersonateRequest,
+		client authDto.ClientInfo,
+	) (dto.AdminImpersonationResponse, error)
+}
+
+const defaultImpersonationTTL = time.Minute * 10
+
+type adminService struct {
+	userRepository          repository.UserRepository
+	securityEventRepository authRepo.SecurityEventRepository
+	passwordPolicyService   authService.PasswordPolicyService
+	oneTimeTokenService     authService.OneTimeTokenService
+	sessionService          authService.SessionService
+	accountStatusService    authService.AccountStatusService
+	jwtService              authService.JWTService
+	rbacService             rbacService.RBACService
+	impersonationTTL        time.Duration
+	db                      *gorm.DB
+}
+
+// NewAdminService reads the lifetime of impersonation tokens from
+// IMPERSONATION_TOKEN_TTL. It never exceeds that of a normal access token.
+func NewAdminService(
+	userRepo repository.UserRepository,
+	securityEventRepo authRepo.SecurityEventRepository,
+	passwordPolicyService authService.PasswordPolicyService,
+	oneTimeTokenService authService.OneTimeTokenService,
+	sessionService authService.SessionService,
+	accountStatusService authService.AccountStatusService,
+	jwtService authService.JWTService,
+	rbacService rbacService.RBACService,
+	db *gorm.DB,
+) AdminService {
+	impersonationTTL := helpers.GetDurationEnv("IMPERSONATION_TOKEN_TTL", defaultImpersonationTTL)
+
+	return &adminService{
+		userRepository:          userRepo,
+		securityEventRepository: securityEventRepo,
+		passwordPolicyService:   passwordPolicyService,
+		oneTimeTokenService:     oneTimeTokenService,
+		sessionService:          sessionService,
+		accountStatusService:    accountStatusService,
+		jwtService:              jwtService,
+		rbacService:             rbacService,
+		impersonationTTL:        impersonationTTL,
+		db:                      db,
+	}
+}
+
+func toAdminUserResponse(user entities.User) dto.AdminUserResponse {
+	response := dto.AdminUserResponse{
+		ID:         user.ID.String(),
+		Name:       user.Name,
+		Email:      user.Email,
+		TelpNumber: user.TelpNumber,
+		Role:       user.Role,
+		ImageUrl:   user.ImageUrl,
+		IsVerified: user.IsVerified,
+		IsActive:   user.IsActive,
+		DisabledAt: user.DisabledAt,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+	}
+	if user.PendingEmail != nil {
+		response.PendingEmail = *user.PendingEmail
+	}
+	if user.DisabledReason != nil {
+		response.DisabledReason = *user.DisabledReason
+	}
+
+	return response
+}
+
+// getUser looks a user up by id, treating malformed ids as unknown users.
+func (s *adminService) getUser(ctx context.Context, tx *gorm.DB, userId string) (entities.User, error) {
+	if _, err := uuid.Parse(userId); err != nil {
+		return entities.User{}, userDto.ErrUserNotFound
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, tx, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.User{}, userDto.ErrUserNotFound
+	}
+
+	return user, err
+}
+
+func (s *adminService) CreateUser(
+	ctx context.Context,
+	actor authDto.Principal,
+	req dto.AdminUserCreateRequest,
+) (dto.AdminUserResponse, error) {
+	var createdUser entities.User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if req.Role == "" {
+			req.Role = constants.ENUM_ROLE_USER
+		}
+		if err := s.rbacService.CheckGrant(ctx, tx, actor, req.Role); err != nil {
+			return err
+		}
+
+		_, isExist, err := s.userRepository.CheckEmail(ctx, tx, req.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if isExist {
+			return userDto.ErrEmailAlreadyExists
+		}
+
+		if err := s.passwordPolicyService.Validate(ctx, tx, req.Password, entities.User{Name: req.Name, Email: req.Email}); err != nil {
+			return err
+		}
+
+		hashedPassword, err := helpers.HashPassword(req.Password)
+		if err != nil {
+			return err
+		}
+
+		user := entities.User{
+			ID:         uuid.New(),
+			Name:       req.Name,
+			Email:      req.Email,
+			TelpNumber: req.TelpNumber,
+			Password:   hashedPassword,
+			Role:       req.Role,
+			IsVerified: req.IsVerified,
+			IsActive:   true,
+		}
+
+		createdUser, err = s.userRepository.Register(ctx, tx, user)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return userDto.ErrEmailAlreadyExists
+		}
+		if err != nil {
+			return err
+		}
+
+		return s.passwordPolicyService.Remember(ctx, tx, createdUser.ID, createdUser.Password)
+	})
+	if err != nil {
+		return dto.AdminUserResponse{}, err
+	}
+
+	return toAdminUserResponse(createdUser), nil
+}
+
+func (s *adminService) GetUser(ctx context.Context, userId string) (dto.AdminUserResponse, error) {
+	user, err := s.getUser(ctx, s.db, userId)
+	if err != nil {
+		return dto.AdminUserResponse{}, err
+	}
+
+	return toAdminUserResponse(user), nil
+}
+
+// UpdateUser edits the profile and role. An email set by an admin is applied
+// directly; a role change signs the user out so new tokens carry the new role.
+func (s *adminService) UpdateUser(
+	ctx context.Context,
+	actor authDto.Principal,
+	userId string,
+	req dto.AdminUserUpdateRequest,
+) (dto.AdminUserResponse, error) {
+	var updatedUser entities.User
+	var roleChanged bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := s.getUser(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.rbacService.CheckUserGrant(ctx, tx, actor, user); err != nil {
+			return err
+		}
+
+		if req.Role != "" && req.Role != user.Role {
+			if actor.UserID == userId {
+				return dto.ErrCannotModifySelf
+			}
+			if err := s.rbacService.CheckGrant(ctx, tx, actor, req.Role); err != nil {
+				return err
+			}
+			user.Role = req.Role
+			roleChanged = true
+		}
+
+		if req.Name != "" {
+			user.Name = req.Name
+		}
+		if req.TelpNumber != "" {
+			user.TelpNumber = req.TelpNumber
+		}
+
+		if req.Email != "" && req.Email != user.Email {
+			_, isExist, err := s.userRepository.CheckEmail(ctx, tx, req.Email)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if isExist {
+				return userDto.ErrEmailAlreadyExists
+			}
+
+			if user.PendingEmail != nil {
+				if err := s.userRepository.ClearPendingEmail(ctx, tx, userId); err != nil {
+					return err
+				}
+				if err := s.oneTimeTokenService.Invalidate(ctx, tx, userId, constants.TOKEN_PURPOSE_EMAIL_CHANGE); err != nil {
+					return err
+				}
+				user.PendingEmail = nil
+			}
+			user.Email = req.Email
+		}
+
+		updatedUser, err = s.userRepository.Update(ctx, tx, user)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return userDto.ErrEmailAlreadyExists
+		}
+		return err
+	})
+	if err != nil {
+		return dto.AdminUserResponse{}, err
+	}
+
+	if roleChanged {
+		if err := s.sessionService.RevokeAllSessions(ctx, userId); err != nil {
+			return dto.AdminUserResponse{}, err
+		}
+	}
+
+	return toAdminUserResponse(updatedUser), nil
+}
+
+func (s *adminService) VerifyUser(ctx context.Context, userId string) (dto.AdminUserResponse, error) {
+	var updatedUser entities.User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := s.getUser(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+
+		user.IsVerified = true
+		if updatedUser, err = s.userRepository.Update(ctx, tx, user); err != nil {
+			return err
+		}
+
+		return s.oneTimeTokenService.Invalidate(ctx, tx, userId, constants.TOKEN_PURPOSE_EMAIL_VERIFICATION)
+	})
+	if err != nil {
+		return dto.AdminUserResponse{}, err
+	}
+
+	return toAdminUserResponse(updatedUser), nil
+}
+
+// DisableUser blocks the account from logging in and ends its sessions. Its
+// refresh tokens are deleted and its access tokens revoked right away.
+func (s *adminService) DisableUser(ctx context.Context, actor authDto.Principal, userId string, req dto.AdminDisableUserRequest) error {
+	if actor.UserID == userId {
+		return dto.ErrCannotModifySelf
+	}
+
+	user, err := s.getUser(ctx, s.db, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.rbacService.CheckUserGrant(ctx, s.db, actor, user); err != nil {
+		return err
+	}
+
+	if err := s.userRepository.Disable(ctx, s.db, userId, req.Reason, time.Now()); err != nil {
+		return err
+	}
+	s.accountStatusService.Invalidate(userId)
+
+	return s.sessionService.RevokeAllSessions(ctx, userId)
+}
+
+func (s *adminService) EnableUser(ctx context.Context, userId string) error {
+	if _, err := s.getUser(ctx, s.db, userId); err != nil {
+		return err
+	}
+
+	if err := s.userRepository.Enable(ctx, s.db, userId); err != nil {
+		return err
+	}
+	s.accountStatusService.Invalidate(userId)
+
+	return nil
+}
+
+func (s *adminService) DeleteUser(ctx context.Context, actor authDto.Principal, userId string) error {
+	if actor.UserID == userId {
+		return dto.ErrCannotModifySelf
+	}
+
+	user, err := s.getUser(ctx, s.db, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.rbacService.CheckUserGrant(ctx, s.db, actor, user); err != nil {
+		return err
+	}
+
+	if err := s.userRepository.Delete(ctx, s.db, userId); err != nil {
+		return err
+	}
+
+	return s.sessionService.RevokeAllSessions(ctx, userId)
+}
+
+// RestoreUser brings back a soft-deleted user. Sessions revoked by the delete
+// stay revoked, so the user has to sign in again.
+func (s *adminService) RestoreUser(ctx context.Context, userId string) (dto.AdminUserResponse, error) {
+	if _, err := uuid.Parse(userId); err != nil {
+		return dto.AdminUserResponse{}, userDto.ErrUserNotFound
+	}
+
+	restored, err := s.userRepository.Restore(ctx, s.db, userId)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return dto.AdminUserResponse{}, userDto.ErrEmailAlreadyExists
+	}
+	if err != nil {
+		return dto.AdminUserResponse{}, err
+	}
+	if !restored {
+		return dto.AdminUserResponse{}, userDto.ErrUserNotFound
+	}
+
+	return s.GetUser(ctx, userId)
+}
+
+// ResetPassword either sets the password given by the admin or emails the
+// user a reset token. Either way the user is signed out everywhere.
+func (s *adminService) ResetPassword(ctx context.Context, actor authDto.Principal, userId string, req dto.AdminResetPasswordRequest) error {
+	var resetToken string
+	var user entities.User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = s.getUser(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.rbacService.CheckUserGrant(ctx, tx, actor, user); err != nil {
+			return err
+		}
+
+		if req.Password == "" {
+			resetToken, err = s.oneTimeTokenService.Issue(ctx, tx, user.ID, constants.TOKEN_PURPOSE_PASSWORD_RESET)
+			return err
+		}
+
+		if err := s.passwordPolicyService.Validate(ctx, tx, req.Password, user); err != nil {
+			return err
+		}
+
+		hashedPassword, err := helpers.HashPassword(req.Password)
+		if err != nil {
+			return err
+		}
+
+		user.Password = hashedPassword
+		if _, err := s.userRepository.Update(ctx, tx, user); err != nil {
+			return err
+		}
+
+		if err := s.passwordPolicyService.Remember(ctx, tx, user.ID, hashedPassword); err != nil {
+			return err
+		}
+
+		// A reset or magic link already sent would still sign in with or
+		// replace the password the administrator just set.
+		return s.oneTimeTokenService.Invalidate(ctx, tx, userId, constants.TOKEN_PURPOSE_PASSWORD_RESET, constants.TOKEN_PURPOSE_MAGIC_LINK)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessionService.RevokeAllSessions(ctx, userId); err != nil {
+		return err
+	}
+
+	if resetToken == "" {
+		return nil
+	}
+
+	subject := "Password Reset"
+	body := "An administrator reset your password. Choose a new one using this token: " + resetToken
+
+	return utils.SendMail(user.Email, subject, body)
+}
+
+func (s *adminService) RevokeSessions(ctx context.Context, userId string) error {
+	if _, err := s.getUser(ctx, s.db, userId); err != nil {
+		return err
+	}
+
+	return s.sessionService.RevokeAllSessions(ctx, userId)
+}
+
+// ImpersonateUser issues a short-lived access token for the user that names
+// the admin in its act claim. Only users whose permissions the admin all holds
+// can be impersonated, so impersonation never widens what the admin can do.
+// The token has no session, so it cannot be refreshed, and an audit event
+// records who acted as whom.
+func (s *adminService) ImpersonateUser(
+	ctx context.Context,
+	actor authDto.Principal,
+	userId string,
+	req dto.AdminImpersonateRequest,
+	client authDto.ClientInfo,
+) (dto.AdminImpersonationResponse, error) {
+	actorUUID, err := uuid.Parse(actor.UserID)
+	if err != nil {
+		return dto.AdminImpersonationResponse{}, authDto.ErrPrincipalNotFound
+	}
+
+	user, err := s.getUser(ctx, s.db, userId)
+	if err != nil {
+		return dto.AdminImpersonationResponse{}, err
+	}
+
+	if actor.UserID == userId || !user.IsActive {
+		return dto.AdminImpersonationResponse{}, dto.ErrCannotImpersonate
+	}
+
+	err = s.rbacService.CheckUserGrant(ctx, s.db, actor, user)
+	if errors.Is(err, rbacDto.ErrGrantDenied) {
+		return dto.AdminImpersonationResponse{}, dto.ErrCannotImpersonate
+	}
+	if err != nil {
+		return dto.AdminImpersonationResponse{}, err
+	}
+
+	permissions, err := s.rbacService.GetUserPermissions(ctx, s.db, user)
+	if err != nil {
+		return dto.AdminImpersonationResponse{}, err
+	}
+
+	accessToken := s.jwtService.GenerateAccessToken(authService.AccessTokenSubject{
+		UserID:      user.ID.String(),
+		Role:        user.Role,
+		Permissions: permissions,
+		ActorID:     actor.UserID,
+		ExpiresIn:   s.impersonationTTL,
+	})
+
+	claims, err := s.jwtService.GetClaimsByToken(accessToken)
+	if err != nil {
+		return dto.AdminImpersonationResponse{}, err
+	}
+
+	event := entities.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    &user.ID,
+		ActorID:   &actorUUID,
+		Type:      constants.SECURITY_EVENT_IMPERSONATION_STARTED,
+		IPAddress: client.IPAddress,
+		UserAgent: truncate(client.UserAgent, 255),
+		Details:   req.Reason,
+	}
+	if _, err := s.securityEventRepository.Create(ctx, s.db, event); err != nil {
+		return dto.AdminImpersonationResponse{}, err
+	}
+
+	return dto.AdminImpersonationResponse{
+		AccessToken: accessToken,
+		ExpiresAt:   claims.ExpiresAt.Time,
+		User:        toAdminUserResponse(user),
+	}, nil
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	return value[:length]
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin/service"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// supportRole is a custom role that was only granted read access to users.
+const supportRole = "support"
+
+// stubAuthenticator treats every bearer token as the name of a role.
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) AuthenticateAccessToken(_ context.Context, token string) (authDto.Principal, error) {
+	principal := authDto.NewPrincipal("actor-id", token)
+	if token == supportRole {
+		principal.Permissions = []string{constants.PERMISSION_USER_READ}
+	}
+	return principal, nil
+}
+
+func (stubAuthenticator) AuthenticateAPIKey(context.Context, string, string) (authDto.Principal, error) {
+	return authDto.Principal{}, authDto.ErrAPIKeyInvalid
+}
+
+type stubAdminService struct {
+	service.AdminService
+	deletedBy     string
+	disableReason string
+}
+
+func (s *stubAdminService) GetUser(_ context.Context, userId string) (dto.AdminUserResponse, error) {
+	if userId != "known" {
+		return dto.AdminUserResponse{}, userDto.ErrUserNotFound
+	}
+	return dto.AdminUserResponse{ID: userId}, nil
+}
+
+func (s *stubAdminService) DeleteUser(_ context.Context, actor authDto.Principal, userId string) error {
+	if actor.UserID == userId {
+		return dto.ErrCannotModifySelf
+	}
+	s.deletedBy = actor.UserID
+	return nil
+}
+
+func (s *stubAdminService) DisableUser(_ context.Context, _ authDto.Principal, _ string, req dto.AdminDisableUserRequest) error {
+	s.disableReason = req.Reason
+	return nil
+}
+
+func (s *stubAdminService) RestoreUser(_ context.Context, userId string) (dto.AdminUserResponse, error) {
+	switch userId {
+	case "deleted":
+		return dto.AdminUserResponse{ID: userId}, nil
+	case "taken":
+		return dto.AdminUserResponse{}, userDto.ErrEmailAlreadyExists
+	default:
+		return dto.AdminUserResponse{}, userDto.ErrUserNotFound
+	}
+}
+
+func newAdminRouter(adminService service.AdminService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	injector := do.New()
+	do.ProvideNamedValue[*gorm.DB](injector, constants.DB, nil)
+	do.ProvideNamedValue[authService.Authenticator](injector, constants.Authenticator, stubAuthenticator{})
+	do.Provide(injector, func(i *do.Injector) (controller.AdminController, error) {
+		return controller.NewAdminController(i, adminService), nil
+	})
+
+	router := gin.New()
+	admin.RegisterRoutes(router, injector)
+	return router
+}
+
+func request(router *gin.Engine, method string, path string, role string) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+role)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestAdminRoutes_RejectNonAdmins(t *testing.T) {
+	router := newAdminRouter(&stubAdminService{})
+
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodGet, "/api/admin/users/known", constants.ENUM_ROLE_USER))
+}
+
+func TestAdminRoutes_AllowCustomRolesByPermission(t *testing.T) {
+	router := newAdminRouter(&stubAdminService{})
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/api/admin/users/known", supportRole))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodDelete, "/api/admin/users/someone", supportRole))
+}
+
+func TestAdminRoutes_GetUser(t *testing.T) {
+	router := newAdminRouter(&stubAdminService{})
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/api/admin/users/known", constants.ENUM_ROLE_ADMIN))
+	assert.Equal(t, http.StatusNotFound, request(router, http.MethodGet, "/api/admin/users/unknown", constants.ENUM_ROLE_ADMIN))
+}
+
+func TestAdminRoutes_DeleteUsesCaller(t *testing.T) {
+	adminService := &stubAdminService{}
+	router := newAdminRouter(adminService)
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodDelete, "/api/admin/users/someone", constants.ENUM_ROLE_ADMIN))
+	assert.Equal(t, "actor-id", adminService.deletedBy)
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodDelete, "/api/admin/users/actor-id", constants.ENUM_ROLE_ADMIN))
+}
+
+func TestAdminRoutes_RestoreUser(t *testing.T) {
+	router := newAdminRouter(&stubAdminService{})
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodPost, "/api/admin/users/deleted/restore", constants.ENUM_ROLE_ADMIN))
+	assert.Equal(t, http.StatusConflict, request(router, http.MethodPost, "/api/admin/users/taken/restore", constants.ENUM_ROLE_ADMIN))
+	assert.Equal(t, http.StatusNotFound, request(router, http.MethodPost, "/api/admin/users/live/restore", constants.ENUM_ROLE_ADMIN))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodPost, "/api/admin/users/deleted/restore", constants.ENUM_ROLE_USER))
+}
+
+func TestAdminRoutes_DisableUser(t *testing.T) {
+	adminService := &stubAdminService{}
+	router := newAdminRouter(adminService)
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodPost, "/api/admin/users/someone/disable", constants.ENUM_ROLE_ADMIN))
+	assert.Empty(t, adminService.disableReason)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users/someone/disable", strings.NewReader(`{"reason":"chargeback fraud"}`))
+	req.Header.Set("Authorization", "Bearer "+constants.ENUM_ROLE_ADMIN)
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "chargeback fraud", adminService.disableReason)
+}
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/admin/service"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	rbacDto "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/dto"
+	rbacRepo "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/repository"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	userRepo "github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// roleManagerRole is a custom role that may edit roles but not users.
+const roleManagerRole = "role-manager"
+
+// stubRoleRepository only stores the custom role, so other users get the
+// permissions their built-in role is seeded with.
+type stubRoleRepository struct {
+	rbacRepo.RoleRepository
+}
+
+func (stubRoleRepository) GetUserPermissionNames(_ context.Context, _ *gorm.DB, _ string, primaryRole string) ([]string, error) {
+	if primaryRole == roleManagerRole {
+		return []string{constants.PERMISSION_ROLE_READ, constants.PERMISSION_ROLE_WRITE}, nil
+	}
+	return nil, nil
+}
+
+func (stubRoleRepository) FindByName(context.Context, *gorm.DB, string) (entities.Role, error) {
+	return entities.Role{}, gorm.ErrRecordNotFound
+}
+
+type stubUserRepository struct {
+	userRepo.UserRepository
+	users map[string]entities.User
+}
+
+func (r *stubUserRepository) GetUserById(_ context.Context, _ *gorm.DB, userId string) (entities.User, error) {
+	user, ok := r.users[userId]
+	if !ok {
+		return entities.User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (r *stubUserRepository) Update(_ context.Context, _ *gorm.DB, user entities.User) (entities.User, error) {
+	r.users[user.ID.String()] = user
+	return user, nil
+}
+
+type allowAllPasswordPolicy struct {
+	authService.PasswordPolicyService
+}
+
+func (allowAllPasswordPolicy) Validate(context.Context, *gorm.DB, string, entities.User) error {
+	return nil
+}
+
+func (allowAllPasswordPolicy) Remember(context.Context, *gorm.DB, uuid.UUID, string) error {
+	return nil
+}
+
+// stubOneTimeTokenService knows outstanding tokens by their purpose.
+type stubOneTimeTokenService struct {
+	authService.OneTimeTokenService
+	outstanding map[string]string
+}
+
+func (s *stubOneTimeTokenService) Invalidate(_ context.Context, _ *gorm.DB, _ string, purposes ...string) error {
+	for token, purpose := range s.outstanding {
+		for _, invalidated := range purposes {
+			if purpose == invalidated {
+				delete(s.outstanding, token)
+			}
+		}
+	}
+	return nil
+}
+
+type stubSessionService struct {
+	authService.SessionService
+	revoked []string
+}
+
+func (s *stubSessionService) RevokeAllSessions(_ context.Context, userId string) error {
+	s.revoked = append(s.revoked, userId)
+	return nil
+}
+
+// newAdminService returns an admin service whose accounts are an admin and
+// users with the given roles.
+func newAdminService(t *testing.T, roles ...string) (service.AdminService, entities.User, []entities.User) {
+	// Transactions need a connection, but the stubs never run a statement.
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+
+	admin := entities.User{ID: uuid.New(), Email: "admin@example.com", Role: constants.ENUM_ROLE_ADMIN, IsActive: true}
+	users := &stubUserRepository{users: map[string]entities.User{admin.ID.String(): admin}}
+
+	var others []entities.User
+	for _, role := range roles {
+		user := entities.User{ID: uuid.New(), Email: role + "@example.com", Role: role, IsActive: true}
+		users.users[user.ID.String()] = user
+		others = append(others, user)
+	}
+
+	rbac := rbacService.NewRBACService(stubRoleRepository{}, nil, users, db)
+
+	return service.NewAdminService(users, nil, nil, nil, nil, nil, nil, rbac, db), admin, others
+}
+
+func TestAdminService_CannotManageMorePrivilegedUser(t *testing.T) {
+	ctx := context.Background()
+	adminService, admin, _ := newAdminService(t)
+
+	actor := authDto.Principal{UserID: uuid.NewString(), Role: supportRole, Permissions: []string{constants.PERMISSION_USER_WRITE}}
+
+	err := adminService.ResetPassword(ctx, actor, admin.ID.String(), dto.AdminResetPasswordRequest{Password: "a new password"})
+	assert.ErrorIs(t, err, rbacDto.ErrGrantDenied)
+
+	_, err = adminService.UpdateUser(ctx, actor, admin.ID.String(), dto.AdminUserUpdateRequest{Email: "attacker@example.com"})
+	assert.ErrorIs(t, err, rbacDto.ErrGrantDenied)
+
+	assert.ErrorIs(t, adminService.DisableUser(ctx, actor, admin.ID.String(), dto.AdminDisableUserRequest{}), rbacDto.ErrGrantDenied)
+	assert.ErrorIs(t, adminService.DeleteUser(ctx, actor, admin.ID.String()), rbacDto.ErrGrantDenied)
+}
+
+func TestAdminService_CannotImpersonateMorePrivilegedUser(t *testing.T) {
+	ctx := context.Background()
+	adminService, admin, others := newAdminService(t, roleManagerRole)
+
+	actor := authDto.Principal{UserID: uuid.NewString(), Role: supportRole, Permissions: []string{constants.PERMISSION_USER_WRITE}}
+
+	for _, target := range []entities.User{admin, others[0]} {
+		_, err := adminService.ImpersonateUser(ctx, actor, target.ID.String(), dto.AdminImpersonateRequest{Reason: "support"}, authDto.ClientInfo{})
+		assert.ErrorIs(t, err, dto.ErrCannotImpersonate, target.Role)
+	}
+}
+
+func TestAdminService_ResetPasswordInvalidatesEmailedLinks(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+
+	admin := entities.User{ID: uuid.New(), Email: "admin@example.com", Role: constants.ENUM_ROLE_ADMIN, IsActive: true}
+	user := entities.User{ID: uuid.New(), Email: "user@example.com", Role: constants.ENUM_ROLE_USER, IsActive: true}
+	users := &stubUserRepository{users: map[string]entities.User{admin.ID.String(): admin, user.ID.String(): user}}
+	oneTimeTokens := &stubOneTimeTokenService{outstanding: map[string]string{
+		"reset-token":        constants.TOKEN_PURPOSE_PASSWORD_RESET,
+		"magic-token":        constants.TOKEN_PURPOSE_MAGIC_LINK,
+		"verification-token": constants.TOKEN_PURPOSE_EMAIL_VERIFICATION,
+	}}
+	sessions := &stubSessionService{}
+	rbac := rbacService.NewRBACService(stubRoleRepository{}, nil, users, db)
+	adminService := service.NewAdminService(users, nil, allowAllPasswordPolicy{}, oneTimeTokens, sessions, nil, nil, rbac, db)
+
+	actor := authDto.NewPrincipal(admin.ID.String(), constants.ENUM_ROLE_ADMIN)
+	err = adminService.ResetPassword(context.Background(), actor, user.ID.String(), dto.AdminResetPasswordRequest{Password: "a brand new password"})
+	require.NoError(t, err)
+
+	assert.NotEqual(t, user.Password, users.users[user.ID.String()].Password)
+	assert.Equal(t, map[string]string{"verification-token": constants.TOKEN_PURPOSE_EMAIL_VERIFICATION}, oneTimeTokens.outstanding)
+	assert.Equal(t, []string{user.ID.String()}, sessions.revoked)
+}
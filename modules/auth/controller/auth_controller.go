@@ -1,11 +1,16 @@
 package controller
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/validation"
+	orgDto "github.com/Caknoooo/go-gin-clean-starter/modules/organization/dto"
 	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
@@ -24,21 +29,34 @@ type (
 		VerifyEmail(ctx *gin.Context)
 		SendPasswordReset(ctx *gin.Context)
 		ResetPassword(ctx *gin.Context)
+		VerifyMFA(ctx *gin.Context)
+		UnlockAccount(ctx *gin.Context)
+		SendMagicLink(ctx *gin.Context)
+		MagicLinkLogin(ctx *gin.Context)
+		JWKS(ctx *gin.Context)
+		GetPasswordPolicy(ctx *gin.Context)
+		CSRFToken(ctx *gin.Context)
+		SwitchOrganization(ctx *gin.Context)
 	}
 
 	authController struct {
 		authService    service.AuthService
+		jwtService     service.JWTService
 		authValidation *validation.AuthValidation
+		cookies        *middlewares.AuthCookies
 		db             *gorm.DB
 	}
 )
 
 func NewAuthController(injector *do.Injector, as service.AuthService) AuthController {
 	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
+	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
 	authValidation := validation.NewAuthValidation()
 	return &authController{
 		authService:    as,
+		jwtService:     jwtService,
 		authValidation: authValidation,
+		cookies:        middlewares.NewAuthCookies(),
 		db:             db,
 	}
 }
@@ -60,8 +78,15 @@ func (c *authController) Register(ctx *gin.Context) {
 
 	result, err := c.authService.Register(ctx.Request.Context(), req)
 	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, userDto.ErrEmailAlreadyExists):
+			status = http.StatusConflict
+		case errors.Is(err, dto.ErrRegistrationDisabled), errors.Is(err, dto.ErrInvitationRequired):
+			status = http.StatusForbidden
+		}
 		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_REGISTER_USER, err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
+		ctx.JSON(status, res)
 		return
 	}
 
@@ -84,46 +109,90 @@ func (c *authController) Login(ctx *gin.Context) {
 		return
 	}
 
-	result, err := c.authService.Login(ctx.Request.Context(), req)
+	result, err := c.authService.Login(ctx.Request.Context(), req, clientInfo(ctx))
 	if err != nil {
+		status := http.StatusBadRequest
+		var throttled *dto.LoginThrottledError
+		if errors.As(err, &throttled) {
+			status = http.StatusTooManyRequests
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		}
+		if errors.Is(err, dto.ErrAccountDisabled) {
+			status = http.StatusForbidden
+		}
 		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_LOGIN, err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
+		ctx.JSON(status, res)
 		return
 	}
 
-	res := utils.BuildResponseSuccess(userDto.MESSAGE_SUCCESS_LOGIN, result)
-	ctx.JSON(http.StatusOK, res)
+	message := userDto.MESSAGE_SUCCESS_LOGIN
+	if result.MFARequired {
+		message = dto.MESSAGE_MFA_REQUIRED
+	}
+
+	respondWithTokens(ctx, c.cookies, message, result)
 }
 
+// RefreshToken takes the refresh token from the body, or from its cookie
+// when the client signed in with cookies.
 func (c *authController) RefreshToken(ctx *gin.Context) {
 	var req dto.RefreshTokenRequest
-	if err := ctx.ShouldBind(&req); err != nil {
+	cookie, fromCookie, err := c.cookies.RefreshToken(ctx)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REFRESH_TOKEN, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, res)
+		return
+	}
+
+	if fromCookie {
+		req.RefreshToken = cookie
+	} else if err := ctx.ShouldBind(&req); err != nil {
 		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
 
-	result, err := c.authService.RefreshToken(ctx.Request.Context(), req)
+	result, err := c.authService.RefreshToken(ctx.Request.Context(), req, clientInfo(ctx))
 	if err != nil {
+		if fromCookie {
+			c.cookies.Clear(ctx)
+		}
+		status := http.StatusUnauthorized
+		if errors.Is(err, dto.ErrAccountDisabled) {
+			status = http.StatusForbidden
+		}
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REFRESH_TOKEN, err.Error(), nil)
-		ctx.JSON(http.StatusUnauthorized, res)
+		ctx.JSON(status, res)
 		return
 	}
 
-	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REFRESH_TOKEN, result)
-	ctx.JSON(http.StatusOK, res)
+	respondWithTokens(ctx, c.cookies, dto.MESSAGE_SUCCESS_REFRESH_TOKEN, result)
 }
 
 func (c *authController) Logout(ctx *gin.Context) {
+	var req dto.LogoutRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
 	userId := ctx.MustGet("user_id").(string)
+	sessionId := ctx.GetString("session_id")
 
-	err := c.authService.Logout(ctx.Request.Context(), userId)
+	var err error
+	if principal, _ := middlewares.GetPrincipal(ctx); principal.ImpersonatorID != "" {
+		err = c.authService.EndImpersonation(ctx.Request.Context(), principal)
+	} else {
+		err = c.authService.Logout(ctx.Request.Context(), userId, sessionId, req)
+	}
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_LOGOUT, err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
+	c.cookies.Clear(ctx)
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_LOGOUT, nil)
 	ctx.JSON(http.StatusOK, res)
 }
@@ -203,3 +272,163 @@ func (c *authController) ResetPassword(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_RESET_PASSWORD, nil)
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (c *authController) VerifyMFA(ctx *gin.Context) {
+	var req dto.MFAVerifyRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.authService.VerifyMFA(ctx.Request.Context(), req, clientInfo(ctx))
+	if err != nil {
+		status := http.StatusUnauthorized
+		switch {
+		case errors.Is(err, dto.ErrAccountDisabled):
+			status = http.StatusForbidden
+		case errors.Is(err, dto.ErrMFATooManyAttempts):
+			status = http.StatusTooManyRequests
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_VERIFY_MFA, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	respondWithTokens(ctx, c.cookies, dto.MESSAGE_SUCCESS_VERIFY_MFA, result)
+}
+
+func (c *authController) UnlockAccount(ctx *gin.Context) {
+	var req dto.UnlockAccountRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.authService.UnlockAccount(ctx.Request.Context(), req, clientInfo(ctx)); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UNLOCK_ACCOUNT, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UNLOCK_ACCOUNT, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *authController) SendMagicLink(ctx *gin.Context) {
+	var req dto.SendMagicLinkRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.authService.SendMagicLink(ctx.Request.Context(), req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_SEND_MAGIC_LINK, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_SEND_MAGIC_LINK, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *authController) MagicLinkLogin(ctx *gin.Context) {
+	var req dto.MagicLinkLoginRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.authService.MagicLinkLogin(ctx.Request.Context(), req, clientInfo(ctx))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_MAGIC_LINK_LOGIN, err.Error(), nil)
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+
+	message := userDto.MESSAGE_SUCCESS_LOGIN
+	if result.MFARequired {
+		message = dto.MESSAGE_MFA_REQUIRED
+	}
+
+	respondWithTokens(ctx, c.cookies, message, result)
+}
+
+// JWKS publishes the token verification keys in the standard JWK Set format,
+// so it is intentionally not wrapped in the usual response envelope.
+func (c *authController) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.jwtService.GetJWKS())
+}
+
+func (c *authController) GetPasswordPolicy(ctx *gin.Context) {
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_PASSWORD_POLICY, c.authService.GetPasswordPolicy())
+	ctx.JSON(http.StatusOK, res)
+}
+
+// CSRFToken returns the token browser clients echo in the X-CSRF-Token header
+// on unsafe requests made with cookie authentication.
+func (c *authController) CSRFToken(ctx *gin.Context) {
+	token, err := c.cookies.CSRFToken(ctx)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_CSRF_TOKEN, err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_CSRF_TOKEN, dto.CSRFTokenResponse{CSRFToken: token})
+	ctx.JSON(http.StatusOK, res)
+}
+
+// respondWithTokens answers a successful sign-in, setting the auth cookies
+// when cookie mode is enabled.
+func respondWithTokens(ctx *gin.Context, cookies *middlewares.AuthCookies, message string, tokens dto.TokenResponse) {
+	body, err := cookies.Respond(ctx, tokens)
+	if err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(message, body)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// SwitchOrganization makes another organization the active one of the
+// current session, or none when no organization id is given.
+func (c *authController) SwitchOrganization(ctx *gin.Context) {
+	var req dto.SwitchOrganizationRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	result, err := c.authService.SwitchOrganization(ctx.Request.Context(), principal, req)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, orgDto.ErrOrganizationNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, dto.ErrCurrentSessionUnknown):
+			status = http.StatusUnauthorized
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_SWITCH_ORGANIZATION, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	respondWithTokens(ctx, c.cookies, dto.MESSAGE_SUCCESS_SWITCH_ORGANIZATION, result)
+}
+
+func clientInfo(ctx *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	MFAController interface {
+		GetStatus(ctx *gin.Context)
+		Enroll(ctx *gin.Context)
+		Confirm(ctx *gin.Context)
+		Disable(ctx *gin.Context)
+		RegenerateRecoveryCodes(ctx *gin.Context)
+	}
+
+	mfaController struct {
+		mfaService service.MFAService
+	}
+)
+
+func NewMFAController(ms service.MFAService) MFAController {
+	return &mfaController{
+		mfaService: ms,
+	}
+}
+
+// mfaErrorStatus answers 429 while wrong codes keep the user locked out.
+func mfaErrorStatus(err error) int {
+	if errors.Is(err, dto.ErrMFATooManyAttempts) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadRequest
+}
+
+func (c *mfaController) GetStatus(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.mfaService.GetStatus(ctx.Request.Context(), userId)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_MFA_STATUS, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_MFA_STATUS, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *mfaController) Enroll(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.mfaService.Enroll(ctx.Request.Context(), userId)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_ENROLL_MFA, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_ENROLL_MFA, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *mfaController) Confirm(ctx *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.mfaService.Confirm(ctx.Request.Context(), userId, req, clientInfo(ctx))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CONFIRM_MFA, err.Error(), nil)
+		ctx.JSON(mfaErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CONFIRM_MFA, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *mfaController) Disable(ctx *gin.Context) {
+	var req dto.MFADisableRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userId := ctx.MustGet("user_id").(string)
+
+	if err := c.mfaService.Disable(ctx.Request.Context(), userId, req, clientInfo(ctx)); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DISABLE_MFA, err.Error(), nil)
+		ctx.JSON(mfaErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DISABLE_MFA, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *mfaController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var req dto.MFACodeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.mfaService.RegenerateRecoveryCodes(ctx.Request.Context(), userId, req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REGENERATE_RECOVERY_CODES, err.Error(), nil)
+		ctx.JSON(mfaErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REGENERATE_RECOVERY_CODES, result)
+	ctx.JSON(http.StatusOK, res)
+}
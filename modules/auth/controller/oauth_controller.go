@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	OAuthController interface {
+		GetProviders(ctx *gin.Context)
+		Authorize(ctx *gin.Context)
+		Callback(ctx *gin.Context)
+	}
+
+	oauthController struct {
+		oauthService service.OAuthService
+		cookies      *middlewares.AuthCookies
+	}
+)
+
+func NewOAuthController(os service.OAuthService) OAuthController {
+	return &oauthController{
+		oauthService: os,
+		cookies:      middlewares.NewAuthCookies(),
+	}
+}
+
+func (c *oauthController) GetProviders(ctx *gin.Context) {
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_OAUTH_PROVIDERS, c.oauthService.GetProviders())
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *oauthController) Authorize(ctx *gin.Context) {
+	result, err := c.oauthService.Authorize(ctx.Request.Context(), ctx.Param("provider"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrOAuthProviderNotFound) {
+			status = http.StatusNotFound
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_OAUTH_AUTHORIZE, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	c.cookies.SetOAuthState(ctx, result.State, result.StateExpiresAt)
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_OAUTH_AUTHORIZE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *oauthController) Callback(ctx *gin.Context) {
+	var req dto.OAuthCallbackRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+	req.BrowserState = c.cookies.TakeOAuthState(ctx)
+
+	result, err := c.oauthService.Callback(ctx.Request.Context(), ctx.Param("provider"), req, clientInfo(ctx))
+	if err != nil {
+		status := http.StatusUnauthorized
+		switch {
+		case errors.Is(err, dto.ErrOAuthProviderNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, dto.ErrRegistrationDisabled), errors.Is(err, dto.ErrInvitationRequired):
+			status = http.StatusForbidden
+		case errors.Is(err, dto.ErrOAuthAccountUnverified):
+			status = http.StatusConflict
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_OAUTH_CALLBACK, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	message := userDto.MESSAGE_SUCCESS_LOGIN
+	if result.MFARequired {
+		message = dto.MESSAGE_MFA_REQUIRED
+	}
+
+	respondWithTokens(ctx, c.cookies, message, result)
+}
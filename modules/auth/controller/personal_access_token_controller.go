@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	PersonalAccessTokenController interface {
+		GetTokens(ctx *gin.Context)
+		CreateToken(ctx *gin.Context)
+		RevokeToken(ctx *gin.Context)
+	}
+
+	personalAccessTokenController struct {
+		personalAccessTokenService service.PersonalAccessTokenService
+	}
+)
+
+func NewPersonalAccessTokenController(ps service.PersonalAccessTokenService) PersonalAccessTokenController {
+	return &personalAccessTokenController{
+		personalAccessTokenService: ps,
+	}
+}
+
+func (c *personalAccessTokenController) GetTokens(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.personalAccessTokenService.List(ctx.Request.Context(), userId)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_ACCESS_TOKENS, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_ACCESS_TOKENS, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *personalAccessTokenController) CreateToken(ctx *gin.Context) {
+	var req dto.PersonalAccessTokenCreateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(userDto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	result, err := c.personalAccessTokenService.Create(ctx.Request.Context(), principal, req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrAccessTokenScopeDenied) {
+			status = http.StatusForbidden
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_ACCESS_TOKEN, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_ACCESS_TOKEN, result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *personalAccessTokenController) RevokeToken(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	if err := c.personalAccessTokenService.Revoke(ctx.Request.Context(), userId, ctx.Param("id")); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrAccessTokenNotFound) {
+			status = http.StatusNotFound
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REVOKE_ACCESS_TOKEN, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REVOKE_ACCESS_TOKEN, nil)
+	ctx.JSON(http.StatusOK, res)
+}
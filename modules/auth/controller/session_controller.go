@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	SessionController interface {
+		GetSessions(ctx *gin.Context)
+		RevokeSession(ctx *gin.Context)
+		RevokeOtherSessions(ctx *gin.Context)
+	}
+
+	sessionController struct {
+		sessionService service.SessionService
+	}
+)
+
+func NewSessionController(ss service.SessionService) SessionController {
+	return &sessionController{
+		sessionService: ss,
+	}
+}
+
+func (c *sessionController) GetSessions(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.sessionService.GetSessions(ctx.Request.Context(), userId, ctx.GetString("session_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_SESSIONS, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_SESSIONS, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *sessionController) RevokeSession(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	err := c.sessionService.RevokeSession(ctx.Request.Context(), userId, ctx.GetString("session_id"), ctx.Param("id"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REVOKE_SESSION, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REVOKE_SESSION, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *sessionController) RevokeOtherSessions(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	if err := c.sessionService.RevokeOtherSessions(ctx.Request.Context(), userId, ctx.GetString("session_id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REVOKE_SESSIONS, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REVOKE_SESSIONS, nil)
+	ctx.JSON(http.StatusOK, res)
+}
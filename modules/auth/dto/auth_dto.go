@@ -2,6 +2,10 @@ package dto
 
 import (
 	"errors"
+	"slices"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 )
 
 const (
@@ -13,32 +17,179 @@ const (
 	MESSAGE_SUCCESS_SEND_PASSWORD_RESET = "success send password reset"
 	MESSAGE_FAILED_RESET_PASSWORD       = "failed reset password"
 	MESSAGE_SUCCESS_RESET_PASSWORD      = "success reset password"
+	MESSAGE_FAILED_UNLOCK_ACCOUNT       = "failed unlock account"
+	MESSAGE_SUCCESS_UNLOCK_ACCOUNT      = "success unlock account"
+	MESSAGE_FAILED_SEND_MAGIC_LINK      = "failed send magic link"
+	MESSAGE_SUCCESS_SEND_MAGIC_LINK     = "if the account exists, a sign-in link has been sent"
+	MESSAGE_FAILED_MAGIC_LINK_LOGIN     = "failed magic link login"
+	MESSAGE_FAILED_GET_CSRF_TOKEN       = "failed get csrf token"
+	MESSAGE_SUCCESS_GET_CSRF_TOKEN      = "success get csrf token"
+	MESSAGE_FAILED_SWITCH_ORGANIZATION  = "failed switch organization"
+	MESSAGE_SUCCESS_SWITCH_ORGANIZATION = "success switch organization"
 )
 
 var (
-	ErrRefreshTokenNotFound = errors.New("refresh token not found")
-	ErrRefreshTokenExpired  = errors.New("refresh token expired")
-	ErrInvalidCredentials   = errors.New("invalid credentials")
-	ErrPasswordResetToken   = errors.New("password reset token invalid")
+	ErrRefreshTokenNotFound   = errors.New("refresh token not found")
+	ErrRefreshTokenExpired    = errors.New("refresh token expired")
+	ErrRefreshTokenReused     = errors.New("refresh token reuse detected, session revoked")
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrAccountDisabled        = errors.New("account is disabled")
+	ErrPasswordResetToken     = errors.New("password reset token invalid")
+	ErrOneTimeTokenInvalid    = errors.New("token invalid or already used")
+	ErrOneTimeTokenExpired    = errors.New("token expired")
+	ErrOneTimeTokenPurpose    = errors.New("unknown token purpose")
+	ErrTokenRevoked           = errors.New("token revoked")
+	ErrTokenSubjectMissing    = errors.New("token has no subject")
+	ErrPrincipalNotFound      = errors.New("authenticated user not found in context")
+	ErrInsufficientRole       = errors.New("insufficient role")
+	ErrInsufficientPermission = errors.New("insufficient permission")
+	ErrTooManyLoginAttempts   = errors.New("too many failed login attempts, try again later")
+	ErrUnlockToken            = errors.New("unlock token invalid")
+	ErrMagicLinkToken         = errors.New("sign-in link invalid or expired")
+	ErrMagicLinkUnverified    = errors.New("verify your email before signing in with a link")
+	ErrImpersonationForbidden = errors.New("not allowed while impersonating a user")
+	ErrCSRFTokenInvalid       = errors.New("missing or invalid CSRF token")
+	ErrRegistrationDisabled   = errors.New("registration is disabled")
+	ErrInvitationRequired     = errors.New("registration requires an invitation")
 )
 
+// LoginThrottledError rejects a login while the account or client is
+// throttled. It matches ErrTooManyLoginAttempts with errors.Is.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrTooManyLoginAttempts.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
 type (
 	RefreshTokenRequest struct {
 		RefreshToken string `json:"refresh_token" binding:"required"`
 	}
 
+	// TokenResponse carries either a token pair or, when the account has
+	// two-factor authentication enabled, the challenge token to exchange at
+	// /api/auth/mfa/verify.
 	TokenResponse struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
-		Role         string `json:"role"`
+		AccessToken  string `json:"access_token,omitempty"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		Role         string `json:"role,omitempty"`
+		MFARequired  bool   `json:"mfa_required,omitempty"`
+		MFAToken     string `json:"mfa_token,omitempty"`
+		// OrganizationID is the active organization of the session.
+		OrganizationID string `json:"organization_id,omitempty"`
+		// RefreshTokenExpiresAt sets the lifetime of the auth cookies.
+		RefreshTokenExpiresAt time.Time `json:"-"`
+	}
+
+	CSRFTokenResponse struct {
+		CSRFToken string `json:"csrf_token"`
+	}
+
+	// SwitchOrganizationRequest makes the organization active in the current
+	// session. An empty id leaves the session without one.
+	SwitchOrganizationRequest struct {
+		OrganizationID string `json:"organization_id" binding:"omitempty,uuid"`
+	}
+
+	LogoutRequest struct {
+		All bool `json:"all" form:"all"`
 	}
 
 	SendPasswordResetRequest struct {
 		Email string `json:"email" binding:"required,email"`
 	}
 
+	SendMagicLinkRequest struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	MagicLinkLoginRequest struct {
+		Token      string `json:"token" binding:"required"`
+		DeviceName string `json:"device_name" binding:"omitempty,max=100"`
+	}
+
+	UnlockAccountRequest struct {
+		Token string `json:"token" binding:"required"`
+	}
+
 	ResetPasswordRequest struct {
 		Token       string `json:"token" binding:"required"`
-		NewPassword string `json:"new_password" binding:"required,min=8"`
+		NewPassword string `json:"new_password" binding:"required"`
 	}
 )
+
+// Principal is the authenticated caller resolved from a validated access token.
+type Principal struct {
+	UserID      string    `json:"user_id"`
+	Role        string    `json:"role"`
+	SessionID   string    `json:"session_id"`
+	TokenID     string    `json:"token_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Permissions []string  `json:"permissions"`
+	// APIKeyID is set when the caller authenticated with a personal access
+	// token instead of a login session.
+	APIKeyID string `json:"api_key_id,omitempty"`
+	// ImpersonatorID is the admin acting as the user, taken from the token's
+	// act claim.
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
+	// OrganizationID is the active organization of the session and
+	// OrganizationRole the caller's role in it, taken from the org claim.
+	OrganizationID   string `json:"organization_id,omitempty"`
+	OrganizationRole string `json:"organization_role,omitempty"`
+}
+
+func NewPrincipal(userID string, role string) Principal {
+	return Principal{
+		UserID:      userID,
+		Role:        role,
+		Permissions: constants.RolePermissions[role],
+	}
+}
+
+// ClientInfo identifies the device a request comes from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+func (p Principal) HasRole(roles ...string) bool {
+	return slices.Contains(roles, p.Role)
+}
+
+// RestrictToScopes narrows the permissions to the given scopes. An empty
+// scope list leaves them unchanged.
+func (p Principal) RestrictToScopes(scopes []string) Principal {
+	if len(scopes) == 0 {
+		return p
+	}
+
+	permissions := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if p.HasPermission(scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+	p.Permissions = permissions
+	return p
+}
+
+// HasOrganizationRole reports whether the caller has an active organization
+// and one of the given roles in it.
+func (p Principal) HasOrganizationRole(roles ...string) bool {
+	return p.OrganizationID != "" && slices.Contains(roles, p.OrganizationRole)
+}
+
+func (p Principal) HasPermission(permission string) bool {
+	for _, granted := range p.Permissions {
+		if granted == constants.PERMISSION_ALL || granted == permission {
+			return true
+		}
+	}
+	return false
+}
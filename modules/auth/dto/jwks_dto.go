@@ -0,0 +1,20 @@
+package dto
+
+type (
+	// JWK is a public key in JSON Web Key format (RFC 7517).
+	JWK struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		N   string `json:"n,omitempty"`
+		E   string `json:"e,omitempty"`
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+		Y   string `json:"y,omitempty"`
+	}
+
+	JWKSet struct {
+		Keys []JWK `json:"keys"`
+	}
+)
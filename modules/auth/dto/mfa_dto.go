@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"errors"
+)
+
+const (
+	MESSAGE_FAILED_GET_MFA_STATUS             = "failed get mfa status"
+	MESSAGE_SUCCESS_GET_MFA_STATUS            = "success get mfa status"
+	MESSAGE_FAILED_ENROLL_MFA                 = "failed enroll mfa"
+	MESSAGE_SUCCESS_ENROLL_MFA                = "success enroll mfa"
+	MESSAGE_FAILED_CONFIRM_MFA                = "failed confirm mfa"
+	MESSAGE_SUCCESS_CONFIRM_MFA               = "success confirm mfa"
+	MESSAGE_FAILED_DISABLE_MFA                = "failed disable mfa"
+	MESSAGE_SUCCESS_DISABLE_MFA               = "success disable mfa"
+	MESSAGE_FAILED_REGENERATE_RECOVERY_CODES  = "failed regenerate recovery codes"
+	MESSAGE_SUCCESS_REGENERATE_RECOVERY_CODES = "success regenerate recovery codes"
+	MESSAGE_FAILED_VERIFY_MFA                 = "failed verify mfa"
+	MESSAGE_SUCCESS_VERIFY_MFA                = "success verify mfa"
+	MESSAGE_MFA_REQUIRED                      = "mfa required"
+)
+
+var (
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication already enabled")
+	ErrMFANotEnrolled      = errors.New("two-factor authentication not enrolled")
+	ErrMFANotEnabled       = errors.New("two-factor authentication not enabled")
+	ErrMFACodeInvalid      = errors.New("invalid two-factor code")
+	ErrMFATooManyAttempts  = errors.New("too many invalid two-factor codes, please try again later")
+	ErrMFAChallengeInvalid = errors.New("mfa token invalid or expired, please login again")
+	ErrMFAEncryptionKey    = errors.New("MFA_ENCRYPTION_KEY must be 32 hex encoded bytes")
+)
+
+type (
+	MFAStatusResponse struct {
+		Enabled                bool  `json:"enabled"`
+		RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+	}
+
+	MFAEnrollResponse struct {
+		Secret     string `json:"secret"`
+		OTPAuthURI string `json:"otpauth_uri"`
+	}
+
+	MFACodeRequest struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	MFADisableRequest struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	MFARecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	MFAVerifyRequest struct {
+		MFAToken   string `json:"mfa_token" binding:"required"`
+		Code       string `json:"code" binding:"required"`
+		DeviceName string `json:"device_name" binding:"omitempty,max=100"`
+	}
+)
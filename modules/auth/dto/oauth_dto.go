@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MESSAGE_SUCCESS_GET_OAUTH_PROVIDERS = "success get oauth providers"
+	MESSAGE_FAILED_OAUTH_AUTHORIZE      = "failed start oauth login"
+	MESSAGE_SUCCESS_OAUTH_AUTHORIZE     = "success start oauth login"
+	MESSAGE_FAILED_OAUTH_CALLBACK       = "failed oauth login"
+)
+
+var (
+	ErrOAuthProviderNotFound = errors.New("oauth provider not found")
+	ErrOAuthStateInvalid     = errors.New("oauth state invalid or expired")
+	ErrOAuthDenied           = errors.New("authorization denied by provider")
+	ErrOAuthExchange         = errors.New("oauth code exchange failed")
+	ErrOAuthNonceMismatch    = errors.New("id token nonce mismatch")
+	ErrOAuthEmailNotVerified = errors.New("provider did not return a verified email")
+	// ErrOAuthAccountUnverified refuses to link an external login to a local
+	// account whose owner never proved the email, which may have been
+	// registered by someone else ahead of the real owner.
+	ErrOAuthAccountUnverified = errors.New("an unverified account already uses this email; verify it and reset its password before signing in with this provider")
+)
+
+type (
+	OAuthProvidersResponse struct {
+		Providers []string `json:"providers"`
+	}
+
+	OAuthAuthorizeResponse struct {
+		AuthorizationURL string `json:"authorization_url"`
+		// State is delivered in a cookie to bind the flow to the browser.
+		State          string    `json:"-"`
+		StateExpiresAt time.Time `json:"-"`
+	}
+
+	OAuthCallbackRequest struct {
+		Code             string `form:"code"`
+		State            string `form:"state" binding:"required"`
+		Error            string `form:"error"`
+		ErrorDescription string `form:"error_description"`
+		// BrowserState is the state cookie set by Authorize.
+		BrowserState string `form:"-"`
+	}
+)
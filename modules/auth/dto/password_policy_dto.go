@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"errors"
+)
+
+const (
+	MESSAGE_SUCCESS_GET_PASSWORD_POLICY = "success get password policy"
+)
+
+var (
+	ErrPasswordTooShort        = errors.New("password is too short")
+	ErrPasswordTooLong         = errors.New("password is too long")
+	ErrPasswordMissingUpper    = errors.New("password must contain an uppercase letter")
+	ErrPasswordMissingLower    = errors.New("password must contain a lowercase letter")
+	ErrPasswordMissingDigit    = errors.New("password must contain a digit")
+	ErrPasswordMissingSymbol   = errors.New("password must contain a symbol")
+	ErrPasswordContainsProfile = errors.New("password must not contain your name or email")
+	ErrPasswordBreached        = errors.New("password appears in a list of breached or common passwords")
+	ErrPasswordReused          = errors.New("password was used recently")
+)
+
+type (
+	PasswordPolicyResponse struct {
+		MinLength     int  `json:"min_length"`
+		MaxLength     int  `json:"max_length"`
+		RequireUpper  bool `json:"require_upper"`
+		RequireLower  bool `json:"require_lower"`
+		RequireDigit  bool `json:"require_digit"`
+		RequireSymbol bool `json:"require_symbol"`
+		HistorySize   int  `json:"history_size"`
+	}
+)
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+// PERSONAL_ACCESS_TOKEN_PREFIX marks API keys so they are recognisable in
+// Authorization headers and by secret scanners.
+const PERSONAL_ACCESS_TOKEN_PREFIX = "pat_"
+
+const (
+	MESSAGE_FAILED_GET_ACCESS_TOKENS    = "failed get personal access tokens"
+	MESSAGE_SUCCESS_GET_ACCESS_TOKENS   = "success get personal access tokens"
+	MESSAGE_FAILED_CREATE_ACCESS_TOKEN  = "failed create personal access token"
+	MESSAGE_SUCCESS_CREATE_ACCESS_TOKEN = "success create personal access token"
+	MESSAGE_FAILED_REVOKE_ACCESS_TOKEN  = "failed revoke personal access token"
+	MESSAGE_SUCCESS_REVOKE_ACCESS_TOKEN = "success revoke personal access token"
+	MESSAGE_FAILED_API_KEY_NOT_ALLOWED  = "api keys cannot be used for this action"
+)
+
+var (
+	ErrAPIKeyInvalid            = errors.New("api key invalid")
+	ErrAPIKeyExpired            = errors.New("api key expired")
+	ErrAccessTokenNotFound      = errors.New("personal access token not found")
+	ErrAccessTokenExpiryInPast  = errors.New("expires_at must be in the future")
+	ErrAccessTokenScopeNotFound = errors.New("unknown scope")
+	ErrAccessTokenScopeDenied   = errors.New("scope exceeds your own permissions")
+	ErrAPIKeyNotAllowed         = errors.New(MESSAGE_FAILED_API_KEY_NOT_ALLOWED)
+)
+
+type (
+	PersonalAccessTokenCreateRequest struct {
+		Name      string     `json:"name" binding:"required,min=1,max=100"`
+		Scopes    []string   `json:"scopes" binding:"omitempty,dive,required"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	PersonalAccessTokenResponse struct {
+		ID         string     `json:"id"`
+		Name       string     `json:"name"`
+		Prefix     string     `json:"prefix"`
+		Scopes     []string   `json:"scopes"`
+		ExpiresAt  *time.Time `json:"expires_at"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+		LastUsedIP string     `json:"last_used_ip"`
+		CreatedAt  time.Time  `json:"created_at"`
+	}
+
+	// PersonalAccessTokenCreateResponse is the only response that carries the
+	// secret; it cannot be retrieved again.
+	PersonalAccessTokenCreateResponse struct {
+		PersonalAccessTokenResponse
+		Token string `json:"token"`
+	}
+)
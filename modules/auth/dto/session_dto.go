@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MESSAGE_FAILED_GET_SESSIONS     = "failed get sessions"
+	MESSAGE_SUCCESS_GET_SESSIONS    = "success get sessions"
+	MESSAGE_FAILED_REVOKE_SESSION   = "failed revoke session"
+	MESSAGE_SUCCESS_REVOKE_SESSION  = "success revoke session"
+	MESSAGE_FAILED_REVOKE_SESSIONS  = "failed revoke sessions"
+	MESSAGE_SUCCESS_REVOKE_SESSIONS = "success revoke sessions"
+)
+
+var (
+	ErrSessionNotFound       = errors.New("session not found")
+	ErrCurrentSessionUnknown = errors.New("current session unknown, please login again")
+	ErrRevokeCurrentSession  = errors.New("use logout to end the current session")
+)
+
+type (
+	SessionResponse struct {
+		ID         string     `json:"id"`
+		Name       string     `json:"name"`
+		UserAgent  string     `json:"user_agent"`
+		IPAddress  string     `json:"ip_address"`
+		CreatedAt  time.Time  `json:"created_at"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+		ExpiresAt  time.Time  `json:"expires_at"`
+		Current    bool       `json:"current"`
+	}
+)
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/scheduler"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+)
+
+const defaultLoginAttemptRetention = time.Hour * 24
+
+// RegisterJobs schedules the removal of expired tokens, OAuth states and
+// revocations, and of login attempts older than LOGIN_ATTEMPT_RETENTION.
+func RegisterJobs(s *scheduler.Scheduler, injector *do.Injector) error {
+	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
+	revocationStore := do.MustInvokeNamed[repository.RevocationStore](injector, constants.RevocationStore)
+
+	refreshTokenRepository := repository.NewRefreshTokenRepository(db)
+	oneTimeTokenRepository := repository.NewOneTimeTokenRepository(db)
+	oauthRepository := repository.NewOAuthRepository(db)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(db)
+
+	jobs := []scheduler.Job{
+		{
+			Name:     "delete_expired_refresh_tokens",
+			Schedule: "@hourly",
+			Run: func(ctx context.Context) error {
+				return refreshTokenRepository.DeleteExpired(ctx, nil)
+			},
+		},
+		{
+			Name:     "delete_expired_one_time_tokens",
+			Schedule: "@hourly",
+			Run: func(ctx context.Context) error {
+				return oneTimeTokenRepository.DeleteExpired(ctx, nil)
+			},
+		},
+		{
+			Name:     "delete_expired_oauth_states",
+			Schedule: "*/15 * * * *",
+			Run: func(ctx context.Context) error {
+				return oauthRepository.DeleteExpiredStates(ctx, nil)
+			},
+		},
+		{
+			Name:     "delete_old_login_attempts",
+			Schedule: "@hourly",
+			Run: func(ctx context.Context) error {
+				retention := helpers.GetDurationEnv("LOGIN_ATTEMPT_RETENTION", defaultLoginAttemptRetention)
+				return loginAttemptRepository.DeleteBefore(ctx, nil, time.Now().Add(-retention))
+			},
+		},
+	}
+
+	// Memory and Redis stores expire entries on their own.
+	if store, ok := revocationStore.(repository.DatabaseRevocationStore); ok {
+		jobs = append(jobs, scheduler.Job{
+			Name:     "delete_expired_revocations",
+			Schedule: "@hourly",
+			Run: func(ctx context.Context) error {
+				return store.DeleteExpired(ctx, nil)
+			},
+		})
+	}
+
+	for _, job := range jobs {
+		if err := s.Register(job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptSummary aggregates the failed logins matching a filter.
+type LoginAttemptSummary struct {
+	Count         int64
+	LastAttemptAt *time.Time
+}
+
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, attempt entities.LoginAttempt) (entities.LoginAttempt, error)
+	SummarizeByEmail(ctx context.Context, tx *gorm.DB, email string, since time.Time) (LoginAttemptSummary, error)
+	SummarizeByIPAddress(ctx context.Context, tx *gorm.DB, ipAddress string, since time.Time) (LoginAttemptSummary, error)
+	DeleteByEmail(ctx context.Context, tx *gorm.DB, email string) error
+	DeleteBefore(ctx context.Context, tx *gorm.DB, before time.Time) error
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{
+		db: db,
+	}
+}
+
+func (r *loginAttemptRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	attempt entities.LoginAttempt,
+) (entities.LoginAttempt, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&attempt).Error; err != nil {
+		return entities.LoginAttempt{}, err
+	}
+
+	return attempt, nil
+}
+
+func (r *loginAttemptRepository) SummarizeByEmail(
+	ctx context.Context,
+	tx *gorm.DB,
+	email string,
+	since time.Time,
+) (LoginAttemptSummary, error) {
+	return r.summarize(ctx, tx, "email = ? AND created_at > ?", email, since)
+}
+
+func (r *loginAttemptRepository) SummarizeByIPAddress(
+	ctx context.Context,
+	tx *gorm.DB,
+	ipAddress string,
+	since time.Time,
+) (LoginAttemptSummary, error) {
+	return r.summarize(ctx, tx, "ip_address = ? AND created_at > ?", ipAddress, since)
+}
+
+func (r *loginAttemptRepository) summarize(
+	ctx context.Context,
+	tx *gorm.DB,
+	query string,
+	args ...any,
+) (LoginAttemptSummary, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var summary LoginAttemptSummary
+	if err := tx.WithContext(ctx).
+		Model(&entities.LoginAttempt{}).
+		Select("COUNT(*) AS count, MAX(created_at) AS last_attempt_at").
+		Where(query, args...).
+		Scan(&summary).Error; err != nil {
+		return LoginAttemptSummary{}, err
+	}
+
+	return summary, nil
+}
+
+func (r *loginAttemptRepository) DeleteByEmail(ctx context.Context, tx *gorm.DB, email string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("email = ?", email).Delete(&entities.LoginAttempt{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) DeleteBefore(ctx context.Context, tx *gorm.DB, before time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("created_at < ?", before).Delete(&entities.LoginAttempt{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+type MFARepository interface {
+	FindByUserID(ctx context.Context, tx *gorm.DB, userID string) (entities.UserMFA, error)
+	Save(ctx context.Context, tx *gorm.DB, mfa entities.UserMFA) (entities.UserMFA, error)
+	MarkConfirmed(ctx context.Context, tx *gorm.DB, id string) error
+	DeleteByUserID(ctx context.Context, tx *gorm.DB, userID string) error
+	MarkStepUsed(ctx context.Context, tx *gorm.DB, id string, step int64) (bool, error)
+	RecordFailedAttempt(ctx context.Context, tx *gorm.DB, userID string, at time.Time, windowStart time.Time) error
+	ResetFailedAttempts(ctx context.Context, tx *gorm.DB, id string) error
+	ReplaceRecoveryCodes(ctx context.Context, tx *gorm.DB, userID string, codes []entities.MFARecoveryCode) error
+	ConsumeRecoveryCode(ctx context.Context, tx *gorm.DB, userID string, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, tx *gorm.DB, userID string) (int64, error)
+}
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &mfaRepository{
+		db: db,
+	}
+}
+
+func (r *mfaRepository) FindByUserID(ctx context.Context, tx *gorm.DB, userID string) (entities.UserMFA, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var mfa entities.UserMFA
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Take(&mfa).Error; err != nil {
+		return entities.UserMFA{}, err
+	}
+
+	return mfa, nil
+}
+
+func (r *mfaRepository) Save(ctx context.Context, tx *gorm.DB, mfa entities.UserMFA) (entities.UserMFA, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Save(&mfa).Error; err != nil {
+		return entities.UserMFA{}, err
+	}
+
+	return mfa, nil
+}
+
+func (r *mfaRepository) MarkConfirmed(ctx context.Context, tx *gorm.DB, id string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).
+		Model(&entities.UserMFA{}).
+		Where("id = ?", id).
+		Update("confirmed_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *mfaRepository) DeleteByUserID(ctx context.Context, tx *gorm.DB, userID string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Delete(&entities.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Delete(&entities.UserMFA{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MarkStepUsed records the time step of an accepted code. It reports false
+// when that step or a later one was already used, so a code cannot be replayed.
+func (r *mfaRepository) MarkStepUsed(ctx context.Context, tx *gorm.DB, id string, step int64) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Model(&entities.UserMFA{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// RecordFailedAttempt counts a wrong code. A failure after windowStart adds
+// to the count, an earlier one starts counting again.
+func (r *mfaRepository) RecordFailedAttempt(ctx context.Context, tx *gorm.DB, userID string, at time.Time, windowStart time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entities.UserMFA{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{
+			"failed_attempts": gorm.Expr("CASE WHEN last_failed_at IS NULL OR last_failed_at < ? THEN 1 ELSE failed_attempts + 1 END", windowStart),
+			"last_failed_at":  at,
+		}).Error
+}
+
+func (r *mfaRepository) ResetFailedAttempts(ctx context.Context, tx *gorm.DB, id string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entities.UserMFA{}).
+		Where("id = ? AND failed_attempts > 0", id).
+		Updates(map[string]any{"failed_attempts": 0, "last_failed_at": nil}).Error
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID string,
+	codes []entities.MFARecoveryCode,
+) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Delete(&entities.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.WithContext(ctx).Create(&codes).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ConsumeRecoveryCode marks a matching unused code as used and reports
+// whether one was found.
+func (r *mfaRepository) ConsumeRecoveryCode(ctx context.Context, tx *gorm.DB, userID string, codeHash string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Model(&entities.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *mfaRepository) CountUnusedRecoveryCodes(ctx context.Context, tx *gorm.DB, userID string) (int64, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var count int64
+	if err := tx.WithContext(ctx).
+		Model(&entities.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
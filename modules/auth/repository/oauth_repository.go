@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OAuthRepository interface {
+	CreateState(ctx context.Context, tx *gorm.DB, state entities.OAuthState) (entities.OAuthState, error)
+	TakeState(ctx context.Context, tx *gorm.DB, stateHash string) (entities.OAuthState, error)
+	DeleteExpiredStates(ctx context.Context, tx *gorm.DB) error
+	FindIdentity(ctx context.Context, tx *gorm.DB, provider string, subject string) (entities.UserIdentity, error)
+	CreateIdentity(ctx context.Context, tx *gorm.DB, identity entities.UserIdentity) (entities.UserIdentity, error)
+}
+
+type oauthRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthRepository(db *gorm.DB) OAuthRepository {
+	return &oauthRepository{
+		db: db,
+	}
+}
+
+func (r *oauthRepository) CreateState(
+	ctx context.Context,
+	tx *gorm.DB,
+	state entities.OAuthState,
+) (entities.OAuthState, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&state).Error; err != nil {
+		return entities.OAuthState{}, err
+	}
+
+	return state, nil
+}
+
+// TakeState loads and deletes the state in one step, so a state can only be
+// redeemed by a single callback.
+func (r *oauthRepository) TakeState(ctx context.Context, tx *gorm.DB, stateHash string) (entities.OAuthState, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var states []entities.OAuthState
+	result := tx.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("state_hash = ?", stateHash).
+		Delete(&states)
+	if result.Error != nil {
+		return entities.OAuthState{}, result.Error
+	}
+
+	if len(states) == 0 {
+		return entities.OAuthState{}, gorm.ErrRecordNotFound
+	}
+
+	return states[0], nil
+}
+
+func (r *oauthRepository) DeleteExpiredStates(ctx context.Context, tx *gorm.DB) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&entities.OAuthState{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *oauthRepository) FindIdentity(
+	ctx context.Context,
+	tx *gorm.DB,
+	provider string,
+	subject string,
+) (entities.UserIdentity, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var identity entities.UserIdentity
+	if err := tx.WithContext(ctx).
+		Preload("User").
+		Where("provider = ? AND subject = ?", provider, subject).
+		Take(&identity).Error; err != nil {
+		return entities.UserIdentity{}, err
+	}
+
+	return identity, nil
+}
+
+func (r *oauthRepository) CreateIdentity(
+	ctx context.Context,
+	tx *gorm.DB,
+	identity entities.UserIdentity,
+) (entities.UserIdentity, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&identity).Error; err != nil {
+		return entities.UserIdentity{}, err
+	}
+
+	return identity, nil
+}
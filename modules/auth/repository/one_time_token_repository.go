@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+type OneTimeTokenRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, token entities.OneTimeToken) (entities.OneTimeToken, error)
+	FindByTokenHash(ctx context.Context, tx *gorm.DB, tokenHash string, purpose string) (entities.OneTimeToken, error)
+	FindLatestByUserIDAndPurpose(ctx context.Context, tx *gorm.DB, userID string, purpose string) (entities.OneTimeToken, error)
+	MarkConsumed(ctx context.Context, tx *gorm.DB, id string) (bool, error)
+	IncrementAttempts(ctx context.Context, tx *gorm.DB, id string) error
+	DeleteByUserIDAndPurpose(ctx context.Context, tx *gorm.DB, userID string, purposes ...string) error
+	DeleteExpired(ctx context.Context, tx *gorm.DB) error
+}
+
+type oneTimeTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewOneTimeTokenRepository(db *gorm.DB) OneTimeTokenRepository {
+	return &oneTimeTokenRepository{
+		db: db,
+	}
+}
+
+func (r *oneTimeTokenRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	token entities.OneTimeToken,
+) (entities.OneTimeToken, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&token).Error; err != nil {
+		return entities.OneTimeToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *oneTimeTokenRepository) FindByTokenHash(
+	ctx context.Context,
+	tx *gorm.DB,
+	tokenHash string,
+	purpose string,
+) (entities.OneTimeToken, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var token entities.OneTimeToken
+	if err := tx.WithContext(ctx).
+		Where("token_hash = ? AND purpose = ?", tokenHash, purpose).
+		Take(&token).Error; err != nil {
+		return entities.OneTimeToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *oneTimeTokenRepository) FindLatestByUserIDAndPurpose(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID string,
+	purpose string,
+) (entities.OneTimeToken, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var token entities.OneTimeToken
+	if err := tx.WithContext(ctx).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("created_at DESC").
+		Take(&token).Error; err != nil {
+		return entities.OneTimeToken{}, err
+	}
+
+	return token, nil
+}
+
+// MarkConsumed flags the token as used. It reports false when another request
+// consumed the token first, so callers must not act on it.
+func (r *oneTimeTokenRepository) MarkConsumed(ctx context.Context, tx *gorm.DB, id string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Model(&entities.OneTimeToken{}).
+		Where("id = ? AND consumed_at IS NULL", id).
+		Update("consumed_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *oneTimeTokenRepository) IncrementAttempts(ctx context.Context, tx *gorm.DB, id string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).
+		Model(&entities.OneTimeToken{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *oneTimeTokenRepository) DeleteByUserIDAndPurpose(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID string,
+	purposes ...string,
+) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).
+		Where("user_id = ? AND purpose IN ?", userID, purposes).
+		Delete(&entities.OneTimeToken{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *oneTimeTokenRepository) DeleteExpired(ctx context.Context, tx *gorm.DB) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&entities.OneTimeToken{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, history entities.PasswordHistory) (entities.PasswordHistory, error)
+	FindRecentByUserID(ctx context.Context, tx *gorm.DB, userID string, limit int) ([]entities.PasswordHistory, error)
+	DeleteAllButRecent(ctx context.Context, tx *gorm.DB, userID string, keep int) error
+}
+
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{
+		db: db,
+	}
+}
+
+func (r *passwordHistoryRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	history entities.PasswordHistory,
+) (entities.PasswordHistory, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&history).Error; err != nil {
+		return entities.PasswordHistory{}, err
+	}
+
+	return history, nil
+}
+
+func (r *passwordHistoryRepository) FindRecentByUserID(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID string,
+	limit int,
+) ([]entities.PasswordHistory, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var histories []entities.PasswordHistory
+	if err := tx.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&histories).Error; err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+// DeleteAllButRecent drops everything except the user's keep newest entries.
+func (r *passwordHistoryRepository) DeleteAllButRecent(ctx context.Context, tx *gorm.DB, userID string, keep int) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	recent := tx.Model(&entities.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(keep)
+
+	return tx.WithContext(ctx).
+		Where("user_id = ? AND id NOT IN (?)", userID, recent).
+		Delete(&entities.PasswordHistory{}).Error
+}
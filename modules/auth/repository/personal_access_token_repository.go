@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, token entities.PersonalAccessToken) (entities.PersonalAccessToken, error)
+	FindByTokenHash(ctx context.Context, tx *gorm.DB, tokenHash string) (entities.PersonalAccessToken, error)
+	FindByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]entities.PersonalAccessToken, error)
+	DeleteByIDAndUserID(ctx context.Context, tx *gorm.DB, id string, userID string) (bool, error)
+	UpdateLastUsed(ctx context.Context, tx *gorm.DB, id string, usedAt time.Time, ipAddress string) error
+}
+
+type personalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalAccessTokenRepository(db *gorm.DB) PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{
+		db: db,
+	}
+}
+
+func (r *personalAccessTokenRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	token entities.PersonalAccessToken,
+) (entities.PersonalAccessToken, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&token).Error; err != nil {
+		return entities.PersonalAccessToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *personalAccessTokenRepository) FindByTokenHash(
+	ctx context.Context,
+	tx *gorm.DB,
+	tokenHash string,
+) (entities.PersonalAccessToken, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var token entities.PersonalAccessToken
+	if err := tx.WithContext(ctx).Where("token_hash = ?", tokenHash).Preload("User").Take(&token).Error; err != nil {
+		return entities.PersonalAccessToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *personalAccessTokenRepository) FindByUserID(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID string,
+) ([]entities.PersonalAccessToken, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var tokens []entities.PersonalAccessToken
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// DeleteByIDAndUserID reports false when the user owns no token with the id.
+func (r *personalAccessTokenRepository) DeleteByIDAndUserID(ctx context.Context, tx *gorm.DB, id string, userID string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&entities.PersonalAccessToken{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *personalAccessTokenRepository) UpdateLastUsed(
+	ctx context.Context,
+	tx *gorm.DB,
+	id string,
+	usedAt time.Time,
+	ipAddress string,
+) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).
+		Model(&entities.PersonalAccessToken{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"last_used_at": usedAt, "last_used_ip": ipAddress}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
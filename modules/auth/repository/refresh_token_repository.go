@@ -5,14 +5,22 @@ import (
 	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type RefreshTokenRepository interface {
 	Create(ctx context.Context, tx *gorm.DB, token entities.RefreshToken) (entities.RefreshToken, error)
-	FindByToken(ctx context.Context, tx *gorm.DB, token string) (entities.RefreshToken, error)
+	FindByTokenHash(ctx context.Context, tx *gorm.DB, tokenHash string) (entities.RefreshToken, error)
+	FindActiveByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]entities.RefreshToken, error)
+	FindFamilyIDsByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]string, error)
 	DeleteByUserID(ctx context.Context, tx *gorm.DB, userID string) error
-	DeleteByToken(ctx context.Context, tx *gorm.DB, token string) error
+	DeleteByTokenHash(ctx context.Context, tx *gorm.DB, tokenHash string) error
+	DeleteByFamilyID(ctx context.Context, tx *gorm.DB, familyID string) error
+	DeleteByUserIDExceptFamilyID(ctx context.Context, tx *gorm.DB, userID string, familyID string) error
+	MarkRotated(ctx context.Context, tx *gorm.DB, id string) (bool, error)
+	FindActiveByFamilyID(ctx context.Context, tx *gorm.DB, userID string, familyID string) (entities.RefreshToken, error)
+	SetOrganization(ctx context.Context, tx *gorm.DB, familyID string, organizationID *uuid.UUID) (bool, error)
 	DeleteExpired(ctx context.Context, tx *gorm.DB) error
 }
 
@@ -42,7 +50,7 @@ func (r *refreshTokenRepository) Create(
 	return token, nil
 }
 
-func (r *refreshTokenRepository) FindByToken(ctx context.Context, tx *gorm.DB, token string) (
+func (r *refreshTokenRepository) FindByTokenHash(ctx context.Context, tx *gorm.DB, tokenHash string) (
 	entities.RefreshToken,
 	error,
 ) {
@@ -51,13 +59,53 @@ func (r *refreshTokenRepository) FindByToken(ctx context.Context, tx *gorm.DB, t
 	}
 
 	var refreshToken entities.RefreshToken
-	if err := tx.WithContext(ctx).Where("token = ?", token).Preload("User").Take(&refreshToken).Error; err != nil {
+	if err := tx.WithContext(ctx).Where("token_hash = ?", tokenHash).Preload("User").Take(&refreshToken).Error; err != nil {
 		return entities.RefreshToken{}, err
 	}
 
 	return refreshToken, nil
 }
 
+// FindActiveByUserID returns the current token of every live session of the user.
+func (r *refreshTokenRepository) FindActiveByUserID(ctx context.Context, tx *gorm.DB, userID string) (
+	[]entities.RefreshToken,
+	error,
+) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var refreshTokens []entities.RefreshToken
+	if err := tx.WithContext(ctx).
+		Where("user_id = ? AND rotated_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&refreshTokens).Error; err != nil {
+		return nil, err
+	}
+
+	return refreshTokens, nil
+}
+
+// FindFamilyIDsByUserID lists every session of the user that still has a
+// refresh token row, including expired and rotated ones whose access tokens
+// may not have expired yet.
+func (r *refreshTokenRepository) FindFamilyIDsByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]string, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var familyIDs []string
+	if err := tx.WithContext(ctx).
+		Model(&entities.RefreshToken{}).
+		Where("user_id = ?", userID).
+		Distinct().
+		Pluck("family_id", &familyIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return familyIDs, nil
+}
+
 func (r *refreshTokenRepository) DeleteByUserID(ctx context.Context, tx *gorm.DB, userID string) error {
 	if tx == nil {
 		tx = r.db
@@ -70,18 +118,112 @@ func (r *refreshTokenRepository) DeleteByUserID(ctx context.Context, tx *gorm.DB
 	return nil
 }
 
-func (r *refreshTokenRepository) DeleteByToken(ctx context.Context, tx *gorm.DB, token string) error {
+func (r *refreshTokenRepository) DeleteByTokenHash(ctx context.Context, tx *gorm.DB, tokenHash string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("token_hash = ?", tokenHash).Delete(&entities.RefreshToken{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepository) DeleteByFamilyID(ctx context.Context, tx *gorm.DB, familyID string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("family_id = ?", familyID).Delete(&entities.RefreshToken{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepository) DeleteByUserIDExceptFamilyID(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID string,
+	familyID string,
+) error {
 	if tx == nil {
 		tx = r.db
 	}
 
-	if err := tx.WithContext(ctx).Where("token = ?", token).Delete(&entities.RefreshToken{}).Error; err != nil {
+	if err := tx.WithContext(ctx).
+		Where("user_id = ? AND family_id <> ?", userID, familyID).
+		Delete(&entities.RefreshToken{}).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// MarkRotated flags the token as replaced by a newer one. It reports false when
+// the token was already rotated, which means it is being replayed.
+func (r *refreshTokenRepository) MarkRotated(ctx context.Context, tx *gorm.DB, id string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Model(&entities.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL", id).
+		Update("rotated_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// FindActiveByFamilyID returns the current token of the user's session.
+func (r *refreshTokenRepository) FindActiveByFamilyID(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID string,
+	familyID string,
+) (entities.RefreshToken, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var refreshToken entities.RefreshToken
+	if err := tx.WithContext(ctx).
+		Where("user_id = ? AND family_id = ? AND rotated_at IS NULL AND expires_at > ?", userID, familyID, time.Now()).
+		Preload("User").
+		Take(&refreshToken).Error; err != nil {
+		return entities.RefreshToken{}, err
+	}
+
+	return refreshToken, nil
+}
+
+// SetOrganization changes the active organization of a session. It reports
+// false when the session has no current token.
+func (r *refreshTokenRepository) SetOrganization(
+	ctx context.Context,
+	tx *gorm.DB,
+	familyID string,
+	organizationID *uuid.UUID,
+) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Model(&entities.RefreshToken{}).
+		Where("family_id = ? AND rotated_at IS NULL", familyID).
+		Update("organization_id", organizationID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
 func (r *refreshTokenRepository) DeleteExpired(ctx context.Context, tx *gorm.DB) error {
 	if tx == nil {
 		tx = r.db
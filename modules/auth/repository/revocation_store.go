@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RevocationStore keeps revoked keys until they expire. Implementations must
+// forget an entry on their own once its expiry has passed.
+type RevocationStore interface {
+	Revoke(ctx context.Context, key string, expiresAt time.Time) error
+	// Lookup returns the revocation time of every given key that is revoked.
+	Lookup(ctx context.Context, keys ...string) (map[string]time.Time, error)
+}
+
+// NewRevocationStore builds the store selected by TOKEN_REVOCATION_STORE:
+// "database" (default), "memory" for single instance setups, or "redis"
+// which connects to any Redis-compatible server at REDIS_URL.
+func NewRevocationStore(db *gorm.DB) (RevocationStore, error) {
+	switch driver := os.Getenv("TOKEN_REVOCATION_STORE"); driver {
+	case "", "database":
+		return NewDatabaseRevocationStore(db), nil
+	case "memory":
+		return NewMemoryRevocationStore(), nil
+	case "redis":
+		return NewRedisRevocationStore(os.Getenv("REDIS_URL"))
+	default:
+		return nil, fmt.Errorf("unknown token revocation store %q", driver)
+	}
+}
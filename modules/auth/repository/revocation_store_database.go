@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DatabaseRevocationStore interface {
+	RevocationStore
+	DeleteExpired(ctx context.Context, tx *gorm.DB) error
+}
+
+type databaseRevocationStore struct {
+	db *gorm.DB
+}
+
+func NewDatabaseRevocationStore(db *gorm.DB) DatabaseRevocationStore {
+	return &databaseRevocationStore{
+		db: db,
+	}
+}
+
+func (s *databaseRevocationStore) Revoke(ctx context.Context, key string, expiresAt time.Time) error {
+	revokedToken := entities.RevokedToken{
+		Key:       key,
+		RevokedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_at", "expires_at"}),
+	}).Create(&revokedToken).Error
+}
+
+func (s *databaseRevocationStore) Lookup(ctx context.Context, keys ...string) (map[string]time.Time, error) {
+	var revokedTokens []entities.RevokedToken
+	if err := s.db.WithContext(ctx).
+		Where("key IN ? AND expires_at > ?", keys, time.Now()).
+		Find(&revokedTokens).Error; err != nil {
+		return nil, err
+	}
+
+	revoked := make(map[string]time.Time, len(revokedTokens))
+	for _, revokedToken := range revokedTokens {
+		revoked[revokedToken.Key] = revokedToken.RevokedAt
+	}
+
+	return revoked, nil
+}
+
+func (s *databaseRevocationStore) DeleteExpired(ctx context.Context, tx *gorm.DB) error {
+	if tx == nil {
+		tx = s.db
+	}
+
+	return tx.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&entities.RevokedToken{}).Error
+}
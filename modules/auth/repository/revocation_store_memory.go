@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryRevocationEntry struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+type memoryRevocationStore struct {
+	mu        sync.RWMutex
+	entries   map[string]memoryRevocationEntry
+	lastSweep time.Time
+}
+
+func NewMemoryRevocationStore() RevocationStore {
+	return &memoryRevocationStore{
+		entries:   make(map[string]memoryRevocationEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *memoryRevocationStore) Revoke(_ context.Context, key string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.entries[key] = memoryRevocationEntry{revokedAt: now, expiresAt: expiresAt}
+
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	return nil
+}
+
+func (s *memoryRevocationStore) Lookup(_ context.Context, keys ...string) (map[string]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	revoked := make(map[string]time.Time)
+	for _, key := range keys {
+		if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+			revoked[key] = entry.revokedAt
+		}
+	}
+
+	return revoked, nil
+}
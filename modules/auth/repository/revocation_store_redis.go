@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisRevocationPrefix = "revoked:"
+
+type redisRevocationStore struct {
+	client *redis.Client
+}
+
+func NewRedisRevocationStore(url string) (RevocationStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisRevocationStore{
+		client: redis.NewClient(options),
+	}, nil
+}
+
+// Revoke stores the revocation time with a TTL, so Redis drops the entry on its own.
+func (s *redisRevocationStore) Revoke(ctx context.Context, key string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	value := strconv.FormatInt(time.Now().UnixNano(), 10)
+	return s.client.Set(ctx, redisRevocationPrefix+key, value, ttl).Err()
+}
+
+func (s *redisRevocationStore) Lookup(ctx context.Context, keys ...string) (map[string]time.Time, error) {
+	revoked := make(map[string]time.Time)
+	if len(keys) == 0 {
+		return revoked, nil
+	}
+
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = redisRevocationPrefix + key
+	}
+
+	values, err := s.client.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		nanos, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		revoked[keys[i]] = time.Unix(0, nanos)
+	}
+
+	return revoked, nil
+}
+
+// Shutdown closes the connection pool. It is called by the injector.
+func (s *redisRevocationStore) Shutdown() error {
+	return s.client.Close()
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+type SecurityEventRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, event entities.SecurityEvent) (entities.SecurityEvent, error)
+}
+
+type securityEventRepository struct {
+	db *gorm.DB
+}
+
+func NewSecurityEventRepository(db *gorm.DB) SecurityEventRepository {
+	return &securityEventRepository{
+		db: db,
+	}
+}
+
+func (r *securityEventRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	event entities.SecurityEvent,
+) (entities.SecurityEvent, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&event).Error; err != nil {
+		return entities.SecurityEvent{}, err
+	}
+
+	return event, nil
+}
@@ -1,23 +1,74 @@
 package auth
 
 import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
 	"github.com/gin-gonic/gin"
 	"github.com/samber/do"
 )
 
 func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
 	authController := do.MustInvoke[controller.AuthController](injector)
+	sessionController := do.MustInvoke[controller.SessionController](injector)
+	mfaController := do.MustInvoke[controller.MFAController](injector)
+	oauthController := do.MustInvoke[controller.OAuthController](injector)
+	personalAccessTokenController := do.MustInvoke[controller.PersonalAccessTokenController](injector)
+	authenticator := do.MustInvokeNamed[service.Authenticator](injector, constants.Authenticator)
+
+	server.GET("/.well-known/jwks.json", authController.JWKS)
 
 	authRoutes := server.Group("/api/auth")
 	{
 		authRoutes.POST("/register", authController.Register)
 		authRoutes.POST("/login", authController.Login)
 		authRoutes.POST("/refresh", authController.RefreshToken)
-		authRoutes.POST("/logout", authController.Logout)
+		authRoutes.POST("/logout", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), authController.Logout)
+		authRoutes.POST(
+			"/switch-organization",
+			middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), middlewares.DenyImpersonation(),
+			authController.SwitchOrganization,
+		)
 		authRoutes.POST("/send-verification-email", authController.SendVerificationEmail)
 		authRoutes.POST("/verify-email", authController.VerifyEmail)
 		authRoutes.POST("/send-password-reset", authController.SendPasswordReset)
 		authRoutes.POST("/reset-password", authController.ResetPassword)
+		authRoutes.GET("/password-policy", authController.GetPasswordPolicy)
+		authRoutes.GET("/csrf", authController.CSRFToken)
+		authRoutes.POST("/mfa/verify", authController.VerifyMFA)
+		authRoutes.POST("/unlock", authController.UnlockAccount)
+		authRoutes.POST("/magic-link", authController.SendMagicLink)
+		authRoutes.POST("/magic-link/consume", authController.MagicLinkLogin)
+	}
+
+	oauthRoutes := authRoutes.Group("/oauth")
+	{
+		oauthRoutes.GET("", oauthController.GetProviders)
+		oauthRoutes.GET("/:provider", oauthController.Authorize)
+		oauthRoutes.GET("/:provider/callback", oauthController.Callback)
+	}
+
+	sessionRoutes := authRoutes.Group("/sessions", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), middlewares.DenyImpersonation())
+	{
+		sessionRoutes.GET("", sessionController.GetSessions)
+		sessionRoutes.DELETE("", sessionController.RevokeOtherSessions)
+		sessionRoutes.DELETE("/:id", sessionController.RevokeSession)
+	}
+
+	mfaRoutes := authRoutes.Group("/mfa", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), middlewares.DenyImpersonation())
+	{
+		mfaRoutes.GET("", mfaController.GetStatus)
+		mfaRoutes.POST("/enroll", mfaController.Enroll)
+		mfaRoutes.POST("/confirm", mfaController.Confirm)
+		mfaRoutes.POST("/disable", mfaController.Disable)
+		mfaRoutes.POST("/recovery-codes", mfaController.RegenerateRecoveryCodes)
+	}
+
+	tokenRoutes := authRoutes.Group("/tokens", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), middlewares.DenyImpersonation())
+	{
+		tokenRoutes.GET("", personalAccessTokenController.GetTokens)
+		tokenRoutes.POST("", personalAccessTokenController.CreateToken)
+		tokenRoutes.DELETE("/:id", personalAccessTokenController.RevokeToken)
 	}
 }
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+const defaultAccountStatusCacheTTL = time.Second * 30
+
+// AccountStatusService tells whether an account may still authenticate, so
+// access tokens of disabled or deleted accounts stop working before they
+// expire.
+type AccountStatusService interface {
+	IsActive(ctx context.Context, userId string) (bool, error)
+	Invalidate(userId string)
+}
+
+type accountStatusEntry struct {
+	active    bool
+	expiresAt time.Time
+}
+
+type accountStatusService struct {
+	userRepository repository.UserRepository
+	ttl            time.Duration
+	mu             sync.RWMutex
+	entries        map[string]accountStatusEntry
+	lastSweep      time.Time
+	db             *gorm.DB
+}
+
+// NewAccountStatusService caches each answer for ACCOUNT_STATUS_CACHE_TTL, 30
+// seconds by default, so authenticating a request does not query the users
+// table every time. Disabling an account also revokes its tokens, so the
+// cache only delays the check on replicas that did not handle the change.
+func NewAccountStatusService(userRepo repository.UserRepository, db *gorm.DB) AccountStatusService {
+	return &accountStatusService{
+		userRepository: userRepo,
+		ttl:            helpers.GetNonNegativeDurationEnv("ACCOUNT_STATUS_CACHE_TTL", defaultAccountStatusCacheTTL),
+		entries:        make(map[string]accountStatusEntry),
+		lastSweep:      time.Now(),
+		db:             db,
+	}
+}
+
+// IsActive reports false for disabled accounts and for accounts that no
+// longer exist.
+func (s *accountStatusService) IsActive(ctx context.Context, userId string) (bool, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	entry, ok := s.entries[userId]
+	s.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.active, nil
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, s.db, userId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	active := err == nil && user.IsActive
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[userId] = accountStatusEntry{active: active, expiresAt: now.Add(s.ttl)}
+
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	return active, nil
+}
+
+// Invalidate drops the cached status of the user after it changed.
+func (s *accountStatusService) Invalidate(userId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, userId)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+// apiKeyLastUsedInterval limits how often using an API key writes its last
+// used time, so busy clients do not cause a write per request.
+const apiKeyLastUsedInterval = time.Minute
+
+// Authenticator resolves the caller of a request from the credential it presents.
+type Authenticator interface {
+	AuthenticateAccessToken(ctx context.Context, token string) (dto.Principal, error)
+	AuthenticateAPIKey(ctx context.Context, key string, ipAddress string) (dto.Principal, error)
+}
+
+type authenticator struct {
+	jwtService                    JWTService
+	tokenRevocationService        TokenRevocationService
+	personalAccessTokenRepository authRepo.PersonalAccessTokenRepository
+	rbacService                   rbacService.RBACService
+	accountStatusService          AccountStatusService
+}
+
+func NewAuthenticator(
+	jwtService JWTService,
+	tokenRevocationService TokenRevocationService,
+	personalAccessTokenRepo authRepo.PersonalAccessTokenRepository,
+	rbacService rbacService.RBACService,
+	accountStatusService AccountStatusService,
+) Authenticator {
+	return &authenticator{
+		jwtService:                    jwtService,
+		tokenRevocationService:        tokenRevocationService,
+		personalAccessTokenRepository: personalAccessTokenRepo,
+		rbacService:                   rbacService,
+		accountStatusService:          accountStatusService,
+	}
+}
+
+func (a *authenticator) AuthenticateAccessToken(ctx context.Context, token string) (dto.Principal, error) {
+	claims, err := a.jwtService.GetClaimsByToken(token)
+	if err != nil {
+		return dto.Principal{}, err
+	}
+
+	if claims.UserID == "" {
+		return dto.Principal{}, dto.ErrTokenSubjectMissing
+	}
+
+	revoked, err := a.tokenRevocationService.IsRevoked(ctx, claims)
+	if err != nil {
+		return dto.Principal{}, err
+	}
+
+	if revoked {
+		return dto.Principal{}, dto.ErrTokenRevoked
+	}
+
+	// An impersonation token stops working as soon as either the target or
+	// the admin acting as them is disabled.
+	accounts := []string{claims.UserID}
+	if claims.Actor != nil {
+		accounts = append(accounts, claims.Actor.Subject)
+	}
+	for _, userId := range accounts {
+		active, err := a.accountStatusService.IsActive(ctx, userId)
+		if err != nil {
+			return dto.Principal{}, err
+		}
+		if !active {
+			return dto.Principal{}, dto.ErrAccountDisabled
+		}
+	}
+
+	principal := dto.NewPrincipal(claims.UserID, claims.Role)
+	if claims.Permissions != nil {
+		principal.Permissions = claims.Permissions
+	}
+	principal.SessionID = claims.SessionID
+	principal.TokenID = claims.ID
+	if claims.Actor != nil {
+		principal.ImpersonatorID = claims.Actor.Subject
+	}
+	if claims.Organization != nil {
+		principal.OrganizationID = claims.Organization.ID
+		principal.OrganizationRole = claims.Organization.Role
+	}
+	if claims.ExpiresAt != nil {
+		principal.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	return principal, nil
+}
+
+// AuthenticateAPIKey resolves a personal access token to its owner. The
+// owner's current permissions apply, restricted to the token's scopes.
+func (a *authenticator) AuthenticateAPIKey(ctx context.Context, key string, ipAddress string) (dto.Principal, error) {
+	token, err := a.personalAccessTokenRepository.FindByTokenHash(ctx, nil, helpers.HashToken(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.Principal{}, dto.ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return dto.Principal{}, err
+	}
+
+	if !token.User.IsActive {
+		return dto.Principal{}, dto.ErrAccountDisabled
+	}
+
+	now := time.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return dto.Principal{}, dto.ErrAPIKeyExpired
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiKeyLastUsedInterval || token.LastUsedIP != ipAddress {
+		if err := a.personalAccessTokenRepository.UpdateLastUsed(ctx, nil, token.ID.String(), now, ipAddress); err != nil {
+			return dto.Principal{}, err
+		}
+	}
+
+	permissions, err := a.rbacService.GetUserPermissions(ctx, nil, token.User)
+	if err != nil {
+		return dto.Principal{}, err
+	}
+
+	principal := dto.NewPrincipal(token.UserID.String(), token.User.Role)
+	principal.Permissions = permissions
+	principal = principal.RestrictToScopes(token.Scopes)
+	principal.APIKeyID = token.ID.String()
+	if token.ExpiresAt != nil {
+		principal.ExpiresAt = *token.ExpiresAt
+	}
+
+	return principal, nil
+}
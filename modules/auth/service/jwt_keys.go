@@ -0,0 +1,396 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var errNoSigningKey = errors.New("no jwt signing key configured")
+
+const (
+	// rotationLockFile is created in the keys directory by the replica that
+	// rotates, so replicas sharing the directory do not all add a key.
+	rotationLockFile = ".rotate.lock"
+	// rotationLockTimeout is how long a lock file is honoured. An older one
+	// was left behind by a replica that stopped while rotating.
+	rotationLockTimeout = 5 * time.Minute
+)
+
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	private   any
+	public    any
+	createdAt time.Time
+}
+
+// keySet holds the keys tokens are signed and verified with. Keys are ordered
+// from oldest to newest and the newest one signs new tokens. Older keys stay
+// available for verification until gracePeriod after they were superseded.
+type keySet struct {
+	mu          sync.RWMutex
+	method      jwt.SigningMethod
+	dir         string
+	gracePeriod time.Duration
+	keys        []*signingKey
+}
+
+func newSymmetricKeySet(secret string) *keySet {
+	return &keySet{
+		method: jwt.SigningMethodHS256,
+		keys: []*signingKey{{
+			method:  jwt.SigningMethodHS256,
+			private: []byte(secret),
+			public:  []byte(secret),
+		}},
+	}
+}
+
+// newAsymmetricKeySet loads the PEM encoded private keys stored in dir. The
+// file name without extension is used as the key id. When dir holds no key
+// and generate is true, a new key is created (and written to dir if set).
+func newAsymmetricKeySet(method jwt.SigningMethod, dir string, gracePeriod time.Duration, generate bool) (*keySet, error) {
+	ks := &keySet{
+		method:      method,
+		dir:         dir,
+		gracePeriod: gracePeriod,
+	}
+
+	if err := ks.reload(); err != nil {
+		return nil, err
+	}
+
+	if len(ks.keys) == 0 {
+		if !generate {
+			return nil, errNoSigningKey
+		}
+		if err := ks.rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ks, nil
+}
+
+func (ks *keySet) active() *signingKey {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	return ks.keys[len(ks.keys)-1]
+}
+
+func (ks *keySet) find(kid string) (*signingKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	for _, key := range ks.keys {
+		if key.id == kid {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// reload picks up keys written to dir, including keys rotated by other replicas.
+// When dir no longer holds a usable key, the current keys are kept and an
+// error is returned so signing never runs out of keys.
+func (ks *keySet) reload() error {
+	if ks.dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var keys []*signingKey
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pem" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(filepath.Join(ks.dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		key, err := parsePrivateKey(content)
+		if err != nil {
+			return fmt.Errorf("jwt key %s: %w", entry.Name(), err)
+		}
+
+		method, public, err := signingMethodFor(key)
+		if err != nil {
+			return fmt.Errorf("jwt key %s: %w", entry.Name(), err)
+		}
+
+		// Keys of another algorithm are left in place for a future switch.
+		if method.Alg() != ks.method.Alg() {
+			continue
+		}
+
+		keys = append(keys, &signingKey{
+			id:        strings.TrimSuffix(entry.Name(), ".pem"),
+			method:    method,
+			private:   key,
+			public:    public,
+			createdAt: info.ModTime(),
+		})
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].createdAt.Before(keys[j].createdAt)
+	})
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if len(keys) == 0 {
+		if len(ks.keys) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%w in %s, keeping the current keys", errNoSigningKey, ks.dir)
+	}
+
+	ks.keys = keys
+	ks.pruneLocked(time.Now())
+	return nil
+}
+
+// rotate generates a new key and makes it the active signing key.
+func (ks *keySet) rotate() error {
+	private, err := generatePrivateKey(ks.method)
+	if err != nil {
+		return err
+	}
+
+	_, public, err := signingMethodFor(private)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+
+	key := &signingKey{
+		id:        time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix),
+		method:    ks.method,
+		private:   private,
+		public:    public,
+		createdAt: time.Now(),
+	}
+
+	if ks.dir != "" {
+		der, err := x509.MarshalPKCS8PrivateKey(private)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(ks.dir, 0700); err != nil {
+			return err
+		}
+
+		content := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := os.WriteFile(filepath.Join(ks.dir, key.id+".pem"), content, 0600); err != nil {
+			return err
+		}
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.keys = append(ks.keys, key)
+	ks.pruneLocked(time.Now())
+	return nil
+}
+
+// rotateIfOlder rotates when the active key is older than maxAge. With a keys
+// directory it holds the rotation lock file and reloads first, so a key just
+// written by another replica is used instead of adding one more. It reports
+// false when no key was added.
+func (ks *keySet) rotateIfOlder(maxAge time.Duration) (bool, error) {
+	if ks.dir == "" {
+		if time.Since(ks.active().createdAt) < maxAge {
+			return false, nil
+		}
+		return true, ks.rotate()
+	}
+
+	unlock, locked, err := ks.lockDir()
+	if err != nil || !locked {
+		return false, err
+	}
+	defer unlock()
+
+	if err := ks.reload(); err != nil {
+		return false, err
+	}
+
+	if time.Since(ks.active().createdAt) < maxAge {
+		return false, nil
+	}
+	return true, ks.rotate()
+}
+
+// lockDir creates the rotation lock file. It reports false while another
+// replica holds the lock.
+func (ks *keySet) lockDir() (func(), bool, error) {
+	if err := os.MkdirAll(ks.dir, 0700); err != nil {
+		return nil, false, err
+	}
+
+	path := filepath.Join(ks.dir, rotationLockFile)
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > rotationLockTimeout {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, false, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	file.Close()
+
+	return func() { os.Remove(path) }, true, nil
+}
+
+// pruneLocked drops keys that were superseded more than gracePeriod ago.
+func (ks *keySet) pruneLocked(now time.Time) {
+	var keys []*signingKey
+	for i, key := range ks.keys {
+		if i == len(ks.keys)-1 || now.Before(ks.keys[i+1].createdAt.Add(ks.gracePeriod)) {
+			keys = append(keys, key)
+		}
+	}
+	ks.keys = keys
+}
+
+func (ks *keySet) jwks() dto.JWKSet {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	set := dto.JWKSet{Keys: []dto.JWK{}}
+	for _, key := range ks.keys {
+		jwk := dto.JWK{
+			Use: "sig",
+			Kid: key.id,
+			Alg: key.method.Alg(),
+		}
+
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (public.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = public.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			// Symmetric secrets are never published.
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func signingMethodByName(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case "", "HS256":
+		return jwt.SigningMethodHS256, nil
+	case "RS256":
+		return jwt.SigningMethodRS256, nil
+	case "ES256":
+		return jwt.SigningMethodES256, nil
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", algorithm)
+	}
+}
+
+func signingMethodFor(private any) (jwt.SigningMethod, any, error) {
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, &key.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return nil, nil, errors.New("only P-256 ecdsa keys are supported")
+		}
+		return jwt.SigningMethodES256, &key.PublicKey, nil
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, key.Public(), nil
+	default:
+		return nil, nil, errors.New("unsupported private key type")
+	}
+}
+
+func generatePrivateKey(method jwt.SigningMethod) (any, error) {
+	switch method {
+	case jwt.SigningMethodRS256:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jwt.SigningMethodEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	default:
+		return nil, fmt.Errorf("cannot generate key for %s", method.Alg())
+	}
+}
+
+func parsePrivateKey(content []byte) (any, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("invalid pem")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+}
@@ -1,66 +1,251 @@
 package service
 
 import (
-	"crypto/rand"
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
+const accessTokenExpiry = time.Minute * 15
+
 type JWTService interface {
-	GenerateAccessToken(userId string, role string) string
+	GenerateAccessToken(subject AccessTokenSubject) string
 	GenerateRefreshToken() (string, time.Time)
 	ValidateToken(token string) (*jwt.Token, error)
 	GetUserIDByToken(token string) (string, error)
+	GetClaimsByToken(token string) (*JWTCustomClaim, error)
+	HashRefreshToken(token string) string
+	GetJWKS() dto.JWKSet
+	ReloadKeys() error
+	RotateKeys() (bool, error)
 }
 
-type jwtCustomClaim struct {
+type JWTCustomClaim struct {
 	UserID string `json:"user_id"`
 	Role   string `json:"role"`
+	// Permissions are resolved from the user's roles when the token is
+	// issued. Tokens issued without them fall back to the built-in role.
+	Permissions  []string           `json:"permissions"`
+	SessionID    string             `json:"sid,omitempty"`
+	Actor        *ActorClaim        `json:"act,omitempty"`
+	Organization *OrganizationClaim `json:"org,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ActorClaim is the RFC 8693 "act" claim naming the user acting on behalf of
+// the subject, e.g. an admin impersonating them.
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// OrganizationClaim is the active organization of the session and the role
+// of the user in it.
+type OrganizationClaim struct {
+	ID   string `json:"id"`
+	Role string `json:"role"`
+}
+
+// AccessTokenSubject describes who an access token is issued for.
+type AccessTokenSubject struct {
+	UserID      string
+	Role        string
+	Permissions []string
+	SessionID   string
+	// ActorID is set when someone else acts as the user.
+	ActorID string
+	// OrganizationID and OrganizationRole are set when the session has an
+	// active organization.
+	OrganizationID   string
+	OrganizationRole string
+	// ExpiresIn shortens the token lifetime below the default when set.
+	ExpiresIn time.Duration
+}
+
 type jwtService struct {
-	secretKey     string
-	issuer        string
-	accessExpiry  time.Duration
-	refreshExpiry time.Duration
+	keys             *keySet
+	refreshSecret    string
+	issuer           string
+	accessExpiry     time.Duration
+	refreshExpiry    time.Duration
+	rotationInterval time.Duration
+	stop             chan struct{}
 }
 
-func NewJWTService() JWTService {
-	return &jwtService{
-		secretKey:     getSecretKey(),
-		issuer:        "Template",
-		accessExpiry:  time.Minute * 15,
-		refreshExpiry: time.Hour * 24 * 7,
+// NewJWTService signs access tokens with JWT_ALGORITHM (HS256, RS256, ES256 or
+// EdDSA). Asymmetric keys are read from JWT_KEYS_DIR and, when
+// JWT_KEY_ROTATION_INTERVAL is set, rotated in the background while older keys
+// keep verifying tokens for JWT_KEY_GRACE_PERIOD.
+func NewJWTService() (JWTService, error) {
+	accessExpiry := accessTokenExpiry
+	production := os.Getenv("APP_ENV") == constants.ENUM_RUN_PRODUCTION
+
+	method, err := signingMethodByName(os.Getenv("JWT_ALGORITHM"))
+	if err != nil {
+		return nil, err
+	}
+
+	refreshSecret, err := getRefreshTokenSecret(production)
+	if err != nil {
+		return nil, err
+	}
+
+	rotationInterval := helpers.GetDurationEnv("JWT_KEY_ROTATION_INTERVAL", 0)
+
+	var keys *keySet
+	if method == jwt.SigningMethodHS256 {
+		secretKey, err := getSecretKey(production)
+		if err != nil {
+			return nil, err
+		}
+		keys = newSymmetricKeySet(secretKey)
+	} else {
+		gracePeriod := helpers.GetDurationEnv("JWT_KEY_GRACE_PERIOD", accessExpiry+time.Hour)
+		if gracePeriod < accessExpiry {
+			gracePeriod = accessExpiry
+		}
+
+		// Outside production a throwaway key is generated so the app still boots.
+		// Production only generates keys when they are persisted and rotated.
+		keysDir := os.Getenv("JWT_KEYS_DIR")
+		generate := !production || (keysDir != "" && rotationInterval > 0)
+		keys, err = newAsymmetricKeySet(method, keysDir, gracePeriod, generate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	service := &jwtService{
+		keys:             keys,
+		refreshSecret:    refreshSecret,
+		issuer:           "Template",
+		accessExpiry:     accessExpiry,
+		refreshExpiry:    time.Hour * 24 * 7,
+		rotationInterval: rotationInterval,
+		stop:             make(chan struct{}),
+	}
+
+	if method != jwt.SigningMethodHS256 && service.rotationInterval > 0 {
+		go service.rotateKeys()
 	}
+
+	return service, nil
 }
 
-func getSecretKey() string {
+func getSecretKey(production bool) (string, error) {
 	secretKey := os.Getenv("JWT_SECRET")
 	if secretKey == "" {
+		if production {
+			return "", errNoSigningKey
+		}
 		secretKey = "Template"
 	}
-	return secretKey
+	return secretKey, nil
 }
 
-func (j *jwtService) GenerateAccessToken(userId string, role string) string {
-	claims := jwtCustomClaim{
-		userId,
-		role,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessExpiry)),
+func getRefreshTokenSecret(production bool) (string, error) {
+	secretKey := os.Getenv("REFRESH_TOKEN_SECRET")
+	if secretKey == "" {
+		secretKey = os.Getenv("JWT_SECRET")
+	}
+	if secretKey == "" {
+		if production {
+			return "", errors.New("REFRESH_TOKEN_SECRET is required in production")
+		}
+		secretKey = "Template"
+	}
+	return secretKey, nil
+}
+
+// rotateKeys checks once a minute whether the active key is older than the
+// rotation interval. Keys rotated by other replicas sharing JWT_KEYS_DIR are
+// picked up on the same tick.
+func (j *jwtService) rotateKeys() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			if err := j.ReloadKeys(); err != nil {
+				log.Printf("jwt: reload keys: %v", err)
+			}
+
+			rotated, err := j.RotateKeys()
+			if err != nil {
+				log.Printf("jwt: rotate key: %v", err)
+				continue
+			}
+			if rotated {
+				log.Printf("jwt: rotated signing key, active kid %s", j.keys.active().id)
+			}
+		}
+	}
+}
+
+// RotateKeys adds a signing key when the active one is older than
+// JWT_KEY_ROTATION_INTERVAL. Replicas sharing JWT_KEYS_DIR take turns through
+// a lock file in it, and the others pick the new key up on their next reload.
+func (j *jwtService) RotateKeys() (bool, error) {
+	if j.keys.method == jwt.SigningMethodHS256 || j.rotationInterval <= 0 {
+		return false, nil
+	}
+	return j.keys.rotateIfOlder(j.rotationInterval)
+}
+
+// ReloadKeys picks up keys added to or removed from JWT_KEYS_DIR. The current
+// keys stay in use when the directory holds no usable key.
+func (j *jwtService) ReloadKeys() error {
+	return j.keys.reload()
+}
+
+// Shutdown stops the key rotation loop. It is called by the injector.
+func (j *jwtService) Shutdown() error {
+	close(j.stop)
+	return nil
+}
+
+func (j *jwtService) GenerateAccessToken(subject AccessTokenSubject) string {
+	expiry := j.accessExpiry
+	if subject.ExpiresIn > 0 && subject.ExpiresIn < expiry {
+		expiry = subject.ExpiresIn
+	}
+
+	claims := JWTCustomClaim{
+		UserID:      subject.UserID,
+		Role:        subject.Role,
+		Permissions: subject.Permissions,
+		SessionID:   subject.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			Issuer:    j.issuer,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if subject.ActorID != "" {
+		claims.Actor = &ActorClaim{Subject: subject.ActorID}
+	}
+	if subject.OrganizationID != "" {
+		claims.Organization = &OrganizationClaim{ID: subject.OrganizationID, Role: subject.OrganizationRole}
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tx, err := token.SignedString([]byte(j.secretKey))
+	key := j.keys.active()
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.id != "" {
+		token.Header["kid"] = key.id
+	}
+
+	tx, err := token.SignedString(key.private)
 	if err != nil {
 		log.Println(err)
 	}
@@ -68,24 +253,28 @@ func (j *jwtService) GenerateAccessToken(userId string, role string) string {
 }
 
 func (j *jwtService) GenerateRefreshToken() (string, time.Time) {
-	b := make([]byte, 32)
-	_, err := rand.Read(b)
+	refreshToken, err := helpers.GenerateRandomToken(32)
 	if err != nil {
 		log.Println(err)
 		return "", time.Time{}
 	}
 
-	refreshToken := base64.StdEncoding.EncodeToString(b)
 	expiresAt := time.Now().Add(j.refreshExpiry)
 
 	return refreshToken, expiresAt
 }
 
 func (j *jwtService) parseToken(t_ *jwt.Token) (any, error) {
-	if _, ok := t_.Method.(*jwt.SigningMethodHMAC); !ok {
+	kid, _ := t_.Header["kid"].(string)
+	key, ok := j.keys.find(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if t_.Method.Alg() != key.method.Alg() {
 		return nil, fmt.Errorf("unexpected signing method %v", t_.Header["alg"])
 	}
-	return []byte(j.secretKey), nil
+	return key.public, nil
 }
 
 func (j *jwtService) ValidateToken(token string) (*jwt.Token, error) {
@@ -102,3 +291,27 @@ func (j *jwtService) GetUserIDByToken(token string) (string, error) {
 	id := fmt.Sprintf("%v", claims["user_id"])
 	return id, nil
 }
+
+func (j *jwtService) GetClaimsByToken(token string) (*JWTCustomClaim, error) {
+	claims := &JWTCustomClaim{}
+	tToken, err := jwt.ParseWithClaims(token, claims, j.parseToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tToken.Valid {
+		return nil, fmt.Errorf("token is not valid")
+	}
+
+	return claims, nil
+}
+
+// HashRefreshToken returns the keyed hash under which a refresh token is stored.
+func (j *jwtService) HashRefreshToken(token string) string {
+	return helpers.HMACToken(token, j.refreshSecret)
+}
+
+// GetJWKS returns the public keys that currently verify access tokens.
+func (j *jwtService) GetJWKS() dto.JWKSet {
+	return j.keys.jwks()
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxLoginDelay = time.Second * 30
+
+// LoginThrottleService slows down password guessing. Failed logins are
+// tracked per email and per client IP: every failure on an email adds a
+// growing delay, the email is locked once LOGIN_MAX_FAILED_ATTEMPTS is reached
+// and a client IP is blocked after LOGIN_IP_MAX_FAILED_ATTEMPTS. Locks expire
+// after LOGIN_LOCKOUT_DURATION. Unknown emails are throttled the same way so
+// responses do not reveal which accounts exist.
+type LoginThrottleService interface {
+	Check(ctx context.Context, email string, ipAddress string) error
+	RecordFailure(ctx context.Context, email string, user *entities.User, client dto.ClientInfo) error
+	RecordSuccess(ctx context.Context, email string) error
+	Unlock(ctx context.Context, tx *gorm.DB, user entities.User, client dto.ClientInfo) error
+}
+
+type loginThrottleService struct {
+	loginAttemptRepository  authRepo.LoginAttemptRepository
+	oneTimeTokenService     OneTimeTokenService
+	securityEventRepository authRepo.SecurityEventRepository
+	maxAttempts             int64
+	maxIPAttempts           int64
+	lockoutDuration         time.Duration
+	db                      *gorm.DB
+}
+
+func NewLoginThrottleService(
+	loginAttemptRepo authRepo.LoginAttemptRepository,
+	oneTimeTokenService OneTimeTokenService,
+	securityEventRepo authRepo.SecurityEventRepository,
+	db *gorm.DB,
+) LoginThrottleService {
+	return &loginThrottleService{
+		loginAttemptRepository:  loginAttemptRepo,
+		oneTimeTokenService:     oneTimeTokenService,
+		securityEventRepository: securityEventRepo,
+		maxAttempts:             helpers.GetIntEnv("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+		maxIPAttempts:           helpers.GetIntEnv("LOGIN_IP_MAX_FAILED_ATTEMPTS", 50),
+		lockoutDuration:         helpers.GetDurationEnv("LOGIN_LOCKOUT_DURATION", time.Minute*15),
+		db:                      db,
+	}
+}
+
+// ProgressiveLoginDelay is how long a client must wait after the latest of
+// failures consecutive failed logins: nothing after the first, then doubling
+// from one second up to 30 seconds.
+func ProgressiveLoginDelay(failures int64) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+	return min(time.Second<<min(failures-2, 5), maxLoginDelay)
+}
+
+// Check returns a *dto.LoginThrottledError when a login for the email from
+// the IP address must not be attempted yet.
+func (s *loginThrottleService) Check(ctx context.Context, email string, ipAddress string) error {
+	now := time.Now()
+	since := now.Add(-s.lockoutDuration)
+
+	ipSummary, err := s.loginAttemptRepository.SummarizeByIPAddress(ctx, s.db, ipAddress, since)
+	if err != nil {
+		return err
+	}
+
+	if ipSummary.Count >= s.maxIPAttempts {
+		return throttled(ipSummary.LastAttemptAt.Add(s.lockoutDuration).Sub(now))
+	}
+
+	emailSummary, err := s.loginAttemptRepository.SummarizeByEmail(ctx, s.db, normalizeEmail(email), since)
+	if err != nil {
+		return err
+	}
+
+	if emailSummary.Count == 0 {
+		return nil
+	}
+
+	if emailSummary.Count >= s.maxAttempts {
+		return throttled(emailSummary.LastAttemptAt.Add(s.lockoutDuration).Sub(now))
+	}
+
+	if wait := emailSummary.LastAttemptAt.Add(ProgressiveLoginDelay(emailSummary.Count)).Sub(now); wait > 0 {
+		return throttled(wait)
+	}
+
+	return nil
+}
+
+func throttled(retryAfter time.Duration) error {
+	return &dto.LoginThrottledError{RetryAfter: max(retryAfter, time.Second)}
+}
+
+// RecordFailure stores a failed login. When it locks an existing account the
+// owner is emailed a link to unlock it early.
+func (s *loginThrottleService) RecordFailure(
+	ctx context.Context,
+	email string,
+	user *entities.User,
+	client dto.ClientInfo,
+) error {
+	email = normalizeEmail(email)
+	attempt := entities.LoginAttempt{
+		ID:        uuid.New(),
+		Email:     truncate(email, 255),
+		IPAddress: client.IPAddress,
+	}
+
+	if _, err := s.loginAttemptRepository.Create(ctx, s.db, attempt); err != nil {
+		return err
+	}
+
+	if user == nil {
+		return nil
+	}
+
+	summary, err := s.loginAttemptRepository.SummarizeByEmail(ctx, s.db, email, time.Now().Add(-s.lockoutDuration))
+	if err != nil {
+		return err
+	}
+
+	if summary.Count != s.maxAttempts {
+		return nil
+	}
+
+	event := entities.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    &user.ID,
+		Type:      constants.SECURITY_EVENT_ACCOUNT_LOCKED,
+		IPAddress: client.IPAddress,
+		UserAgent: truncate(client.UserAgent, 255),
+		Details:   "locked after " + strconv.FormatInt(summary.Count, 10) + " failed logins",
+	}
+
+	if _, err := s.securityEventRepository.Create(ctx, s.db, event); err != nil {
+		return err
+	}
+
+	unlockToken, err := s.oneTimeTokenService.Issue(ctx, s.db, user.ID, constants.TOKEN_PURPOSE_ACCOUNT_UNLOCK)
+	if err != nil {
+		return err
+	}
+
+	subject := "Account Locked"
+	body := "Your account was locked after too many failed login attempts. " +
+		"It unlocks automatically in " + s.lockoutDuration.String() +
+		", or you can unlock it now using this token: " + unlockToken
+
+	// A failed mail is only logged: an error here would only ever reach
+	// logins to existing accounts and tell them apart from unknown emails.
+	if err := utils.SendMail(user.Email, subject, body); err != nil {
+		log.Printf("auth: send account locked email to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// RecordSuccess forgets the failures of the email once the right password
+// is given. Failures counted against the client IP stay.
+func (s *loginThrottleService) RecordSuccess(ctx context.Context, email string) error {
+	return s.loginAttemptRepository.DeleteByEmail(ctx, s.db, normalizeEmail(email))
+}
+
+func (s *loginThrottleService) Unlock(ctx context.Context, tx *gorm.DB, user entities.User, client dto.ClientInfo) error {
+	if err := s.loginAttemptRepository.DeleteByEmail(ctx, tx, normalizeEmail(user.Email)); err != nil {
+		return err
+	}
+
+	if err := s.oneTimeTokenService.Invalidate(ctx, tx, user.ID.String(), constants.TOKEN_PURPOSE_ACCOUNT_UNLOCK); err != nil {
+		return err
+	}
+
+	event := entities.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    &user.ID,
+		Type:      constants.SECURITY_EVENT_ACCOUNT_UNLOCKED,
+		IPAddress: client.IPAddress,
+		UserAgent: truncate(client.UserAgent, 255),
+	}
+
+	_, err := s.securityEventRepository.Create(ctx, tx, event)
+	return err
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,435 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	recoveryCodeCount = 10
+	// totpSkew accepts codes from one step before and after the current one
+	// to tolerate clock drift on the user's device.
+	totpSkew = 1
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAService manages TOTP two-factor authentication and its recovery codes.
+type MFAService interface {
+	GetStatus(ctx context.Context, userId string) (dto.MFAStatusResponse, error)
+	Enroll(ctx context.Context, userId string) (dto.MFAEnrollResponse, error)
+	Confirm(ctx context.Context, userId string, req dto.MFACodeRequest, client dto.ClientInfo) (dto.MFARecoveryCodesResponse, error)
+	Disable(ctx context.Context, userId string, req dto.MFADisableRequest, client dto.ClientInfo) error
+	RegenerateRecoveryCodes(ctx context.Context, userId string, req dto.MFACodeRequest) (dto.MFARecoveryCodesResponse, error)
+	IsEnabled(ctx context.Context, tx *gorm.DB, userId string) (bool, error)
+	VerifyCode(ctx context.Context, tx *gorm.DB, userId string, code string, client dto.ClientInfo) error
+	RecordFailure(ctx context.Context, userId string) error
+}
+
+type mfaService struct {
+	mfaRepository           authRepo.MFARepository
+	userRepository          repository.UserRepository
+	securityEventRepository authRepo.SecurityEventRepository
+	encryptionKey           []byte
+	issuer                  string
+	maxAttempts             int64
+	lockoutDuration         time.Duration
+	db                      *gorm.DB
+}
+
+// NewMFAService encrypts TOTP secrets with MFA_ENCRYPTION_KEY, a hex encoded
+// 32 byte AES key. Outside production a fixed development key is used when it
+// is not set.
+// After MFA_MAX_FAILED_ATTEMPTS wrong codes no code is accepted until
+// MFA_LOCKOUT_DURATION has passed since the last one.
+func NewMFAService(
+	mfaRepo authRepo.MFARepository,
+	userRepo repository.UserRepository,
+	securityEventRepo authRepo.SecurityEventRepository,
+	db *gorm.DB,
+) (MFAService, error) {
+	encryptionKey, err := getMFAEncryptionKey(os.Getenv("APP_ENV") == constants.ENUM_RUN_PRODUCTION)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := os.Getenv("APP_NAME")
+	if issuer == "" {
+		issuer = "Template"
+	}
+
+	return &mfaService{
+		mfaRepository:           mfaRepo,
+		userRepository:          userRepo,
+		securityEventRepository: securityEventRepo,
+		encryptionKey:           encryptionKey,
+		issuer:                  issuer,
+		maxAttempts:             helpers.GetIntEnv("MFA_MAX_FAILED_ATTEMPTS", 5),
+		lockoutDuration:         helpers.GetDurationEnv("MFA_LOCKOUT_DURATION", time.Minute*15),
+		db:                      db,
+	}, nil
+}
+
+func getMFAEncryptionKey(production bool) ([]byte, error) {
+	value := os.Getenv("MFA_ENCRYPTION_KEY")
+	if value == "" {
+		if production {
+			return nil, dto.ErrMFAEncryptionKey
+		}
+		key := sha256.Sum256([]byte("Template"))
+		return key[:], nil
+	}
+
+	key, err := hex.DecodeString(value)
+	if err != nil || len(key) != 32 {
+		return nil, dto.ErrMFAEncryptionKey
+	}
+	return key, nil
+}
+
+func (s *mfaService) GetStatus(ctx context.Context, userId string) (dto.MFAStatusResponse, error) {
+	enabled, err := s.IsEnabled(ctx, s.db, userId)
+	if err != nil || !enabled {
+		return dto.MFAStatusResponse{}, err
+	}
+
+	remaining, err := s.mfaRepository.CountUnusedRecoveryCodes(ctx, s.db, userId)
+	if err != nil {
+		return dto.MFAStatusResponse{}, err
+	}
+
+	return dto.MFAStatusResponse{
+		Enabled:                true,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// Enroll generates a new secret for the user. It is not enforced until the
+// user proves their authenticator works through Confirm; enrolling again
+// before that replaces the pending secret.
+func (s *mfaService) Enroll(ctx context.Context, userId string) (dto.MFAEnrollResponse, error) {
+	user, err := s.userRepository.GetUserById(ctx, s.db, userId)
+	if err != nil {
+		return dto.MFAEnrollResponse{}, userDto.ErrUserNotFound
+	}
+
+	mfa, err := s.mfaRepository.FindByUserID(ctx, s.db, userId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.MFAEnrollResponse{}, err
+	}
+
+	if mfa.ConfirmedAt != nil {
+		return dto.MFAEnrollResponse{}, dto.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return dto.MFAEnrollResponse{}, err
+	}
+
+	encryptedSecret, err := helpers.EncryptAESGCM(secret, s.encryptionKey)
+	if err != nil {
+		return dto.MFAEnrollResponse{}, err
+	}
+
+	if mfa.ID == uuid.Nil {
+		mfa = entities.UserMFA{
+			ID:     uuid.New(),
+			UserID: user.ID,
+		}
+	}
+	mfa.SecretEncrypted = encryptedSecret
+	mfa.LastUsedStep = 0
+
+	if _, err := s.mfaRepository.Save(ctx, s.db, mfa); err != nil {
+		return dto.MFAEnrollResponse{}, err
+	}
+
+	return dto.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: helpers.TOTPURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the user submits a valid code
+// for the pending secret, and returns the recovery codes. They are only shown
+// this once.
+func (s *mfaService) Confirm(
+	ctx context.Context,
+	userId string,
+	req dto.MFACodeRequest,
+	client dto.ClientInfo,
+) (dto.MFARecoveryCodesResponse, error) {
+	var recoveryCodes []string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		mfa, err := s.mfaRepository.FindByUserID(ctx, tx, userId)
+		if err != nil {
+			return dto.ErrMFANotEnrolled
+		}
+
+		if mfa.ConfirmedAt != nil {
+			return dto.ErrMFAAlreadyEnabled
+		}
+
+		if err := s.verifyTOTP(ctx, tx, mfa, req.Code); err != nil {
+			return err
+		}
+
+		if err := s.mfaRepository.MarkConfirmed(ctx, tx, mfa.ID.String()); err != nil {
+			return err
+		}
+
+		recoveryCodes, err = s.replaceRecoveryCodes(ctx, tx, mfa.UserID)
+		if err != nil {
+			return err
+		}
+
+		return s.recordEvent(ctx, tx, mfa.UserID, constants.SECURITY_EVENT_MFA_ENABLED, client)
+	})
+	if err != nil {
+		return dto.MFARecoveryCodesResponse{}, s.failed(ctx, userId, err)
+	}
+
+	return dto.MFARecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// Disable requires both the password and a current code (or recovery code) so
+// a stolen access token alone cannot switch two-factor authentication off.
+func (s *mfaService) Disable(ctx context.Context, userId string, req dto.MFADisableRequest, client dto.ClientInfo) error {
+	user, err := s.userRepository.GetUserById(ctx, s.db, userId)
+	if err != nil {
+		return userDto.ErrUserNotFound
+	}
+
+	isValid, err := helpers.CheckPassword(user.Password, []byte(req.Password))
+	if err != nil || !isValid {
+		return dto.ErrInvalidCredentials
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.VerifyCode(ctx, tx, userId, req.Code, client); err != nil {
+			return err
+		}
+
+		if err := s.mfaRepository.DeleteByUserID(ctx, tx, userId); err != nil {
+			return err
+		}
+
+		return s.recordEvent(ctx, tx, user.ID, constants.SECURITY_EVENT_MFA_DISABLED, client)
+	})
+
+	return s.failed(ctx, userId, err)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(
+	ctx context.Context,
+	userId string,
+	req dto.MFACodeRequest,
+) (dto.MFARecoveryCodesResponse, error) {
+	var recoveryCodes []string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		mfa, err := s.findEnabled(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.verifyTOTP(ctx, tx, mfa, req.Code); err != nil {
+			return err
+		}
+
+		recoveryCodes, err = s.replaceRecoveryCodes(ctx, tx, mfa.UserID)
+		return err
+	})
+	if err != nil {
+		return dto.MFARecoveryCodesResponse{}, s.failed(ctx, userId, err)
+	}
+
+	return dto.MFARecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *mfaService) IsEnabled(ctx context.Context, tx *gorm.DB, userId string) (bool, error) {
+	mfa, err := s.mfaRepository.FindByUserID(ctx, tx, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return mfa.ConfirmedAt != nil, nil
+}
+
+// VerifyCode accepts either a TOTP code or an unused recovery code. Recovery
+// codes are burned on use and leave a security event behind.
+func (s *mfaService) VerifyCode(ctx context.Context, tx *gorm.DB, userId string, code string, client dto.ClientInfo) error {
+	mfa, err := s.findEnabled(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == 6 {
+		return s.verifyTOTP(ctx, tx, mfa, code)
+	}
+
+	if err := s.checkAttempts(mfa); err != nil {
+		return err
+	}
+
+	consumed, err := s.mfaRepository.ConsumeRecoveryCode(ctx, tx, userId, helpers.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+
+	if !consumed {
+		return dto.ErrMFACodeInvalid
+	}
+
+	if err := s.mfaRepository.ResetFailedAttempts(ctx, tx, mfa.ID.String()); err != nil {
+		return err
+	}
+
+	return s.recordEvent(ctx, tx, mfa.UserID, constants.SECURITY_EVENT_MFA_RECOVERY_USED, client)
+}
+
+// RecordFailure counts a wrong code against the user. Callers verify codes
+// inside a transaction that a wrong code rolls back, so it is recorded
+// afterwards on its own.
+func (s *mfaService) RecordFailure(ctx context.Context, userId string) error {
+	now := time.Now()
+	return s.mfaRepository.RecordFailedAttempt(ctx, s.db, userId, now, now.Add(-s.lockoutDuration))
+}
+
+// failed records err when it is a wrong code and returns it.
+func (s *mfaService) failed(ctx context.Context, userId string, err error) error {
+	if errors.Is(err, dto.ErrMFACodeInvalid) {
+		if recordErr := s.RecordFailure(ctx, userId); recordErr != nil {
+			return recordErr
+		}
+	}
+	return err
+}
+
+// checkAttempts refuses every code, right or wrong, once too many wrong ones
+// were entered recently. The count spans all challenges, so logging in again
+// does not buy more guesses.
+func (s *mfaService) checkAttempts(mfa entities.UserMFA) error {
+	if int64(mfa.FailedAttempts) >= s.maxAttempts && mfa.LastFailedAt != nil &&
+		time.Since(*mfa.LastFailedAt) < s.lockoutDuration {
+		return dto.ErrMFATooManyAttempts
+	}
+	return nil
+}
+
+func (s *mfaService) findEnabled(ctx context.Context, tx *gorm.DB, userId string) (entities.UserMFA, error) {
+	mfa, err := s.mfaRepository.FindByUserID(ctx, tx, userId)
+	if err != nil || mfa.ConfirmedAt == nil {
+		return entities.UserMFA{}, dto.ErrMFANotEnabled
+	}
+	return mfa, nil
+}
+
+func (s *mfaService) verifyTOTP(ctx context.Context, tx *gorm.DB, mfa entities.UserMFA, code string) error {
+	if err := s.checkAttempts(mfa); err != nil {
+		return err
+	}
+
+	secret, err := helpers.DecryptAESGCM(mfa.SecretEncrypted, s.encryptionKey)
+	if err != nil {
+		return err
+	}
+
+	step, ok := helpers.ValidateTOTP(secret, strings.TrimSpace(code), time.Now(), totpSkew)
+	if !ok {
+		return dto.ErrMFACodeInvalid
+	}
+
+	fresh, err := s.mfaRepository.MarkStepUsed(ctx, tx, mfa.ID.String(), step)
+	if err != nil {
+		return err
+	}
+
+	// The code was valid but has already been used once.
+	if !fresh {
+		return dto.ErrMFACodeInvalid
+	}
+
+	return s.mfaRepository.ResetFailedAttempts(ctx, tx, mfa.ID.String())
+}
+
+func (s *mfaService) replaceRecoveryCodes(ctx context.Context, tx *gorm.DB, userId uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]entities.MFARecoveryCode, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		records = append(records, entities.MFARecoveryCode{
+			ID:       uuid.New(),
+			UserID:   userId,
+			CodeHash: helpers.HashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := s.mfaRepository.ReplaceRecoveryCodes(ctx, tx, userId.String(), records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *mfaService) recordEvent(
+	ctx context.Context,
+	tx *gorm.DB,
+	userId uuid.UUID,
+	eventType string,
+	client dto.ClientInfo,
+) error {
+	event := entities.SecurityEvent{
+		ID:        uuid.New(),
+		UserID:    &userId,
+		Type:      eventType,
+		IPAddress: client.IPAddress,
+		UserAgent: truncate(client.UserAgent, 255),
+	}
+
+	_, err := s.securityEventRepository.Create(ctx, tx, event)
+	return err
+}
+
+// generateRecoveryCode returns 50 random bits formatted as xxxxx-xxxxx.
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
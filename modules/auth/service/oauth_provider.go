@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// OAuthIdentity is the external account a provider vouches for once an
+// authorization code was exchanged.
+type OAuthIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OAuthProvider is an external identity provider using the authorization code
+// flow with PKCE. Providers that support it also bind the nonce to the ID
+// token they return.
+type OAuthProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state string, codeVerifier string, nonce string) (string, error)
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (OAuthIdentity, error)
+}
+
+type OAuthProviderConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// IssuerURL is where OpenID Connect providers publish their discovery
+	// document; it is unused for plain OAuth2 providers.
+	IssuerURL string
+}
+
+// NewOAuthProviders builds the providers configured through the environment:
+// Google (OAUTH_GOOGLE_*), GitHub (OAUTH_GITHUB_*) and any OpenID Connect
+// issuer (OAUTH_OIDC_*). A provider is enabled once its client id is set.
+// Callbacks are expected at OAUTH_REDIRECT_BASE_URL/api/auth/oauth/:provider/callback.
+func NewOAuthProviders() map[string]OAuthProvider {
+	baseURL := strings.TrimSuffix(os.Getenv("OAUTH_REDIRECT_BASE_URL"), "/")
+	if baseURL == "" {
+		baseURL = "http://localhost:8888"
+	}
+
+	config := func(name string, prefix string) OAuthProviderConfig {
+		return OAuthProviderConfig{
+			Name:         name,
+			ClientID:     os.Getenv(prefix + "_CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "_CLIENT_SECRET"),
+			RedirectURL:  baseURL + "/api/auth/oauth/" + name + "/callback",
+		}
+	}
+
+	providers := make(map[string]OAuthProvider)
+
+	if googleConfig := config("google", "OAUTH_GOOGLE"); googleConfig.ClientID != "" {
+		googleConfig.IssuerURL = "https://accounts.google.com"
+		providers[googleConfig.Name] = NewOIDCProvider(googleConfig)
+	}
+
+	if githubConfig := config("github", "OAUTH_GITHUB"); githubConfig.ClientID != "" {
+		providers[githubConfig.Name] = NewGitHubProvider(githubConfig)
+	}
+
+	name := os.Getenv("OAUTH_OIDC_NAME")
+	if name == "" {
+		name = "oidc"
+	}
+	if oidcConfig := config(name, "OAUTH_OIDC"); oidcConfig.ClientID != "" {
+		oidcConfig.IssuerURL = os.Getenv("OAUTH_OIDC_ISSUER")
+		providers[oidcConfig.Name] = NewOIDCProvider(oidcConfig)
+	}
+
+	return providers
+}
+
+type oidcProvider struct {
+	config   OAuthProviderConfig
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// NewOIDCProvider signs users in with any OpenID Connect issuer. The
+// discovery document is fetched on first use, so an unreachable issuer does
+// not keep the application from starting.
+func NewOIDCProvider(config OAuthProviderConfig) OAuthProvider {
+	return &oidcProvider{
+		config: config,
+	}
+}
+
+func (p *oidcProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (*oidc.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider == nil {
+		provider, err := oidc.NewProvider(ctx, p.config.IssuerURL)
+		if err != nil {
+			return nil, fmt.Errorf("oidc discovery for %s: %w", p.config.Name, err)
+		}
+		p.provider = provider
+	}
+
+	return p.provider, nil
+}
+
+func (p *oidcProvider) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+	}
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state string, codeVerifier string, nonce string) (string, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return p.oauth2Config(provider).AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier), oidc.Nonce(nonce)), nil
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (OAuthIdentity, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return OAuthIdentity{}, err
+	}
+
+	token, err := p.oauth2Config(provider).Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return OAuthIdentity{}, fmt.Errorf("%w: %v", dto.ErrOAuthExchange, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return OAuthIdentity{}, fmt.Errorf("%w: no id_token in response", dto.ErrOAuthExchange)
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return OAuthIdentity{}, fmt.Errorf("%w: %v", dto.ErrOAuthExchange, err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		return OAuthIdentity{}, dto.ErrOAuthNonceMismatch
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return OAuthIdentity{}, fmt.Errorf("%w: %v", dto.ErrOAuthExchange, err)
+	}
+
+	return OAuthIdentity{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+type githubProvider struct {
+	config OAuthProviderConfig
+	oauth2 *oauth2.Config
+	apiURL string
+}
+
+// NewGitHubProvider signs users in with GitHub, which speaks plain OAuth2: the
+// profile and verified email addresses are read from the REST API.
+func NewGitHubProvider(config OAuthProviderConfig) OAuthProvider {
+	return &githubProvider{
+		config: config,
+		oauth2: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     github.Endpoint,
+			Scopes:       []string{"read:user", "user:email"},
+		},
+		apiURL: "https://api.github.com",
+	}
+}
+
+func (p *githubProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *githubProvider) AuthCodeURL(_ context.Context, state string, codeVerifier string, _ string) (string, error) {
+	return p.oauth2.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+func (p *githubProvider) Exchange(ctx context.Context, code string, codeVerifier string, _ string) (OAuthIdentity, error) {
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return OAuthIdentity{}, fmt.Errorf("%w: %v", dto.ErrOAuthExchange, err)
+	}
+
+	client := p.oauth2.Client(ctx, token)
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getJSON(ctx, client, p.apiURL+"/user", &user); err != nil {
+		return OAuthIdentity{}, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, p.apiURL+"/user/emails", &emails); err != nil {
+		return OAuthIdentity{}, err
+	}
+
+	identity := OAuthIdentity{
+		Subject: strconv.FormatInt(user.ID, 10),
+		Name:    user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", dto.ErrOAuthExchange, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %s", dto.ErrOAuthExchange, url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	invitationService "github.com/Caknoooo/go-gin-clean-starter/modules/invitation/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// OAuthService signs users in through external OAuth2/OpenID Connect
+// providers, linking each external account to a local user.
+type OAuthService interface {
+	GetProviders() dto.OAuthProvidersResponse
+	Authorize(ctx context.Context, providerName string) (dto.OAuthAuthorizeResponse, error)
+	Callback(ctx context.Context, providerName string, req dto.OAuthCallbackRequest, client dto.ClientInfo) (dto.TokenResponse, error)
+}
+
+type oauthService struct {
+	providers         map[string]OAuthProvider
+	oauthRepository   authRepo.OAuthRepository
+	userRepository    repository.UserRepository
+	authService       AuthService
+	invitationService invitationService.InvitationService
+	stateTTL          time.Duration
+	registrationMode  string
+	db                *gorm.DB
+}
+
+func NewOAuthService(
+	providers map[string]OAuthProvider,
+	oauthRepo authRepo.OAuthRepository,
+	userRepo repository.UserRepository,
+	authService AuthService,
+	invitationService invitationService.InvitationService,
+	db *gorm.DB,
+) OAuthService {
+	return &oauthService{
+		providers:         providers,
+		oauthRepository:   oauthRepo,
+		userRepository:    userRepo,
+		authService:       authService,
+		invitationService: invitationService,
+		stateTTL:          helpers.GetDurationEnv("OAUTH_STATE_TTL", time.Minute*10),
+		registrationMode:  getRegistrationMode(),
+		db:                db,
+	}
+}
+
+func (s *oauthService) GetProviders() dto.OAuthProvidersResponse {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return dto.OAuthProvidersResponse{Providers: names}
+}
+
+// Authorize starts the authorization code flow. The state, PKCE verifier and
+// nonce are kept server side until the provider redirects back. The state is
+// also returned to be stored in the browser, so the callback only succeeds in
+// the browser that started the flow.
+func (s *oauthService) Authorize(ctx context.Context, providerName string) (dto.OAuthAuthorizeResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return dto.OAuthAuthorizeResponse{}, dto.ErrOAuthProviderNotFound
+	}
+
+	state, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return dto.OAuthAuthorizeResponse{}, err
+	}
+
+	nonce, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return dto.OAuthAuthorizeResponse{}, err
+	}
+
+	codeVerifier := oauth2.GenerateVerifier()
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, state, codeVerifier, nonce)
+	if err != nil {
+		return dto.OAuthAuthorizeResponse{}, err
+	}
+
+	oauthState := entities.OAuthState{
+		ID:           uuid.New(),
+		StateHash:    helpers.HashToken(state),
+		Provider:     providerName,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}
+
+	if _, err := s.oauthRepository.CreateState(ctx, s.db, oauthState); err != nil {
+		return dto.OAuthAuthorizeResponse{}, err
+	}
+
+	return dto.OAuthAuthorizeResponse{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+		StateExpiresAt:   oauthState.ExpiresAt,
+	}, nil
+}
+
+// Callback finishes the flow started by Authorize and logs the linked user in.
+// The state must match the one stored in the browser, otherwise someone could
+// send the victim the callback of their own flow and sign them in to the
+// attacker's account.
+func (s *oauthService) Callback(
+	ctx context.Context,
+	providerName string,
+	req dto.OAuthCallbackRequest,
+	client dto.ClientInfo,
+) (dto.TokenResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return dto.TokenResponse{}, dto.ErrOAuthProviderNotFound
+	}
+
+	if req.BrowserState == "" || subtle.ConstantTimeCompare([]byte(req.State), []byte(req.BrowserState)) != 1 {
+		return dto.TokenResponse{}, dto.ErrOAuthStateInvalid
+	}
+
+	oauthState, err := s.oauthRepository.TakeState(ctx, s.db, helpers.HashToken(req.State))
+	if err != nil || oauthState.Provider != providerName || time.Now().After(oauthState.ExpiresAt) {
+		return dto.TokenResponse{}, dto.ErrOAuthStateInvalid
+	}
+
+	if req.Error != "" || req.Code == "" {
+		return dto.TokenResponse{}, dto.ErrOAuthDenied
+	}
+
+	identity, err := provider.Exchange(ctx, req.Code, oauthState.CodeVerifier, oauthState.Nonce)
+	if err != nil {
+		return dto.TokenResponse{}, err
+	}
+
+	user, err := s.resolveUser(ctx, providerName, identity)
+	if err != nil {
+		return dto.TokenResponse{}, err
+	}
+
+	return s.authService.CompleteLogin(ctx, user, client, "")
+}
+
+// resolveUser returns the user linked to the external identity. An unknown
+// identity is linked to the account with the same email, or a new verified
+// account is registered, but only when the provider verified the email. The
+// existing account must be verified as well: otherwise whoever registered it
+// could still sign in with its password after the owner links their login.
+func (s *oauthService) resolveUser(ctx context.Context, providerName string, identity OAuthIdentity) (entities.User, error) {
+	var user entities.User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		linked, err := s.oauthRepository.FindIdentity(ctx, tx, providerName, identity.Subject)
+		if err == nil {
+			user = linked.User
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if identity.Email == "" || !identity.EmailVerified {
+			return dto.ErrOAuthEmailNotVerified
+		}
+
+		user, err = s.userRepository.GetUserByEmail(ctx, tx, identity.Email)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			user, err = s.register(ctx, tx, identity)
+		} else if err == nil && !user.IsVerified {
+			err = dto.ErrOAuthAccountUnverified
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = s.oauthRepository.CreateIdentity(ctx, tx, entities.UserIdentity{
+			ID:       uuid.New(),
+			UserID:   user.ID,
+			Provider: providerName,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		})
+		return err
+	})
+
+	return user, err
+}
+
+// register creates an account for a first-time external login. It gets a
+// random password, which the user can replace through a password reset. The
+// provider verified the email, so a pending invitation to it is accepted
+// without a token; when registration is invite only there must be one.
+func (s *oauthService) register(ctx context.Context, tx *gorm.DB, identity OAuthIdentity) (entities.User, error) {
+	if s.registrationMode == constants.REGISTRATION_MODE_DISABLED {
+		return entities.User{}, dto.ErrRegistrationDisabled
+	}
+
+	invitation, invited, err := s.invitationService.FindForEmail(ctx, tx, identity.Email)
+	if err != nil {
+		return entities.User{}, err
+	}
+	if !invited && s.registrationMode == constants.REGISTRATION_MODE_INVITE_ONLY {
+		return entities.User{}, dto.ErrInvitationRequired
+	}
+
+	role := constants.ENUM_ROLE_USER
+	if invited {
+		role = invitation.Role
+	}
+
+	password, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	hashedPassword, err := helpers.HashPassword(password)
+	if err != nil {
+		return entities.User{}, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	user := entities.User{
+		ID:         uuid.New(),
+		Name:       truncate(name, 100),
+		Email:      identity.Email,
+		Password:   hashedPassword,
+		Role:       role,
+		IsVerified: true,
+	}
+
+	user, err = s.userRepository.Register(ctx, tx, user)
+	if err != nil || !invited {
+		return user, err
+	}
+
+	return user, s.invitationService.Accept(ctx, tx, invitation, user.ID)
+}
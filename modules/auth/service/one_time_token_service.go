@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OneTimeTokenService issues and consumes single-use, purpose-scoped tokens.
+type OneTimeTokenService interface {
+	Issue(ctx context.Context, tx *gorm.DB, userID uuid.UUID, purpose string) (string, error)
+	IssueWithPayload(ctx context.Context, tx *gorm.DB, userID uuid.UUID, purpose string, payload string) (string, error)
+	Consume(ctx context.Context, tx *gorm.DB, token string, purpose string) (entities.OneTimeToken, error)
+	Inspect(ctx context.Context, tx *gorm.DB, token string, purpose string) (entities.OneTimeToken, error)
+	RecordFailedAttempt(ctx context.Context, tx *gorm.DB, token entities.OneTimeToken) error
+	Invalidate(ctx context.Context, tx *gorm.DB, userID string, purposes ...string) error
+	IssuedWithin(ctx context.Context, tx *gorm.DB, userID uuid.UUID, purpose string, window time.Duration) (bool, error)
+	FindOutstanding(ctx context.Context, tx *gorm.DB, userID uuid.UUID, purpose string) (entities.OneTimeToken, bool, error)
+}
+
+// oneTimeTokenMaxAttempts bounds how often a wrong secret can be submitted
+// alongside a token before the token stops being accepted.
+const oneTimeTokenMaxAttempts = 5
+
+type oneTimeTokenService struct {
+	oneTimeTokenRepository authRepo.OneTimeTokenRepository
+	ttl                    map[string]time.Duration
+}
+
+func NewOneTimeTokenService(oneTimeTokenRepo authRepo.OneTimeTokenRepository) OneTimeTokenService {
+	return &oneTimeTokenService{
+		oneTimeTokenRepository: oneTimeTokenRepo,
+		ttl: map[string]time.Duration{
+			constants.TOKEN_PURPOSE_EMAIL_VERIFICATION:  helpers.GetDurationEnv("VERIFICATION_TOKEN_TTL", time.Hour*24),
+			constants.TOKEN_PURPOSE_PASSWORD_RESET:      helpers.GetDurationEnv("PASSWORD_RESET_TOKEN_TTL", time.Hour),
+			constants.TOKEN_PURPOSE_MFA_CHALLENGE:       helpers.GetDurationEnv("MFA_CHALLENGE_TTL", time.Minute*5),
+			constants.TOKEN_PURPOSE_ACCOUNT_UNLOCK:      helpers.GetDurationEnv("ACCOUNT_UNLOCK_TOKEN_TTL", time.Hour*24),
+			constants.TOKEN_PURPOSE_MAGIC_LINK:          helpers.GetDurationEnv("MAGIC_LINK_TTL", time.Minute*15),
+			constants.TOKEN_PURPOSE_EMAIL_CHANGE:        helpers.GetDurationEnv("EMAIL_CHANGE_TOKEN_TTL", time.Hour*24),
+			constants.TOKEN_PURPOSE_EMAIL_CHANGE_CANCEL: helpers.GetDurationEnv("EMAIL_CHANGE_CANCEL_TTL", time.Hour*24*7),
+		},
+	}
+}
+
+// Issue creates a new token for the purpose and invalidates any token of the
+// same purpose that is still outstanding for the user.
+func (s *oneTimeTokenService) Issue(ctx context.Context, tx *gorm.DB, userID uuid.UUID, purpose string) (string, error) {
+	return s.IssueWithPayload(ctx, tx, userID, purpose, "")
+}
+
+// IssueWithPayload is Issue for tokens that carry a value, returned with the
+// token when it is consumed.
+func (s *oneTimeTokenService) IssueWithPayload(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	purpose string,
+	payload string,
+) (string, error) {
+	ttl, ok := s.ttl[purpose]
+	if !ok {
+		return "", dto.ErrOneTimeTokenPurpose
+	}
+
+	rawToken, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.oneTimeTokenRepository.DeleteByUserIDAndPurpose(ctx, tx, userID.String(), purpose); err != nil {
+		return "", err
+	}
+
+	token := entities.OneTimeToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: helpers.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+		Payload:   payload,
+	}
+
+	if _, err := s.oneTimeTokenRepository.Create(ctx, tx, token); err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// Consume validates the token for the purpose and marks it as used so it can
+// never be presented again.
+func (s *oneTimeTokenService) Consume(
+	ctx context.Context,
+	tx *gorm.DB,
+	token string,
+	purpose string,
+) (entities.OneTimeToken, error) {
+	oneTimeToken, err := s.Inspect(ctx, tx, token, purpose)
+	if err != nil {
+		return entities.OneTimeToken{}, err
+	}
+
+	consumed, err := s.oneTimeTokenRepository.MarkConsumed(ctx, tx, oneTimeToken.ID.String())
+	if err != nil {
+		return entities.OneTimeToken{}, err
+	}
+
+	if !consumed {
+		return entities.OneTimeToken{}, dto.ErrOneTimeTokenInvalid
+	}
+
+	return oneTimeToken, nil
+}
+
+// Inspect validates the token for the purpose without using it up. Flows that
+// pair the token with a second secret call it first and only Consume once the
+// secret checks out.
+func (s *oneTimeTokenService) Inspect(
+	ctx context.Context,
+	tx *gorm.DB,
+	token string,
+	purpose string,
+) (entities.OneTimeToken, error) {
+	oneTimeToken, err := s.oneTimeTokenRepository.FindByTokenHash(ctx, tx, helpers.HashToken(token), purpose)
+	if err != nil {
+		return entities.OneTimeToken{}, dto.ErrOneTimeTokenInvalid
+	}
+
+	if oneTimeToken.ConsumedAt != nil || oneTimeToken.Attempts >= oneTimeTokenMaxAttempts {
+		return entities.OneTimeToken{}, dto.ErrOneTimeTokenInvalid
+	}
+
+	if time.Now().After(oneTimeToken.ExpiresAt) {
+		return entities.OneTimeToken{}, dto.ErrOneTimeTokenExpired
+	}
+
+	return oneTimeToken, nil
+}
+
+// RecordFailedAttempt counts a wrong secret submitted with the token.
+func (s *oneTimeTokenService) RecordFailedAttempt(ctx context.Context, tx *gorm.DB, token entities.OneTimeToken) error {
+	return s.oneTimeTokenRepository.IncrementAttempts(ctx, tx, token.ID.String())
+}
+
+func (s *oneTimeTokenService) Invalidate(ctx context.Context, tx *gorm.DB, userID string, purposes ...string) error {
+	return s.oneTimeTokenRepository.DeleteByUserIDAndPurpose(ctx, tx, userID, purposes...)
+}
+
+// IssuedWithin reports whether a token of the purpose was issued for the user
+// during the last window, so callers can rate limit what they send out.
+func (s *oneTimeTokenService) IssuedWithin(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	purpose string,
+	window time.Duration,
+) (bool, error) {
+	token, err := s.oneTimeTokenRepository.FindLatestByUserIDAndPurpose(ctx, tx, userID.String(), purpose)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return time.Since(token.CreatedAt) < window, nil
+}
+
+// FindOutstanding returns the user's latest token of the purpose when it can
+// still be used.
+func (s *oneTimeTokenService) FindOutstanding(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID uuid.UUID,
+	purpose string,
+) (entities.OneTimeToken, bool, error) {
+	token, err := s.oneTimeTokenRepository.FindLatestByUserIDAndPurpose(ctx, tx, userID.String(), purpose)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.OneTimeToken{}, false, nil
+	}
+	if err != nil {
+		return entities.OneTimeToken{}, false, err
+	}
+
+	if token.ConsumedAt != nil || token.Attempts >= oneTimeTokenMaxAttempts || time.Now().After(token.ExpiresAt) {
+		return entities.OneTimeToken{}, false, nil
+	}
+
+	return token, true, nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+)
+
+//go:embed data/common_passwords.txt
+var commonPasswordList string
+
+// PasswordPolicy holds the rules every new password must satisfy.
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// HistorySize is how many previous passwords, the current one included,
+	// may not be reused. Zero disables the check.
+	HistorySize int
+}
+
+// NewPasswordPolicy reads the policy from the PASSWORD_* environment variables.
+func NewPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:     int(helpers.GetIntEnv("PASSWORD_MIN_LENGTH", 8)),
+		MaxLength:     int(helpers.GetIntEnv("PASSWORD_MAX_LENGTH", 64)),
+		RequireUpper:  getBoolEnv("PASSWORD_REQUIRE_UPPER"),
+		RequireLower:  getBoolEnv("PASSWORD_REQUIRE_LOWER"),
+		RequireDigit:  getBoolEnv("PASSWORD_REQUIRE_DIGIT"),
+		RequireSymbol: getBoolEnv("PASSWORD_REQUIRE_SYMBOL"),
+		HistorySize:   int(helpers.GetNonNegativeIntEnv("PASSWORD_HISTORY_SIZE", 5)),
+	}
+}
+
+func getBoolEnv(key string) bool {
+	value, _ := strconv.ParseBool(os.Getenv(key))
+	return value
+}
+
+// CheckRules applies the length and character class rules, and rejects
+// passwords containing any of the user's personal values (name parts, email).
+// All violations are joined into the returned error.
+func (p PasswordPolicy) CheckRules(password string, personal ...string) error {
+	var violations []error
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, dto.ErrPasswordTooShort)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, dto.ErrPasswordTooLong)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsLetter(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, dto.ErrPasswordMissingUpper)
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, dto.ErrPasswordMissingLower)
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, dto.ErrPasswordMissingDigit)
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, dto.ErrPasswordMissingSymbol)
+	}
+
+	if containsPersonalValue(password, personal) {
+		violations = append(violations, dto.ErrPasswordContainsProfile)
+	}
+
+	return errors.Join(violations...)
+}
+
+// containsPersonalValue looks for each name part and the email's local part
+// in the password, ignoring case. Parts shorter than three letters are
+// skipped to avoid rejecting passwords over initials.
+func containsPersonalValue(password string, personal []string) bool {
+	password = strings.ToLower(password)
+
+	for _, value := range personal {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if local, _, found := strings.Cut(value, "@"); found {
+			value = local
+		}
+
+		parts := strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(parts) > 1 {
+			parts = append(parts, value)
+		}
+
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= 3 && strings.Contains(password, part) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// BreachedPasswordChecker reports whether a password is publicly known.
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+type commonPasswordChecker struct {
+	passwords map[string]struct{}
+	next      BreachedPasswordChecker
+}
+
+// NewBreachedPasswordChecker rejects a built-in list of the most common
+// passwords and, when PASSWORD_BREACHED_DIR is set, looks passwords up in a
+// local copy of a breach corpus split by hash prefix.
+func NewBreachedPasswordChecker() BreachedPasswordChecker {
+	checker := &commonPasswordChecker{passwords: make(map[string]struct{})}
+	for _, password := range strings.Fields(commonPasswordList) {
+		checker.passwords[password] = struct{}{}
+	}
+
+	if dir := os.Getenv("PASSWORD_BREACHED_DIR"); dir != "" {
+		checker.next = NewHashPrefixChecker(dir)
+	}
+
+	return checker
+}
+
+func (c *commonPasswordChecker) IsBreached(password string) (bool, error) {
+	if _, ok := c.passwords[strings.ToLower(password)]; ok {
+		return true, nil
+	}
+
+	if c.next == nil {
+		return false, nil
+	}
+
+	return c.next.IsBreached(password)
+}
+
+type hashPrefixChecker struct {
+	dir string
+}
+
+// NewHashPrefixChecker checks passwords k-anonymity style against a directory
+// laid out like the Pwned Passwords range API: one file per upper-case hex
+// SHA-1 prefix of five characters (e.g. "5BAA6" or "5BAA6.txt"), each line
+// holding the remaining 35 characters of a hash, optionally followed by
+// ":count". Only the file for the password's prefix is read.
+func NewHashPrefixChecker(dir string) BreachedPasswordChecker {
+	return &hashPrefixChecker{
+		dir: dir,
+	}
+}
+
+func (c *hashPrefixChecker) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(c.dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		file, err = os.Open(filepath.Join(c.dir, prefix))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), ":")
+		if strings.EqualFold(strings.TrimSpace(line), suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
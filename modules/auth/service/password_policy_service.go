@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordPolicyService enforces the password policy wherever a password is
+// set: registration, password reset and password changes.
+type PasswordPolicyService interface {
+	GetPolicy() dto.PasswordPolicyResponse
+	// Validate checks a new password for the user. For a user that does not
+	// exist yet, only Name and Email need to be set.
+	Validate(ctx context.Context, tx *gorm.DB, password string, user entities.User) error
+	// Remember adds the hash of a newly set password to the user's history.
+	Remember(ctx context.Context, tx *gorm.DB, userID uuid.UUID, passwordHash string) error
+}
+
+type passwordPolicyService struct {
+	passwordHistoryRepository authRepo.PasswordHistoryRepository
+	breachedPasswordChecker   BreachedPasswordChecker
+	policy                    PasswordPolicy
+	db                        *gorm.DB
+}
+
+func NewPasswordPolicyService(
+	passwordHistoryRepo authRepo.PasswordHistoryRepository,
+	breachedPasswordChecker BreachedPasswordChecker,
+	policy PasswordPolicy,
+	db *gorm.DB,
+) PasswordPolicyService {
+	return &passwordPolicyService{
+		passwordHistoryRepository: passwordHistoryRepo,
+		breachedPasswordChecker:   breachedPasswordChecker,
+		policy:                    policy,
+		db:                        db,
+	}
+}
+
+func (s *passwordPolicyService) GetPolicy() dto.PasswordPolicyResponse {
+	return dto.PasswordPolicyResponse{
+		MinLength:     s.policy.MinLength,
+		MaxLength:     s.policy.MaxLength,
+		RequireUpper:  s.policy.RequireUpper,
+		RequireLower:  s.policy.RequireLower,
+		RequireDigit:  s.policy.RequireDigit,
+		RequireSymbol: s.policy.RequireSymbol,
+		HistorySize:   s.policy.HistorySize,
+	}
+}
+
+func (s *passwordPolicyService) Validate(ctx context.Context, tx *gorm.DB, password string, user entities.User) error {
+	if tx == nil {
+		tx = s.db
+	}
+
+	if err := s.policy.CheckRules(password, user.Name, user.Email); err != nil {
+		return err
+	}
+
+	breached, err := s.breachedPasswordChecker.IsBreached(password)
+	if err != nil {
+		return err
+	}
+
+	if breached {
+		return dto.ErrPasswordBreached
+	}
+
+	if s.policy.HistorySize == 0 || user.ID == uuid.Nil {
+		return nil
+	}
+
+	if user.Password != "" {
+		if same, _ := helpers.CheckPassword(user.Password, []byte(password)); same {
+			return dto.ErrPasswordReused
+		}
+	}
+
+	histories, err := s.passwordHistoryRepository.FindRecentByUserID(ctx, tx, user.ID.String(), s.policy.HistorySize)
+	if err != nil {
+		return err
+	}
+
+	for _, history := range histories {
+		if same, _ := helpers.CheckPassword(history.PasswordHash, []byte(password)); same {
+			return dto.ErrPasswordReused
+		}
+	}
+
+	return nil
+}
+
+func (s *passwordPolicyService) Remember(ctx context.Context, tx *gorm.DB, userID uuid.UUID, passwordHash string) error {
+	if s.policy.HistorySize == 0 {
+		return nil
+	}
+
+	if tx == nil {
+		tx = s.db
+	}
+
+	history := entities.PasswordHistory{
+		ID:           uuid.New(),
+		UserID:       userID,
+		PasswordHash: passwordHash,
+	}
+
+	if _, err := s.passwordHistoryRepository.Create(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return s.passwordHistoryRepository.DeleteAllButRecent(ctx, tx, userID.String(), s.policy.HistorySize)
+}
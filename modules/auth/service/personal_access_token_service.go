@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const personalAccessTokenPrefixLength = 12
+
+// PersonalAccessTokenService manages the API keys users create for machine
+// clients. A key acts as its owner, narrowed to the scopes chosen at creation.
+type PersonalAccessTokenService interface {
+	Create(ctx context.Context, principal dto.Principal, req dto.PersonalAccessTokenCreateRequest) (dto.PersonalAccessTokenCreateResponse, error)
+	List(ctx context.Context, userId string) ([]dto.PersonalAccessTokenResponse, error)
+	Revoke(ctx context.Context, userId string, tokenId string) error
+}
+
+type personalAccessTokenService struct {
+	personalAccessTokenRepository authRepo.PersonalAccessTokenRepository
+	db                            *gorm.DB
+}
+
+func NewPersonalAccessTokenService(
+	personalAccessTokenRepo authRepo.PersonalAccessTokenRepository,
+	db *gorm.DB,
+) PersonalAccessTokenService {
+	return &personalAccessTokenService{
+		personalAccessTokenRepository: personalAccessTokenRepo,
+		db:                            db,
+	}
+}
+
+// Create issues a new key. Scopes must be known permissions the caller holds
+// itself; the plaintext key is only part of this response.
+func (s *personalAccessTokenService) Create(
+	ctx context.Context,
+	principal dto.Principal,
+	req dto.PersonalAccessTokenCreateRequest,
+) (dto.PersonalAccessTokenCreateResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return dto.PersonalAccessTokenCreateResponse{}, dto.ErrAccessTokenExpiryInPast
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !slices.Contains(constants.Permissions, scope) {
+			return dto.PersonalAccessTokenCreateResponse{}, dto.ErrAccessTokenScopeNotFound
+		}
+		if !principal.HasPermission(scope) {
+			return dto.PersonalAccessTokenCreateResponse{}, dto.ErrAccessTokenScopeDenied
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	userID, err := uuid.Parse(principal.UserID)
+	if err != nil {
+		return dto.PersonalAccessTokenCreateResponse{}, err
+	}
+
+	secret, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return dto.PersonalAccessTokenCreateResponse{}, err
+	}
+	plainToken := dto.PERSONAL_ACCESS_TOKEN_PREFIX + secret
+
+	token, err := s.personalAccessTokenRepository.Create(ctx, s.db, entities.PersonalAccessToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      req.Name,
+		TokenHash: helpers.HashToken(plainToken),
+		Prefix:    plainToken[:personalAccessTokenPrefixLength],
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return dto.PersonalAccessTokenCreateResponse{}, err
+	}
+
+	return dto.PersonalAccessTokenCreateResponse{
+		PersonalAccessTokenResponse: toPersonalAccessTokenResponse(token),
+		Token:                       plainToken,
+	}, nil
+}
+
+func (s *personalAccessTokenService) List(ctx context.Context, userId string) ([]dto.PersonalAccessTokenResponse, error) {
+	tokens, err := s.personalAccessTokenRepository.FindByUserID(ctx, s.db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		responses = append(responses, toPersonalAccessTokenResponse(token))
+	}
+
+	return responses, nil
+}
+
+func (s *personalAccessTokenService) Revoke(ctx context.Context, userId string, tokenId string) error {
+	if _, err := uuid.Parse(tokenId); err != nil {
+		return dto.ErrAccessTokenNotFound
+	}
+
+	deleted, err := s.personalAccessTokenRepository.DeleteByIDAndUserID(ctx, s.db, tokenId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return dto.ErrAccessTokenNotFound
+	}
+
+	return nil
+}
+
+func toPersonalAccessTokenResponse(token entities.PersonalAccessToken) dto.PersonalAccessTokenResponse {
+	scopes := token.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return dto.PersonalAccessTokenResponse{
+		ID:         token.ID.String(),
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		LastUsedIP: token.LastUsedIP,
+		CreatedAt:  token.CreatedAt,
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"gorm.io/gorm"
+)
+
+// SessionService exposes refresh token families as the user's logged in devices.
+type SessionService interface {
+	GetSessions(ctx context.Context, userId string, currentSessionId string) ([]dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, userId string, currentSessionId string, sessionId string) error
+	RevokeOtherSessions(ctx context.Context, userId string, currentSessionId string) error
+	RevokeAllSessions(ctx context.Context, userId string) error
+}
+
+type sessionService struct {
+	refreshTokenRepository authRepo.RefreshTokenRepository
+	tokenRevocationService TokenRevocationService
+	db                     *gorm.DB
+}
+
+func NewSessionService(
+	refreshTokenRepo authRepo.RefreshTokenRepository,
+	tokenRevocationService TokenRevocationService,
+	db *gorm.DB,
+) SessionService {
+	return &sessionService{
+		refreshTokenRepository: refreshTokenRepo,
+		tokenRevocationService: tokenRevocationService,
+		db:                     db,
+	}
+}
+
+func (s *sessionService) GetSessions(ctx context.Context, userId string, currentSessionId string) ([]dto.SessionResponse, error) {
+	refreshTokens, err := s.refreshTokenRepository.FindActiveByUserID(ctx, s.db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]dto.SessionResponse, 0, len(refreshTokens))
+	for _, refreshToken := range refreshTokens {
+		sessions = append(sessions, dto.SessionResponse{
+			ID:         refreshToken.FamilyID.String(),
+			Name:       refreshToken.Name,
+			UserAgent:  refreshToken.UserAgent,
+			IPAddress:  refreshToken.IPAddress,
+			CreatedAt:  refreshToken.SessionCreatedAt,
+			LastUsedAt: refreshToken.LastUsedAt,
+			ExpiresAt:  refreshToken.ExpiresAt,
+			Current:    refreshToken.FamilyID.String() == currentSessionId,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (s *sessionService) RevokeSession(ctx context.Context, userId string, currentSessionId string, sessionId string) error {
+	if sessionId == currentSessionId {
+		return dto.ErrRevokeCurrentSession
+	}
+
+	refreshTokens, err := s.refreshTokenRepository.FindActiveByUserID(ctx, s.db, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, refreshToken := range refreshTokens {
+		if refreshToken.FamilyID.String() == sessionId {
+			if err := s.refreshTokenRepository.DeleteByFamilyID(ctx, s.db, sessionId); err != nil {
+				return err
+			}
+			return s.tokenRevocationService.RevokeSession(ctx, sessionId)
+		}
+	}
+
+	return dto.ErrSessionNotFound
+}
+
+// RevokeOtherSessions signs out every other session of the user. Sessions
+// whose refresh token already expired are revoked as well, since their last
+// access token may still be valid.
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userId string, currentSessionId string) error {
+	if currentSessionId == "" {
+		return dto.ErrCurrentSessionUnknown
+	}
+
+	familyIDs, err := s.refreshTokenRepository.FindFamilyIDsByUserID(ctx, s.db, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.refreshTokenRepository.DeleteByUserIDExceptFamilyID(ctx, s.db, userId, currentSessionId); err != nil {
+		return err
+	}
+
+	for _, familyID := range familyIDs {
+		if familyID == currentSessionId {
+			continue
+		}
+		if err := s.tokenRevocationService.RevokeSession(ctx, familyID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokeAllSessions signs the user out everywhere, e.g. once the account is
+// suspected to be compromised.
+func (s *sessionService) RevokeAllSessions(ctx context.Context, userId string) error {
+	if err := s.refreshTokenRepository.DeleteByUserID(ctx, s.db, userId); err != nil {
+		return err
+	}
+
+	return s.tokenRevocationService.RevokeUser(ctx, userId)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+)
+
+// TokenRevocationService invalidates access tokens before they expire. A
+// single token is revoked by its jti; sessions and users are revoked by
+// recording when it happened, which rejects every token issued before.
+type TokenRevocationService interface {
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeUser(ctx context.Context, userID string) error
+	IsRevoked(ctx context.Context, claims *JWTCustomClaim) (bool, error)
+}
+
+type tokenRevocationService struct {
+	store authRepo.RevocationStore
+}
+
+func NewTokenRevocationService(store authRepo.RevocationStore) TokenRevocationService {
+	return &tokenRevocationService{
+		store: store,
+	}
+}
+
+func (s *tokenRevocationService) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return s.store.Revoke(ctx, "jti:"+tokenID, expiresAt)
+}
+
+// RevokeSession and RevokeUser only need to outlive the longest lived access
+// token, after which every token they could match has expired anyway.
+func (s *tokenRevocationService) RevokeSession(ctx context.Context, sessionID string) error {
+	return s.store.Revoke(ctx, "sid:"+sessionID, time.Now().Add(accessTokenExpiry))
+}
+
+func (s *tokenRevocationService) RevokeUser(ctx context.Context, userID string) error {
+	return s.store.Revoke(ctx, "user:"+userID, time.Now().Add(accessTokenExpiry))
+}
+
+func (s *tokenRevocationService) IsRevoked(ctx context.Context, claims *JWTCustomClaim) (bool, error) {
+	keys := []string{"user:" + claims.UserID}
+	if claims.ID != "" {
+		keys = append(keys, "jti:"+claims.ID)
+	}
+	if claims.SessionID != "" {
+		keys = append(keys, "sid:"+claims.SessionID)
+	}
+
+	revoked, err := s.store.Lookup(ctx, keys...)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := revoked["jti:"+claims.ID]; ok && claims.ID != "" {
+		return true, nil
+	}
+
+	for _, revokedAt := range revoked {
+		// iat only has second precision, so every token issued in the same
+		// second as the revocation is rejected, including one issued before
+		// it on an exact second.
+		cutoff := revokedAt.Truncate(time.Second).Add(time.Second)
+		if claims.IssuedAt == nil || claims.IssuedAt.Time.Before(cutoff) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	userRepo "github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// stubUserRepository knows users by id and whether they are active.
+type stubUserRepository struct {
+	userRepo.UserRepository
+	active  map[string]bool
+	lookups int
+}
+
+func newStubUserRepository() *stubUserRepository {
+	return &stubUserRepository{active: map[string]bool{
+		"user-id":     true,
+		"admin-id":    true,
+		"disabled-id": false,
+	}}
+}
+
+func (r *stubUserRepository) GetUserById(_ context.Context, _ *gorm.DB, userId string) (entities.User, error) {
+	r.lookups++
+	active, ok := r.active[userId]
+	if !ok {
+		return entities.User{}, gorm.ErrRecordNotFound
+	}
+	return entities.User{IsActive: active}, nil
+}
+
+func TestAccountStatusService_CachesStatus(t *testing.T) {
+	ctx := context.Background()
+	users := newStubUserRepository()
+	accountStatusService := service.NewAccountStatusService(users, nil)
+
+	active, err := accountStatusService.IsActive(ctx, "user-id")
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	users.active["user-id"] = false
+	active, err = accountStatusService.IsActive(ctx, "user-id")
+	require.NoError(t, err)
+	assert.True(t, active)
+	assert.Equal(t, 1, users.lookups)
+
+	accountStatusService.Invalidate("user-id")
+	active, err = accountStatusService.IsActive(ctx, "user-id")
+	require.NoError(t, err)
+	assert.False(t, active)
+	assert.Equal(t, 2, users.lookups)
+}
+
+func TestAccountStatusService_UnknownUser(t *testing.T) {
+	accountStatusService := service.NewAccountStatusService(newStubUserRepository(), nil)
+
+	active, err := accountStatusService.IsActive(context.Background(), "deleted-id")
+	require.NoError(t, err)
+	assert.False(t, active)
+}
+
+func TestAuthenticator_DisabledAccount(t *testing.T) {
+	ctx := context.Background()
+	jwtService, _, authenticator := newTestAuthenticator(t)
+
+	token := jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: "disabled-id", Role: "user"})
+	_, err := authenticator.AuthenticateAccessToken(ctx, token)
+	assert.ErrorIs(t, err, dto.ErrAccountDisabled)
+
+	// Disabling the impersonating admin ends the impersonation as well.
+	token = jwtService.GenerateAccessToken(service.AccessTokenSubject{
+		UserID:    "user-id",
+		Role:      "user",
+		ActorID:   "disabled-id",
+		ExpiresIn: time.Minute,
+	})
+	_, err = authenticator.AuthenticateAccessToken(ctx, token)
+	assert.ErrorIs(t, err, dto.ErrAccountDisabled)
+}
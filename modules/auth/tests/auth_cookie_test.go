@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenAuthenticator accepts the single access token "valid".
+type tokenAuthenticator struct{}
+
+func (tokenAuthenticator) AuthenticateAccessToken(_ context.Context, token string) (authDto.Principal, error) {
+	if token != "valid" {
+		return authDto.Principal{}, authDto.ErrTokenRevoked
+	}
+	return authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER), nil
+}
+
+func (tokenAuthenticator) AuthenticateAPIKey(context.Context, string, string) (authDto.Principal, error) {
+	return authDto.Principal{}, authDto.ErrAPIKeyInvalid
+}
+
+func newCookieAuthRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/", middlewares.Authenticate(tokenAuthenticator{}), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return router
+}
+
+func serveRequest(router *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAuthenticate_AccessTokenCookie(t *testing.T) {
+	router := newCookieAuthRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: constants.ACCESS_TOKEN_COOKIE, Value: "valid"})
+
+	assert.Equal(t, http.StatusOK, serveRequest(router, req).Code)
+}
+
+func TestAuthenticate_CookieRequiresCSRFOnUnsafeMethods(t *testing.T) {
+	router := newCookieAuthRouter()
+
+	withCookies := func(header string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.AddCookie(&http.Cookie{Name: constants.ACCESS_TOKEN_COOKIE, Value: "valid"})
+		req.AddCookie(&http.Cookie{Name: constants.CSRF_TOKEN_COOKIE, Value: "csrf"})
+		if header != "" {
+			req.Header.Set(constants.CSRF_TOKEN_HEADER, header)
+		}
+		return req
+	}
+
+	assert.Equal(t, http.StatusForbidden, serveRequest(router, withCookies("")).Code)
+	assert.Equal(t, http.StatusForbidden, serveRequest(router, withCookies("other")).Code)
+	assert.Equal(t, http.StatusOK, serveRequest(router, withCookies("csrf")).Code)
+}
+
+func TestAuthenticate_BearerIgnoresCSRF(t *testing.T) {
+	router := newCookieAuthRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	req.AddCookie(&http.Cookie{Name: constants.ACCESS_TOKEN_COOKIE, Value: "stale"})
+
+	assert.Equal(t, http.StatusOK, serveRequest(router, req).Code)
+}
+
+func respondWithCookies(t *testing.T, transport string) (*httptest.ResponseRecorder, authDto.TokenResponse) {
+	t.Setenv("AUTH_TOKEN_TRANSPORT", transport)
+	cookies := middlewares.NewAuthCookies()
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+
+	body, err := cookies.Respond(ctx, authDto.TokenResponse{
+		AccessToken:           "access",
+		RefreshToken:          "refresh",
+		RefreshTokenExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	return recorder, body
+}
+
+func cookiesByName(recorder *httptest.ResponseRecorder) map[string]*http.Cookie {
+	cookies := make(map[string]*http.Cookie)
+	for _, cookie := range recorder.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+func TestAuthCookies_CookieMode(t *testing.T) {
+	recorder, body := respondWithCookies(t, constants.TOKEN_TRANSPORT_COOKIE)
+
+	assert.Empty(t, body.AccessToken)
+	assert.Empty(t, body.RefreshToken)
+
+	cookies := cookiesByName(recorder)
+	require.Contains(t, cookies, constants.ACCESS_TOKEN_COOKIE)
+	assert.Equal(t, "access", cookies[constants.ACCESS_TOKEN_COOKIE].Value)
+	assert.True(t, cookies[constants.ACCESS_TOKEN_COOKIE].HttpOnly)
+	assert.True(t, cookies[constants.ACCESS_TOKEN_COOKIE].Secure)
+
+	require.Contains(t, cookies, constants.REFRESH_TOKEN_COOKIE)
+	assert.Equal(t, "/api/auth", cookies[constants.REFRESH_TOKEN_COOKIE].Path)
+
+	require.Contains(t, cookies, constants.CSRF_TOKEN_COOKIE)
+	assert.False(t, cookies[constants.CSRF_TOKEN_COOKIE].HttpOnly)
+	assert.NotEmpty(t, cookies[constants.CSRF_TOKEN_COOKIE].Value)
+}
+
+func TestAuthCookies_BothMode(t *testing.T) {
+	recorder, body := respondWithCookies(t, constants.TOKEN_TRANSPORT_BOTH)
+
+	assert.Equal(t, "access", body.AccessToken)
+	assert.Contains(t, cookiesByName(recorder), constants.ACCESS_TOKEN_COOKIE)
+}
+
+func TestAuthCookies_BearerModeSetsNoCookies(t *testing.T) {
+	recorder, body := respondWithCookies(t, "")
+
+	assert.Equal(t, "access", body.AccessToken)
+	assert.Equal(t, "refresh", body.RefreshToken)
+	assert.Empty(t, recorder.Result().Cookies())
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthorizedRouter(principal *authDto.Principal, guard gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", func(ctx *gin.Context) {
+		if principal != nil {
+			ctx.Set("principal", *principal)
+		}
+		ctx.Next()
+	}, guard, func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return router
+}
+
+func serve(router *gin.Engine) int {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	return recorder.Code
+}
+
+func TestRestrictToScopes_NarrowsPermissions(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_ADMIN).
+		RestrictToScopes([]string{constants.PERMISSION_USER_READ})
+
+	assert.True(t, principal.HasPermission(constants.PERMISSION_USER_READ))
+	assert.False(t, principal.HasPermission(constants.PERMISSION_USER_WRITE))
+}
+
+func TestRestrictToScopes_CannotExceedRole(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER).
+		RestrictToScopes([]string{constants.PERMISSION_USER_WRITE})
+
+	assert.False(t, principal.HasPermission(constants.PERMISSION_USER_WRITE))
+}
+
+func TestDenyAPIKey_RejectsAPIKeyPrincipal(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	principal.APIKeyID = "key-id"
+	router := newAuthorizedRouter(&principal, middlewares.DenyAPIKey())
+
+	assert.Equal(t, http.StatusForbidden, serve(router))
+}
+
+func TestDenyAPIKey_AllowsSessionPrincipal(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	router := newAuthorizedRouter(&principal, middlewares.DenyAPIKey())
+
+	assert.Equal(t, http.StatusOK, serve(router))
+}
+
+func TestDenyImpersonation_RejectsImpersonator(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	principal.ImpersonatorID = "admin-id"
+	router := newAuthorizedRouter(&principal, middlewares.DenyImpersonation())
+
+	assert.Equal(t, http.StatusForbidden, serve(router))
+}
+
+func TestDenyImpersonation_AllowsAccountOwner(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	router := newAuthorizedRouter(&principal, middlewares.DenyImpersonation())
+
+	assert.Equal(t, http.StatusOK, serve(router))
+}
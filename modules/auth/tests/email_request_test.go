@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEmailRequestService(user entities.User) service.AuthService {
+	return service.NewAuthService(
+		&stubEmailUserRepository{user: user},
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+}
+
+func TestSendEmails_DoNotDiscloseAccounts(t *testing.T) {
+	ctx := context.Background()
+	authService := newEmailRequestService(entities.User{ID: uuid.New(), Email: "verified@example.com", IsVerified: true})
+
+	assert.NoError(t, authService.SendPasswordReset(ctx, dto.SendPasswordResetRequest{Email: "unknown@example.com"}))
+	assert.NoError(t, authService.SendVerificationEmail(ctx, userDto.SendVerificationEmailRequest{Email: "unknown@example.com"}))
+	assert.NoError(t, authService.SendVerificationEmail(ctx, userDto.SendVerificationEmailRequest{Email: "verified@example.com"}))
+}
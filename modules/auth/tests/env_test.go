@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDurationEnv(t *testing.T) {
+	t.Setenv("TEST_DURATION", "")
+	assert.Equal(t, time.Minute, helpers.GetDurationEnv("TEST_DURATION", time.Minute))
+
+	t.Setenv("TEST_DURATION", "-1s")
+	assert.Equal(t, time.Minute, helpers.GetDurationEnv("TEST_DURATION", time.Minute))
+
+	t.Setenv("TEST_DURATION", "90s")
+	assert.Equal(t, time.Second*90, helpers.GetDurationEnv("TEST_DURATION", time.Minute))
+}
+
+func TestGetIntEnv(t *testing.T) {
+	t.Setenv("TEST_INT", "many")
+	assert.Equal(t, int64(5), helpers.GetIntEnv("TEST_INT", 5))
+
+	t.Setenv("TEST_INT", "0")
+	assert.Equal(t, int64(5), helpers.GetIntEnv("TEST_INT", 5))
+
+	t.Setenv("TEST_INT", "12")
+	assert.Equal(t, int64(12), helpers.GetIntEnv("TEST_INT", 5))
+}
+
+func TestGetNonNegativeEnv_AcceptsZero(t *testing.T) {
+	t.Setenv("TEST_DURATION", "0")
+	assert.Equal(t, time.Duration(0), helpers.GetNonNegativeDurationEnv("TEST_DURATION", time.Minute))
+
+	t.Setenv("TEST_DURATION", "-1s")
+	assert.Equal(t, time.Minute, helpers.GetNonNegativeDurationEnv("TEST_DURATION", time.Minute))
+
+	t.Setenv("TEST_INT", "0")
+	assert.Equal(t, int64(0), helpers.GetNonNegativeIntEnv("TEST_INT", 5))
+
+	t.Setenv("TEST_INT", "-3")
+	assert.Equal(t, int64(5), helpers.GetNonNegativeIntEnv("TEST_INT", 5))
+
+	t.Setenv("TEST_INT", "")
+	assert.Equal(t, int64(5), helpers.GetNonNegativeIntEnv("TEST_INT", 5))
+}
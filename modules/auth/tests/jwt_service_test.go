@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTService_AsymmetricAlgorithms(t *testing.T) {
+	for _, algorithm := range []string{"RS256", "ES256", "EdDSA"} {
+		t.Run(algorithm, func(t *testing.T) {
+			t.Setenv("JWT_ALGORITHM", algorithm)
+			t.Setenv("JWT_KEYS_DIR", t.TempDir())
+
+			jwtService, err := service.NewJWTService()
+			require.NoError(t, err)
+
+			token := jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user"})
+			claims, err := jwtService.GetClaimsByToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, "user-id", claims.UserID)
+
+			jwks := jwtService.GetJWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, algorithm, jwks.Keys[0].Alg)
+			assert.NotEmpty(t, jwks.Keys[0].Kid)
+		})
+	}
+}
+
+func TestJWTService_KeysDirSharedBetweenInstances(t *testing.T) {
+	t.Setenv("JWT_ALGORITHM", "ES256")
+	t.Setenv("JWT_KEYS_DIR", t.TempDir())
+
+	issuer, err := service.NewJWTService()
+	require.NoError(t, err)
+
+	verifier, err := service.NewJWTService()
+	require.NoError(t, err)
+
+	token := issuer.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user"})
+	_, err = verifier.GetClaimsByToken(token)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_RejectsOtherAlgorithm(t *testing.T) {
+	t.Setenv("JWT_ALGORITHM", "HS256")
+	t.Setenv("JWT_SECRET", "secret")
+
+	hmacService, err := service.NewJWTService()
+	require.NoError(t, err)
+	assert.Empty(t, hmacService.GetJWKS().Keys)
+
+	t.Setenv("JWT_ALGORITHM", "RS256")
+	t.Setenv("JWT_KEYS_DIR", t.TempDir())
+
+	rsaService, err := service.NewJWTService()
+	require.NoError(t, err)
+
+	token := hmacService.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user"})
+	_, err = rsaService.GetClaimsByToken(token)
+	assert.Error(t, err)
+}
+
+func TestJWTService_ProductionRequiresKey(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("REFRESH_TOKEN_SECRET", "refresh-secret")
+
+	t.Setenv("JWT_ALGORITHM", "HS256")
+	_, err := service.NewJWTService()
+	assert.Error(t, err)
+
+	t.Setenv("JWT_ALGORITHM", "RS256")
+	t.Setenv("JWT_KEYS_DIR", t.TempDir())
+	_, err = service.NewJWTService()
+	assert.Error(t, err)
+}
+
+func TestJWTService_ReloadKeepsKeysWhenDirEmpty(t *testing.T) {
+	keysDir := t.TempDir()
+	t.Setenv("JWT_ALGORITHM", "ES256")
+	t.Setenv("JWT_KEYS_DIR", keysDir)
+
+	jwtService, err := service.NewJWTService()
+	require.NoError(t, err)
+	token := jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user"})
+
+	entries, err := os.ReadDir(keysDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		require.NoError(t, os.Remove(filepath.Join(keysDir, entry.Name())))
+	}
+
+	assert.Error(t, jwtService.ReloadKeys())
+
+	assert.NotPanics(t, func() {
+		token = jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user"})
+	})
+	_, err = jwtService.GetClaimsByToken(token)
+	assert.NoError(t, err)
+	assert.Len(t, jwtService.GetJWKS().Keys, 1)
+}
+
+// ageKeys makes every key in keysDir look two hours old.
+func ageKeys(t *testing.T, keysDir string) {
+	entries, err := os.ReadDir(keysDir)
+	require.NoError(t, err)
+
+	past := time.Now().Add(-2 * time.Hour)
+	for _, entry := range entries {
+		require.NoError(t, os.Chtimes(filepath.Join(keysDir, entry.Name()), past, past))
+	}
+}
+
+func countKeys(t *testing.T, keysDir string) int {
+	keys, err := filepath.Glob(filepath.Join(keysDir, "*.pem"))
+	require.NoError(t, err)
+	return len(keys)
+}
+
+func TestJWTService_ReplicasRotateOnce(t *testing.T) {
+	keysDir := t.TempDir()
+	t.Setenv("JWT_ALGORITHM", "ES256")
+	t.Setenv("JWT_KEYS_DIR", keysDir)
+	t.Setenv("JWT_KEY_ROTATION_INTERVAL", "1h")
+
+	first, err := service.NewJWTService()
+	require.NoError(t, err)
+	second, err := service.NewJWTService()
+	require.NoError(t, err)
+	ageKeys(t, keysDir)
+
+	rotated, err := first.RotateKeys()
+	require.NoError(t, err)
+	assert.True(t, rotated)
+
+	// The second replica still holds the old key, but finds the new one
+	// once it has the lock.
+	rotated, err = second.RotateKeys()
+	require.NoError(t, err)
+	assert.False(t, rotated)
+	assert.Equal(t, 2, countKeys(t, keysDir))
+
+	token := second.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user"})
+	_, err = first.GetClaimsByToken(token)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_RotateSkipsWhileLocked(t *testing.T) {
+	keysDir := t.TempDir()
+	t.Setenv("JWT_ALGORITHM", "ES256")
+	t.Setenv("JWT_KEYS_DIR", keysDir)
+	t.Setenv("JWT_KEY_ROTATION_INTERVAL", "1h")
+
+	jwtService, err := service.NewJWTService()
+	require.NoError(t, err)
+	ageKeys(t, keysDir)
+
+	lockFile := filepath.Join(keysDir, ".rotate.lock")
+	require.NoError(t, os.WriteFile(lockFile, nil, 0600))
+
+	rotated, err := jwtService.RotateKeys()
+	require.NoError(t, err)
+	assert.False(t, rotated)
+	assert.Equal(t, 1, countKeys(t, keysDir))
+
+	// A lock left behind by a replica that stopped expires.
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(lockFile, past, past))
+
+	rotated, err = jwtService.RotateKeys()
+	require.NoError(t, err)
+	assert.True(t, rotated)
+	assert.Equal(t, 2, countKeys(t, keysDir))
+	assert.NoFileExists(t, lockFile)
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// lockingAttemptRepository reports every email as having just reached the
+// default limit of five failed logins.
+type lockingAttemptRepository struct {
+	authRepo.LoginAttemptRepository
+}
+
+func (lockingAttemptRepository) Create(_ context.Context, _ *gorm.DB, attempt entities.LoginAttempt) (entities.LoginAttempt, error) {
+	return attempt, nil
+}
+
+func (lockingAttemptRepository) SummarizeByEmail(context.Context, *gorm.DB, string, time.Time) (authRepo.LoginAttemptSummary, error) {
+	now := time.Now()
+	return authRepo.LoginAttemptSummary{Count: 5, LastAttemptAt: &now}, nil
+}
+
+func TestProgressiveLoginDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), service.ProgressiveLoginDelay(0))
+	assert.Equal(t, time.Duration(0), service.ProgressiveLoginDelay(1))
+	assert.Equal(t, time.Second, service.ProgressiveLoginDelay(2))
+	assert.Equal(t, time.Second*2, service.ProgressiveLoginDelay(3))
+	assert.Equal(t, time.Second*16, service.ProgressiveLoginDelay(6))
+	assert.Equal(t, time.Second*30, service.ProgressiveLoginDelay(7))
+	assert.Equal(t, time.Second*30, service.ProgressiveLoginDelay(100))
+}
+
+func TestLoginThrottledError_MatchesSentinel(t *testing.T) {
+	var err error = &dto.LoginThrottledError{RetryAfter: time.Minute}
+
+	assert.ErrorIs(t, err, dto.ErrTooManyLoginAttempts)
+
+	var throttled *dto.LoginThrottledError
+	assert.True(t, errors.As(err, &throttled))
+	assert.Equal(t, time.Minute, throttled.RetryAfter)
+}
+
+func TestRecordFailure_LockoutMailErrorIsNotReturned(t *testing.T) {
+	tokens := newMemoryOneTimeTokenRepository()
+	securityEvents := &stubSecurityEventRepository{}
+	throttle := service.NewLoginThrottleService(lockingAttemptRepository{}, service.NewOneTimeTokenService(tokens), securityEvents, nil)
+
+	// Mail is not configured in tests, so sending the unlock link fails.
+	user := entities.User{ID: uuid.New(), Email: "owner@example.com"}
+	require.NoError(t, throttle.RecordFailure(context.Background(), user.Email, &user, dto.ClientInfo{}))
+
+	assert.Len(t, securityEvents.events, 1)
+	assert.Len(t, tokens.tokens, 1)
+}
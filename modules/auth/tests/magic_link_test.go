@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type stubMagicLinkUserRepository struct {
+	stubEmailUserRepository
+}
+
+func (r *stubMagicLinkUserRepository) GetUserById(_ context.Context, _ *gorm.DB, userId string) (entities.User, error) {
+	if userId != r.user.ID.String() {
+		return entities.User{}, gorm.ErrRecordNotFound
+	}
+	return r.user, nil
+}
+
+// stubMFAEnabledService answers every login with an MFA challenge, so a
+// successful magic link login needs no session storage.
+type stubMFAEnabledService struct {
+	service.MFAService
+}
+
+func (stubMFAEnabledService) IsEnabled(context.Context, *gorm.DB, string) (bool, error) {
+	return true, nil
+}
+
+func newMagicLinkService(t *testing.T, user entities.User) (service.AuthService, service.OneTimeTokenService, *memoryOneTimeTokenRepository) {
+	// Transactions need a connection, but the stubs never run a statement.
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+
+	tokens := newMemoryOneTimeTokenRepository()
+	oneTimeTokenService := service.NewOneTimeTokenService(tokens)
+	authService := service.NewAuthService(
+		&stubMagicLinkUserRepository{stubEmailUserRepository{user: user}},
+		nil,
+		oneTimeTokenService,
+		stubMFAEnabledService{},
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		db,
+	)
+	return authService, oneTimeTokenService, tokens
+}
+
+func magicLinkUser() entities.User {
+	return entities.User{ID: uuid.New(), Email: "owner@example.com", IsActive: true, IsVerified: true}
+}
+
+func TestSendMagicLink_IssuesToken(t *testing.T) {
+	user := magicLinkUser()
+	authService, _, tokens := newMagicLinkService(t, user)
+
+	// Mail is not configured in tests, so sending the link fails.
+	require.NoError(t, authService.SendMagicLink(context.Background(), dto.SendMagicLinkRequest{Email: user.Email}))
+	require.Len(t, tokens.tokens, 1)
+	for _, token := range tokens.tokens {
+		assert.Equal(t, user.ID, token.UserID)
+		assert.Equal(t, constants.TOKEN_PURPOSE_MAGIC_LINK, token.Purpose)
+	}
+
+	// A second request within the resend interval sends nothing.
+	require.NoError(t, authService.SendMagicLink(context.Background(), dto.SendMagicLinkRequest{Email: user.Email}))
+	assert.Len(t, tokens.tokens, 1)
+}
+
+func TestSendMagicLink_SkipsDisabledAndUnverifiedAccounts(t *testing.T) {
+	disabled := magicLinkUser()
+	disabled.IsActive = false
+	unverified := magicLinkUser()
+	unverified.IsVerified = false
+
+	for _, user := range []entities.User{disabled, unverified} {
+		authService, _, tokens := newMagicLinkService(t, user)
+
+		require.NoError(t, authService.SendMagicLink(context.Background(), dto.SendMagicLinkRequest{Email: user.Email}))
+		assert.Empty(t, tokens.tokens)
+	}
+}
+
+func TestMagicLinkLogin_ConsumesTokenOnce(t *testing.T) {
+	ctx := context.Background()
+	user := magicLinkUser()
+	authService, oneTimeTokenService, _ := newMagicLinkService(t, user)
+
+	magicToken, err := oneTimeTokenService.Issue(ctx, nil, user.ID, constants.TOKEN_PURPOSE_MAGIC_LINK)
+	require.NoError(t, err)
+
+	result, err := authService.MagicLinkLogin(ctx, dto.MagicLinkLoginRequest{Token: magicToken}, dto.ClientInfo{})
+	require.NoError(t, err)
+	assert.True(t, result.MFARequired)
+
+	_, err = authService.MagicLinkLogin(ctx, dto.MagicLinkLoginRequest{Token: magicToken}, dto.ClientInfo{})
+	assert.ErrorIs(t, err, dto.ErrMagicLinkToken)
+}
+
+func TestMagicLinkLogin_RejectsExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	user := magicLinkUser()
+	authService, oneTimeTokenService, tokens := newMagicLinkService(t, user)
+
+	magicToken, err := oneTimeTokenService.Issue(ctx, nil, user.ID, constants.TOKEN_PURPOSE_MAGIC_LINK)
+	require.NoError(t, err)
+	tokens.expire()
+
+	_, err = authService.MagicLinkLogin(ctx, dto.MagicLinkLoginRequest{Token: magicToken}, dto.ClientInfo{})
+	assert.ErrorIs(t, err, dto.ErrMagicLinkToken)
+}
+
+func TestMagicLinkLogin_RejectsDisabledAndUnverifiedAccounts(t *testing.T) {
+	ctx := context.Background()
+	disabled := magicLinkUser()
+	disabled.IsActive = false
+	unverified := magicLinkUser()
+	unverified.IsVerified = false
+
+	for user, want := range map[*entities.User]error{
+		&disabled:   dto.ErrAccountDisabled,
+		&unverified: dto.ErrMagicLinkUnverified,
+	} {
+		authService, oneTimeTokenService, _ := newMagicLinkService(t, *user)
+
+		// The account changed after the link was sent.
+		magicToken, err := oneTimeTokenService.Issue(ctx, nil, user.ID, constants.TOKEN_PURPOSE_MAGIC_LINK)
+		require.NoError(t, err)
+
+		_, err = authService.MagicLinkLogin(ctx, dto.MagicLinkLoginRequest{Token: magicToken}, dto.ClientInfo{})
+		assert.ErrorIs(t, err, want)
+	}
+}
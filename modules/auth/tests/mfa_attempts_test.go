@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// wrongMFACode is never a valid TOTP code.
+const wrongMFACode = "abcdef"
+
+// stubMFARepository holds the enrollment of a single user.
+type stubMFARepository struct {
+	authRepo.MFARepository
+	mfa *entities.UserMFA
+}
+
+func (r *stubMFARepository) FindByUserID(context.Context, *gorm.DB, string) (entities.UserMFA, error) {
+	if r.mfa == nil {
+		return entities.UserMFA{}, gorm.ErrRecordNotFound
+	}
+	return *r.mfa, nil
+}
+
+func (r *stubMFARepository) Save(_ context.Context, _ *gorm.DB, mfa entities.UserMFA) (entities.UserMFA, error) {
+	r.mfa = &mfa
+	return mfa, nil
+}
+
+func (r *stubMFARepository) MarkStepUsed(_ context.Context, _ *gorm.DB, _ string, step int64) (bool, error) {
+	if r.mfa.LastUsedStep >= step {
+		return false, nil
+	}
+	r.mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (r *stubMFARepository) RecordFailedAttempt(_ context.Context, _ *gorm.DB, _ string, at time.Time, windowStart time.Time) error {
+	if r.mfa.LastFailedAt == nil || r.mfa.LastFailedAt.Before(windowStart) {
+		r.mfa.FailedAttempts = 1
+	} else {
+		r.mfa.FailedAttempts++
+	}
+	r.mfa.LastFailedAt = &at
+	return nil
+}
+
+func (r *stubMFARepository) ResetFailedAttempts(context.Context, *gorm.DB, string) error {
+	r.mfa.FailedAttempts = 0
+	r.mfa.LastFailedAt = nil
+	return nil
+}
+
+func newTestMFAService(t *testing.T) (service.MFAService, *stubMFARepository, string) {
+	// Transactions need a connection, but the stubs never run a statement.
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+
+	mfaRepository := &stubMFARepository{}
+	users := &stubResetUserRepository{user: entities.User{ID: uuid.New(), Email: "owner@example.com"}}
+	mfaService, err := service.NewMFAService(mfaRepository, users, nil, db)
+	require.NoError(t, err)
+
+	enrollment, err := mfaService.Enroll(context.Background(), users.user.ID.String())
+	require.NoError(t, err)
+
+	return mfaService, mfaRepository, enrollment.Secret
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := helpers.TOTPCode(secret, helpers.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+func TestMFAService_ConfirmLocksAfterFailures(t *testing.T) {
+	ctx := context.Background()
+	mfaService, mfaRepository, secret := newTestMFAService(t)
+	userId := mfaRepository.mfa.UserID.String()
+
+	for range 5 {
+		_, err := mfaService.Confirm(ctx, userId, dto.MFACodeRequest{Code: wrongMFACode}, dto.ClientInfo{})
+		assert.ErrorIs(t, err, dto.ErrMFACodeInvalid)
+	}
+
+	_, err := mfaService.Confirm(ctx, userId, dto.MFACodeRequest{Code: currentTOTPCode(t, secret)}, dto.ClientInfo{})
+	assert.ErrorIs(t, err, dto.ErrMFATooManyAttempts)
+}
+
+func TestMFAService_FailuresSpanChallenges(t *testing.T) {
+	ctx := context.Background()
+	mfaService, mfaRepository, secret := newTestMFAService(t)
+	userId := mfaRepository.mfa.UserID.String()
+	confirmedAt := time.Now()
+	mfaRepository.mfa.ConfirmedAt = &confirmedAt
+
+	// Each wrong code could come from a different login's challenge, as
+	// VerifyMFA records every failure against the user.
+	for range 5 {
+		require.ErrorIs(t, mfaService.VerifyCode(ctx, nil, userId, wrongMFACode, dto.ClientInfo{}), dto.ErrMFACodeInvalid)
+		require.NoError(t, mfaService.RecordFailure(ctx, userId))
+	}
+
+	code := currentTOTPCode(t, secret)
+	assert.ErrorIs(t, mfaService.VerifyCode(ctx, nil, userId, code, dto.ClientInfo{}), dto.ErrMFATooManyAttempts)
+
+	// Once the lockout has passed, a valid code works and clears the count.
+	lastFailedAt := time.Now().Add(-time.Hour)
+	mfaRepository.mfa.LastFailedAt = &lastFailedAt
+	require.NoError(t, mfaService.VerifyCode(ctx, nil, userId, code, dto.ClientInfo{}))
+	assert.Zero(t, mfaRepository.mfa.FailedAttempts)
+}
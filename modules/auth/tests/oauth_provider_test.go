@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+const fakeOIDCClientID = "test-client"
+
+// fakeOIDCServer is a minimal OpenID Connect provider: discovery, JWKS, an
+// authorize endpoint that approves immediately and a PKCE-checking token
+// endpoint.
+type fakeOIDCServer struct {
+	*httptest.Server
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]fakeAuthorization
+}
+
+type fakeAuthorization struct {
+	challenge string
+	nonce     string
+}
+
+func newFakeOIDCServer(t *testing.T) *fakeOIDCServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := &fakeOIDCServer{key: key, codes: make(map[string]fakeAuthorization)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", server.discovery)
+	mux.HandleFunc("/jwks", server.jwks)
+	mux.HandleFunc("/authorize", server.authorize)
+	mux.HandleFunc("/token", server.token)
+
+	server.Server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *fakeOIDCServer) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (s *fakeOIDCServer) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test-key",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (s *fakeOIDCServer) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "pkce required", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+	s.mu.Lock()
+	s.codes[code] = fakeAuthorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	s.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	redirect.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *fakeOIDCServer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	authorization, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != authorization.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            "external-user-1",
+		"aud":            fakeOIDCClientID,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"nonce":          authorization.nonce,
+		"email":          "oidc.user@example.com",
+		"email_verified": true,
+		"name":           "OIDC User",
+	})
+	idToken.Header["kid"] = "test-key"
+
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     signed,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// authorizeWithFakeServer follows the provider's authorization URL like a
+// browser would and returns the code and state from the callback redirect.
+func authorizeWithFakeServer(t *testing.T, authorizationURL string) (string, string) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(authorizationURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func newFakeOIDCProvider(server *fakeOIDCServer) service.OAuthProvider {
+	return service.NewOIDCProvider(service.OAuthProviderConfig{
+		Name:        "fake",
+		ClientID:    fakeOIDCClientID,
+		RedirectURL: "http://localhost/api/auth/oauth/fake/callback",
+		IssuerURL:   server.URL,
+	})
+}
+
+func TestOIDCProvider_AuthorizationCodeFlow(t *testing.T) {
+	ctx := context.Background()
+	provider := newFakeOIDCProvider(newFakeOIDCServer(t))
+	verifier := oauth2.GenerateVerifier()
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, "state-1", verifier, "nonce-1")
+	require.NoError(t, err)
+
+	code, state := authorizeWithFakeServer(t, authorizationURL)
+	assert.Equal(t, "state-1", state)
+
+	identity, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "external-user-1", identity.Subject)
+	assert.Equal(t, "oidc.user@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "OIDC User", identity.Name)
+}
+
+func TestOIDCProvider_RejectsWrongCodeVerifier(t *testing.T) {
+	ctx := context.Background()
+	provider := newFakeOIDCProvider(newFakeOIDCServer(t))
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, "state-1", oauth2.GenerateVerifier(), "nonce-1")
+	require.NoError(t, err)
+
+	code, _ := authorizeWithFakeServer(t, authorizationURL)
+
+	_, err = provider.Exchange(ctx, code, oauth2.GenerateVerifier(), "nonce-1")
+	assert.ErrorIs(t, err, dto.ErrOAuthExchange)
+}
+
+func TestOIDCProvider_RejectsNonceMismatch(t *testing.T) {
+	ctx := context.Background()
+	provider := newFakeOIDCProvider(newFakeOIDCServer(t))
+	verifier := oauth2.GenerateVerifier()
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, "state-1", verifier, "nonce-1")
+	require.NoError(t, err)
+
+	code, _ := authorizeWithFakeServer(t, authorizationURL)
+
+	_, err = provider.Exchange(ctx, code, verifier, "other-nonce")
+	assert.ErrorIs(t, err, dto.ErrOAuthNonceMismatch)
+}
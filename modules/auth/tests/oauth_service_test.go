@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	userRepo "github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// stubOAuthProvider returns the same identity for every code.
+type stubOAuthProvider struct {
+	identity service.OAuthIdentity
+}
+
+func (p stubOAuthProvider) Name() string { return "stub" }
+
+func (p stubOAuthProvider) AuthCodeURL(context.Context, string, string, string) (string, error) {
+	return "", nil
+}
+
+func (p stubOAuthProvider) Exchange(context.Context, string, string, string) (service.OAuthIdentity, error) {
+	return p.identity, nil
+}
+
+// stubOAuthRepository accepts any state and knows no linked identities.
+type stubOAuthRepository struct {
+	authRepo.OAuthRepository
+	linked []entities.UserIdentity
+}
+
+func (r *stubOAuthRepository) TakeState(context.Context, *gorm.DB, string) (entities.OAuthState, error) {
+	return entities.OAuthState{Provider: "stub", ExpiresAt: time.Now().Add(time.Minute)}, nil
+}
+
+func (r *stubOAuthRepository) FindIdentity(context.Context, *gorm.DB, string, string) (entities.UserIdentity, error) {
+	return entities.UserIdentity{}, gorm.ErrRecordNotFound
+}
+
+func (r *stubOAuthRepository) CreateIdentity(_ context.Context, _ *gorm.DB, identity entities.UserIdentity) (entities.UserIdentity, error) {
+	r.linked = append(r.linked, identity)
+	return identity, nil
+}
+
+type stubEmailUserRepository struct {
+	userRepo.UserRepository
+	user entities.User
+}
+
+func (r *stubEmailUserRepository) GetUserByEmail(_ context.Context, _ *gorm.DB, email string) (entities.User, error) {
+	if email != r.user.Email {
+		return entities.User{}, gorm.ErrRecordNotFound
+	}
+	return r.user, nil
+}
+
+type stubLoginService struct {
+	service.AuthService
+	user entities.User
+}
+
+func (s *stubLoginService) CompleteLogin(_ context.Context, user entities.User, _ dto.ClientInfo, _ string) (dto.TokenResponse, error) {
+	s.user = user
+	return dto.TokenResponse{AccessToken: "access-token"}, nil
+}
+
+func oauthCallback(t *testing.T, existing entities.User) (*stubOAuthRepository, *stubLoginService, error) {
+	return oauthCallbackWith(t, existing, dto.OAuthCallbackRequest{State: "state", Code: "code", BrowserState: "state"})
+}
+
+func oauthCallbackWith(t *testing.T, existing entities.User, req dto.OAuthCallbackRequest) (*stubOAuthRepository, *stubLoginService, error) {
+	// Transactions need a connection, but the stubs never run a statement.
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+
+	provider := stubOAuthProvider{identity: service.OAuthIdentity{
+		Subject:       "subject",
+		Email:         "owner@example.com",
+		EmailVerified: true,
+	}}
+	oauthRepository := &stubOAuthRepository{}
+	loginService := &stubLoginService{}
+	oauthService := service.NewOAuthService(
+		map[string]service.OAuthProvider{"stub": provider},
+		oauthRepository,
+		&stubEmailUserRepository{user: existing},
+		loginService,
+		nil,
+		db,
+	)
+
+	_, err = oauthService.Callback(context.Background(), "stub", req, dto.ClientInfo{})
+	return oauthRepository, loginService, err
+}
+
+func TestOAuthCallback_LinksVerifiedAccount(t *testing.T) {
+	existing := entities.User{ID: uuid.New(), Email: "owner@example.com", IsVerified: true}
+
+	oauthRepository, loginService, err := oauthCallback(t, existing)
+	require.NoError(t, err)
+	require.Len(t, oauthRepository.linked, 1)
+	assert.Equal(t, existing.ID, oauthRepository.linked[0].UserID)
+	assert.Equal(t, existing.ID, loginService.user.ID)
+}
+
+func TestOAuthCallback_RefusesUnverifiedAccount(t *testing.T) {
+	// Someone registered the owner's email with a password of their own.
+	existing := entities.User{ID: uuid.New(), Email: "owner@example.com", IsVerified: false}
+
+	oauthRepository, loginService, err := oauthCallback(t, existing)
+	assert.ErrorIs(t, err, dto.ErrOAuthAccountUnverified)
+	assert.Empty(t, oauthRepository.linked)
+	assert.Equal(t, uuid.Nil, loginService.user.ID)
+}
+
+func TestOAuthCallback_RejectsStateOfAnotherBrowser(t *testing.T) {
+	existing := entities.User{ID: uuid.New(), Email: "owner@example.com", IsVerified: true}
+
+	// The victim opens the attacker's callback: their browser holds another
+	// state, or none at all.
+	for _, browserState := range []string{"victim-state", ""} {
+		req := dto.OAuthCallbackRequest{State: "attacker-state", Code: "code", BrowserState: browserState}
+
+		oauthRepository, loginService, err := oauthCallbackWith(t, existing, req)
+		assert.ErrorIs(t, err, dto.ErrOAuthStateInvalid)
+		assert.Empty(t, oauthRepository.linked)
+		assert.Equal(t, uuid.Nil, loginService.user.ID)
+	}
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"gorm.io/gorm"
+)
+
+// memoryOneTimeTokenRepository keeps one-time tokens in memory so the real
+// OneTimeTokenService can run without a database.
+type memoryOneTimeTokenRepository struct {
+	authRepo.OneTimeTokenRepository
+	mu     sync.Mutex
+	tokens map[string]entities.OneTimeToken
+}
+
+func newMemoryOneTimeTokenRepository() *memoryOneTimeTokenRepository {
+	return &memoryOneTimeTokenRepository{tokens: make(map[string]entities.OneTimeToken)}
+}
+
+func (r *memoryOneTimeTokenRepository) Create(_ context.Context, _ *gorm.DB, token entities.OneTimeToken) (entities.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID.String()] = token
+	return token, nil
+}
+
+func (r *memoryOneTimeTokenRepository) FindByTokenHash(_ context.Context, _ *gorm.DB, tokenHash string, purpose string) (entities.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash && token.Purpose == purpose {
+			return token, nil
+		}
+	}
+	return entities.OneTimeToken{}, gorm.ErrRecordNotFound
+}
+
+func (r *memoryOneTimeTokenRepository) FindLatestByUserIDAndPurpose(_ context.Context, _ *gorm.DB, userID string, purpose string) (entities.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var latest entities.OneTimeToken
+	found := false
+	for _, token := range r.tokens {
+		if token.UserID.String() == userID && token.Purpose == purpose && (!found || token.CreatedAt.After(latest.CreatedAt)) {
+			latest, found = token, true
+		}
+	}
+	if !found {
+		return entities.OneTimeToken{}, gorm.ErrRecordNotFound
+	}
+	return latest, nil
+}
+
+func (r *memoryOneTimeTokenRepository) MarkConsumed(_ context.Context, _ *gorm.DB, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.ConsumedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.ConsumedAt = &now
+	r.tokens[id] = token
+	return true, nil
+}
+
+func (r *memoryOneTimeTokenRepository) DeleteByUserIDAndPurpose(_ context.Context, _ *gorm.DB, userID string, purposes ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		for _, purpose := range purposes {
+			if token.UserID.String() == userID && token.Purpose == purpose {
+				delete(r.tokens, id)
+			}
+		}
+	}
+	return nil
+}
+
+// expire moves the expiry of every token into the past.
+func (r *memoryOneTimeTokenRepository) expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		token.ExpiresAt = time.Now().Add(-time.Second)
+		r.tokens[id] = token
+	}
+}
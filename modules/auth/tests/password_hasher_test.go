@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cheapArgon2id keeps the tests fast; production parameters come from env.
+func cheapArgon2id() *helpers.Argon2idHasher {
+	return &helpers.Argon2idHasher{Memory: 1024, Time: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+}
+
+func TestArgon2idHasher_PHCFormat(t *testing.T) {
+	hash, err := cheapArgon2id().Hash("correct horse battery staple")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), hash)
+	assert.Len(t, strings.Split(hash, "$"), 6)
+}
+
+func TestNewArgon2idHasher_ReadsEnv(t *testing.T) {
+	hasher := helpers.NewArgon2idHasher()
+	assert.Equal(t, uint32(64*1024), hasher.Memory)
+	assert.Equal(t, uint32(3), hasher.Time)
+	assert.Equal(t, uint8(2), hasher.Parallelism)
+
+	t.Setenv("PASSWORD_ARGON2_MEMORY", "19456")
+	t.Setenv("PASSWORD_ARGON2_TIME", "2")
+	t.Setenv("PASSWORD_ARGON2_PARALLELISM", "1")
+
+	hasher = helpers.NewArgon2idHasher()
+	assert.Equal(t, uint32(19456), hasher.Memory)
+	assert.Equal(t, uint32(2), hasher.Time)
+	assert.Equal(t, uint8(1), hasher.Parallelism)
+}
+
+func TestCheckPassword_SupportsEveryAlgorithm(t *testing.T) {
+	password := "correct horse battery staple"
+
+	argonHash, err := cheapArgon2id().Hash(password)
+	require.NoError(t, err)
+	bcryptHash, err := (&helpers.BcryptHasher{Cost: 4}).Hash(password)
+	require.NoError(t, err)
+
+	for _, hash := range []string{argonHash, bcryptHash} {
+		valid, err := helpers.CheckPassword(hash, []byte(password))
+		require.NoError(t, err, hash)
+		assert.True(t, valid, hash)
+
+		valid, _ = helpers.CheckPassword(hash, []byte("wrong password"))
+		assert.False(t, valid, hash)
+	}
+
+	_, err = helpers.CheckPassword("plaintext", []byte(password))
+	assert.ErrorIs(t, err, helpers.ErrUnknownPasswordHash)
+}
+
+func TestArgon2idHasher_NeedsRehash(t *testing.T) {
+	hasher := cheapArgon2id()
+
+	current, err := hasher.Hash("password")
+	require.NoError(t, err)
+	assert.False(t, hasher.NeedsRehash(current))
+
+	weaker, err := (&helpers.Argon2idHasher{Memory: 512, Time: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}).Hash("password")
+	require.NoError(t, err)
+	assert.True(t, hasher.NeedsRehash(weaker))
+
+	legacy, err := (&helpers.BcryptHasher{Cost: 4}).Hash("password")
+	require.NoError(t, err)
+	assert.True(t, hasher.NeedsRehash(legacy))
+}
+
+func TestBcryptHasher_NeedsRehash(t *testing.T) {
+	hasher := &helpers.BcryptHasher{Cost: 5}
+
+	current, err := hasher.Hash("password")
+	require.NoError(t, err)
+	assert.False(t, hasher.NeedsRehash(current))
+
+	cheaper, err := (&helpers.BcryptHasher{Cost: 4}).Hash("password")
+	require.NoError(t, err)
+	assert.True(t, hasher.NeedsRehash(cheaper))
+
+	argonHash, err := cheapArgon2id().Hash("password")
+	require.NoError(t, err)
+	assert.True(t, hasher.NeedsRehash(argonHash))
+}
+
+func TestBcryptHasher_RejectsLongPasswords(t *testing.T) {
+	_, err := (&helpers.BcryptHasher{Cost: 4}).Hash(strings.Repeat("a", 73))
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_CheckRules_Success(t *testing.T) {
+	policy := service.PasswordPolicy{MinLength: 8, MaxLength: 64, RequireUpper: true, RequireDigit: true}
+
+	assert.NoError(t, policy.CheckRules("Correct7Horse", "Test User", "test@example.com"))
+}
+
+func TestPasswordPolicy_CheckRules_ReportsAllViolations(t *testing.T) {
+	policy := service.PasswordPolicy{MinLength: 8, RequireUpper: true, RequireDigit: true, RequireSymbol: true}
+
+	err := policy.CheckRules("short")
+
+	assert.ErrorIs(t, err, dto.ErrPasswordTooShort)
+	assert.ErrorIs(t, err, dto.ErrPasswordMissingUpper)
+	assert.ErrorIs(t, err, dto.ErrPasswordMissingDigit)
+	assert.ErrorIs(t, err, dto.ErrPasswordMissingSymbol)
+}
+
+func TestPasswordPolicy_CheckRules_RejectsPersonalValues(t *testing.T) {
+	policy := service.PasswordPolicy{MinLength: 8}
+
+	assert.ErrorIs(t, policy.CheckRules("IamJohnny2024", "John Doe", "jd@example.com"), dto.ErrPasswordContainsProfile)
+	assert.ErrorIs(t, policy.CheckRules("xx-jane.smith-xx", "Someone", "jane.smith@example.com"), dto.ErrPasswordContainsProfile)
+	assert.NoError(t, policy.CheckRules("unrelated-phrase", "Al Bo", "al@example.com"))
+}
+
+func TestBreachedPasswordChecker_RejectsCommonPasswords(t *testing.T) {
+	checker := service.NewBreachedPasswordChecker()
+
+	breached, err := checker.IsBreached("Password123")
+	require.NoError(t, err)
+	assert.True(t, breached)
+}
+
+func TestHashPrefixChecker_LooksUpRangeFile(t *testing.T) {
+	dir := t.TempDir()
+	sum := sha1.Sum([]byte("leaked-secret"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	content := "0000000000000000000000000000000000A:3\n" + hash[5:] + ":42\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(content), 0o600))
+
+	checker := service.NewHashPrefixChecker(dir)
+
+	breached, err := checker.IsBreached("leaked-secret")
+	require.NoError(t, err)
+	assert.True(t, breached)
+
+	breached, err = checker.IsBreached("never-leaked-secret")
+	require.NoError(t, err)
+	assert.False(t, breached)
+}
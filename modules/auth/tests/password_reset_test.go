@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	userRepo "github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// stubRefreshTokenRepository keeps refresh tokens in memory by hash.
+type stubRefreshTokenRepository struct {
+	authRepo.RefreshTokenRepository
+	tokens map[string]entities.RefreshToken
+}
+
+func (r *stubRefreshTokenRepository) FindByTokenHash(_ context.Context, _ *gorm.DB, tokenHash string) (entities.RefreshToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return entities.RefreshToken{}, gorm.ErrRecordNotFound
+	}
+	return token, nil
+}
+
+func (r *stubRefreshTokenRepository) DeleteByUserID(_ context.Context, _ *gorm.DB, userID string) error {
+	for hash, token := range r.tokens {
+		if token.UserID.String() == userID {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+func (r *stubRefreshTokenRepository) DeleteByFamilyID(_ context.Context, _ *gorm.DB, familyID string) error {
+	for hash, token := range r.tokens {
+		if token.FamilyID.String() == familyID {
+			delete(r.tokens, hash)
+		}
+	}
+	return nil
+}
+
+type stubSecurityEventRepository struct {
+	authRepo.SecurityEventRepository
+	events []entities.SecurityEvent
+}
+
+func (r *stubSecurityEventRepository) Create(_ context.Context, _ *gorm.DB, event entities.SecurityEvent) (entities.SecurityEvent, error) {
+	r.events = append(r.events, event)
+	return event, nil
+}
+
+type stubResetUserRepository struct {
+	userRepo.UserRepository
+	user entities.User
+}
+
+func (r *stubResetUserRepository) GetUserById(context.Context, *gorm.DB, string) (entities.User, error) {
+	return r.user, nil
+}
+
+func (r *stubResetUserRepository) Update(_ context.Context, _ *gorm.DB, user entities.User) (entities.User, error) {
+	r.user = user
+	return user, nil
+}
+
+// stubResetTokenService accepts "reset-token" once and records which
+// purposes were invalidated.
+type stubResetTokenService struct {
+	service.OneTimeTokenService
+	userID      uuid.UUID
+	invalidated []string
+}
+
+func (s *stubResetTokenService) Consume(_ context.Context, _ *gorm.DB, token string, purpose string) (entities.OneTimeToken, error) {
+	if token != "reset-token" || purpose != constants.TOKEN_PURPOSE_PASSWORD_RESET {
+		return entities.OneTimeToken{}, gorm.ErrRecordNotFound
+	}
+	return entities.OneTimeToken{UserID: s.userID, Purpose: purpose}, nil
+}
+
+func (s *stubResetTokenService) Invalidate(_ context.Context, _ *gorm.DB, _ string, purposes ...string) error {
+	s.invalidated = append(s.invalidated, purposes...)
+	return nil
+}
+
+type allowAllPasswordPolicy struct {
+	service.PasswordPolicyService
+}
+
+func (allowAllPasswordPolicy) Validate(context.Context, *gorm.DB, string, entities.User) error {
+	return nil
+}
+
+func (allowAllPasswordPolicy) Remember(context.Context, *gorm.DB, uuid.UUID, string) error {
+	return nil
+}
+
+func TestResetPassword_EndsExistingSessions(t *testing.T) {
+	ctx := context.Background()
+	jwtService, revocationService, _ := newTestAuthenticator(t)
+
+	// Transactions need a connection, but the stubs never run a statement.
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+
+	user := entities.User{ID: uuid.New(), Email: "owner@example.com", IsActive: true}
+	refreshToken, expiresAt := jwtService.GenerateRefreshToken()
+	refreshTokens := &stubRefreshTokenRepository{tokens: map[string]entities.RefreshToken{
+		jwtService.HashRefreshToken(refreshToken): {
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			FamilyID:  uuid.New(),
+			ExpiresAt: expiresAt,
+			User:      user,
+		},
+	}}
+	tokenService := &stubResetTokenService{userID: user.ID}
+
+	authService := service.NewAuthService(
+		&stubResetUserRepository{user: user},
+		refreshTokens,
+		tokenService,
+		nil,
+		nil,
+		allowAllPasswordPolicy{},
+		nil,
+		revocationService,
+		jwtService,
+		nil,
+		nil,
+		nil,
+		db,
+	)
+
+	accessToken := jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: user.ID.String(), Role: "user"})
+
+	require.NoError(t, authService.ResetPassword(ctx, dto.ResetPasswordRequest{Token: "reset-token", NewPassword: "a new password"}))
+	assert.ElementsMatch(t, []string{constants.TOKEN_PURPOSE_PASSWORD_RESET, constants.TOKEN_PURPOSE_MAGIC_LINK}, tokenService.invalidated)
+
+	// The refresh token obtained before the reset no longer works.
+	_, err = authService.RefreshToken(ctx, dto.RefreshTokenRequest{RefreshToken: refreshToken}, dto.ClientInfo{})
+	assert.ErrorIs(t, err, dto.ErrRefreshTokenNotFound)
+
+	claims, err := jwtService.GetClaimsByToken(accessToken)
+	require.NoError(t, err)
+	revoked, err := revocationService.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestRefreshToken_ReuseOfDisabledAccountRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	jwtService, revocationService, _ := newTestAuthenticator(t)
+
+	user := entities.User{ID: uuid.New(), Email: "owner@example.com", IsActive: false}
+	familyID := uuid.New()
+	rotatedAt := time.Now()
+	refreshToken, expiresAt := jwtService.GenerateRefreshToken()
+	current, _ := jwtService.GenerateRefreshToken()
+	refreshTokens := &stubRefreshTokenRepository{tokens: map[string]entities.RefreshToken{
+		jwtService.HashRefreshToken(refreshToken): {
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			FamilyID:  familyID,
+			ExpiresAt: expiresAt,
+			RotatedAt: &rotatedAt,
+			User:      user,
+		},
+		jwtService.HashRefreshToken(current): {
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			FamilyID:  familyID,
+			ExpiresAt: expiresAt,
+			User:      user,
+		},
+	}}
+	securityEvents := &stubSecurityEventRepository{}
+
+	authService := service.NewAuthService(
+		&stubResetUserRepository{user: user},
+		refreshTokens,
+		nil,
+		nil,
+		nil,
+		nil,
+		securityEvents,
+		revocationService,
+		jwtService,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+
+	_, err := authService.RefreshToken(ctx, dto.RefreshTokenRequest{RefreshToken: refreshToken}, dto.ClientInfo{})
+	assert.ErrorIs(t, err, dto.ErrRefreshTokenReused)
+	assert.Empty(t, refreshTokens.tokens)
+	require.Len(t, securityEvents.events, 1)
+	assert.Equal(t, constants.SECURITY_EVENT_REFRESH_TOKEN_REUSE, securityEvents.events[0].Type)
+}
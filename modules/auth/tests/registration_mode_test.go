@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	userRepo "github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// takenEmailUserRepository reports every email as registered, so Register
+// stops right after the registration mode was checked.
+type takenEmailUserRepository struct {
+	userRepo.UserRepository
+}
+
+func (takenEmailUserRepository) CheckEmail(context.Context, *gorm.DB, string) (entities.User, bool, error) {
+	return entities.User{}, true, nil
+}
+
+func register(t *testing.T, mode string, req userDto.UserCreateRequest) error {
+	t.Setenv("REGISTRATION_MODE", mode)
+
+	authService := service.NewAuthService(takenEmailUserRepository{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := authService.Register(context.Background(), req)
+	return err
+}
+
+func TestRegister_RegistrationModes(t *testing.T) {
+	req := userDto.UserCreateRequest{Name: "Owner", Email: "owner@example.com", Password: "a long password"}
+	invited := req
+	invited.InviteToken = "invite-token"
+
+	assert.ErrorIs(t, register(t, constants.REGISTRATION_MODE_OPEN, req), userDto.ErrEmailAlreadyExists)
+	assert.ErrorIs(t, register(t, "unknown", req), userDto.ErrEmailAlreadyExists)
+
+	assert.ErrorIs(t, register(t, constants.REGISTRATION_MODE_INVITE_ONLY, req), dto.ErrInvitationRequired)
+	assert.ErrorIs(t, register(t, constants.REGISTRATION_MODE_INVITE_ONLY, invited), userDto.ErrEmailAlreadyExists)
+
+	assert.ErrorIs(t, register(t, constants.REGISTRATION_MODE_DISABLED, req), dto.ErrRegistrationDisabled)
+	assert.ErrorIs(t, register(t, constants.REGISTRATION_MODE_DISABLED, invited), dto.ErrRegistrationDisabled)
+}
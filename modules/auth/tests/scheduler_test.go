@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule_Next(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2026, time.October, 14, 10, 7, 30, 0, time.UTC)
+
+	cases := map[string]time.Time{
+		"*/15 * * * *": time.Date(2026, time.October, 14, 10, 15, 0, 0, time.UTC),
+		"@hourly":      time.Date(2026, time.October, 14, 11, 0, 0, 0, time.UTC),
+		"30 3 * * *":   time.Date(2026, time.October, 15, 3, 30, 0, 0, time.UTC),
+		"0 9 * * 1-5":  time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC),
+		"0 0 * * 7":    time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+		"0 0 1 1 *":    time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 13 * 5":   time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC),
+		"@every 90s":   from.Add(90 * time.Second),
+	}
+
+	for spec, expected := range cases {
+		schedule, err := scheduler.ParseSchedule(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, expected, schedule.Next(from), spec)
+	}
+}
+
+func TestParseSchedule_NeverMatching(t *testing.T) {
+	schedule, err := scheduler.ParseSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "@every 1ms", "@often"} {
+		_, err := scheduler.ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	jobScheduler := scheduler.New(scheduler.NewLocalLocker())
+	require.NoError(t, jobScheduler.Register(scheduler.Job{
+		Name:     "slow",
+		Schedule: "@hourly",
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		},
+	}))
+
+	done := make(chan bool)
+	go func() {
+		ran, _ := jobScheduler.RunNow(context.Background(), "slow")
+		done <- ran
+	}()
+	<-started
+
+	ran, err := jobScheduler.RunNow(context.Background(), "slow")
+	require.NoError(t, err)
+	assert.False(t, ran)
+
+	close(release)
+	assert.True(t, <-done)
+}
+
+func TestScheduler_StopCancelsRunningJobs(t *testing.T) {
+	started := make(chan struct{})
+
+	jobScheduler := scheduler.New(scheduler.NewLocalLocker())
+	require.NoError(t, jobScheduler.Register(scheduler.Job{
+		Name:     "blocking",
+		Schedule: "@every 1s",
+		Run: func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}))
+	require.NoError(t, jobScheduler.Start())
+
+	select {
+	case <-started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job did not start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, jobScheduler.Stop(ctx))
+
+	assert.ErrorIs(t, jobScheduler.Register(scheduler.Job{Name: "late", Schedule: "@hourly"}), scheduler.ErrAlreadyStarted)
+}
+
+func TestScheduler_RejectsDuplicateJobs(t *testing.T) {
+	jobScheduler := scheduler.New(scheduler.NewLocalLocker())
+	job := scheduler.Job{Name: "job", Schedule: "@daily", Run: func(context.Context) error { return nil }}
+
+	require.NoError(t, jobScheduler.Register(job))
+	assert.ErrorIs(t, jobScheduler.Register(job), scheduler.ErrJobAlreadyExists)
+
+	_, err := jobScheduler.RunNow(context.Background(), "missing")
+	assert.ErrorIs(t, err, scheduler.ErrJobNotFound)
+}
@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthenticator(t *testing.T) (service.JWTService, service.TokenRevocationService, service.Authenticator) {
+	t.Setenv("JWT_ALGORITHM", "HS256")
+	t.Setenv("JWT_SECRET", "secret")
+
+	jwtService, err := service.NewJWTService()
+	require.NoError(t, err)
+
+	revocationService := service.NewTokenRevocationService(repository.NewMemoryRevocationStore())
+	return jwtService, revocationService, service.NewAuthenticator(jwtService, revocationService, nil, nil, service.NewAccountStatusService(newStubUserRepository(), nil))
+}
+
+func TestAuthenticator_RevokedToken(t *testing.T) {
+	ctx := context.Background()
+	jwtService, revocationService, authenticator := newTestAuthenticator(t)
+
+	token := jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user", SessionID: "session-id"})
+	principal, err := authenticator.AuthenticateAccessToken(ctx, token)
+	require.NoError(t, err)
+	assert.NotEmpty(t, principal.TokenID)
+
+	require.NoError(t, revocationService.RevokeToken(ctx, principal.TokenID, principal.ExpiresAt))
+
+	_, err = authenticator.AuthenticateAccessToken(ctx, token)
+	assert.ErrorIs(t, err, dto.ErrTokenRevoked)
+}
+
+func TestAuthenticator_RevokedSessionAndUser(t *testing.T) {
+	ctx := context.Background()
+	jwtService, revocationService, authenticator := newTestAuthenticator(t)
+
+	sessionToken := jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user", SessionID: "session-id"})
+	otherSessionToken := jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user", SessionID: "other-session"})
+
+	require.NoError(t, revocationService.RevokeSession(ctx, "session-id"))
+
+	_, err := authenticator.AuthenticateAccessToken(ctx, sessionToken)
+	assert.ErrorIs(t, err, dto.ErrTokenRevoked)
+
+	_, err = authenticator.AuthenticateAccessToken(ctx, otherSessionToken)
+	assert.NoError(t, err)
+
+	require.NoError(t, revocationService.RevokeUser(ctx, "user-id"))
+
+	_, err = authenticator.AuthenticateAccessToken(ctx, otherSessionToken)
+	assert.ErrorIs(t, err, dto.ErrTokenRevoked)
+}
+
+// fixedRevocationStore reports every key as revoked at the same instant.
+type fixedRevocationStore struct {
+	repository.RevocationStore
+	revokedAt time.Time
+}
+
+func (s fixedRevocationStore) Lookup(_ context.Context, keys ...string) (map[string]time.Time, error) {
+	revoked := make(map[string]time.Time, len(keys))
+	for _, key := range keys {
+		revoked[key] = s.revokedAt
+	}
+	return revoked, nil
+}
+
+func TestTokenRevocation_SameSecondAsRevokeUser(t *testing.T) {
+	ctx := context.Background()
+	second := time.Now().Truncate(time.Second)
+
+	for _, revokedAt := range []time.Time{second, second.Add(time.Millisecond * 999)} {
+		revocationService := service.NewTokenRevocationService(fixedRevocationStore{revokedAt: revokedAt})
+
+		sameSecond := &service.JWTCustomClaim{UserID: "user-id"}
+		sameSecond.IssuedAt = jwt.NewNumericDate(second)
+		revoked, err := revocationService.IsRevoked(ctx, sameSecond)
+		require.NoError(t, err)
+		assert.True(t, revoked, revokedAt)
+
+		nextSecond := &service.JWTCustomClaim{UserID: "user-id"}
+		nextSecond.IssuedAt = jwt.NewNumericDate(second.Add(time.Second))
+		revoked, err = revocationService.IsRevoked(ctx, nextSecond)
+		require.NoError(t, err)
+		assert.False(t, revoked, revokedAt)
+	}
+}
+
+func TestMemoryRevocationStore_Expires(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryRevocationStore()
+
+	require.NoError(t, store.Revoke(ctx, "expired", time.Now().Add(-time.Second)))
+	require.NoError(t, store.Revoke(ctx, "active", time.Now().Add(time.Minute)))
+
+	revoked, err := store.Lookup(ctx, "expired", "active", "unknown")
+	require.NoError(t, err)
+	assert.Len(t, revoked, 1)
+	assert.Contains(t, revoked, "active")
+}
+
+func TestAuthenticator_ImpersonationToken(t *testing.T) {
+	ctx := context.Background()
+	jwtService, _, authenticator := newTestAuthenticator(t)
+
+	token := jwtService.GenerateAccessToken(service.AccessTokenSubject{
+		UserID:    "user-id",
+		Role:      "user",
+		ActorID:   "admin-id",
+		ExpiresIn: time.Minute,
+	})
+	principal, err := authenticator.AuthenticateAccessToken(ctx, token)
+	require.NoError(t, err)
+
+	assert.Equal(t, "user-id", principal.UserID)
+	assert.Equal(t, "admin-id", principal.ImpersonatorID)
+	assert.Empty(t, principal.SessionID)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), principal.ExpiresAt, 5*time.Second)
+}
+
+func TestAuthenticator_PermissionsClaim(t *testing.T) {
+	ctx := context.Background()
+	jwtService, _, authenticator := newTestAuthenticator(t)
+
+	token := jwtService.GenerateAccessToken(service.AccessTokenSubject{
+		UserID:      "user-id",
+		Role:        "support",
+		Permissions: []string{"users:read"},
+	})
+	principal, err := authenticator.AuthenticateAccessToken(ctx, token)
+	require.NoError(t, err)
+	assert.True(t, principal.HasPermission("users:read"))
+	assert.False(t, principal.HasPermission("users:write"))
+
+	// Tokens issued before permissions were embedded keep their role's.
+	legacyToken := jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "admin"})
+	principal, err = authenticator.AuthenticateAccessToken(ctx, legacyToken)
+	require.NoError(t, err)
+	assert.True(t, principal.HasPermission("users:write"))
+}
+
+func TestAuthenticator_OrganizationClaim(t *testing.T) {
+	ctx := context.Background()
+	jwtService, _, authenticator := newTestAuthenticator(t)
+
+	token := jwtService.GenerateAccessToken(service.AccessTokenSubject{
+		UserID:           "user-id",
+		Role:             "user",
+		OrganizationID:   "org-id",
+		OrganizationRole: "admin",
+	})
+	principal, err := authenticator.AuthenticateAccessToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "org-id", principal.OrganizationID)
+	assert.True(t, principal.HasOrganizationRole("owner", "admin"))
+
+	token = jwtService.GenerateAccessToken(service.AccessTokenSubject{UserID: "user-id", Role: "user"})
+	principal, err = authenticator.AuthenticateAccessToken(ctx, token)
+	require.NoError(t, err)
+	assert.Empty(t, principal.OrganizationID)
+	assert.False(t, principal.HasOrganizationRole("member"))
+}
@@ -0,0 +1,28 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateRandomToken_Unique(t *testing.T) {
+	first, err := helpers.GenerateRandomToken(32)
+	assert.NoError(t, err)
+
+	second, err := helpers.GenerateRandomToken(32)
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Len(t, first, 43)
+}
+
+func TestHMACToken_DependsOnKey(t *testing.T) {
+	token := "refresh-token"
+
+	assert.Equal(t, helpers.HMACToken(token, "key"), helpers.HMACToken(token, "key"))
+	assert.NotEqual(t, helpers.HMACToken(token, "key"), helpers.HMACToken(token, "other-key"))
+	assert.NotEqual(t, helpers.HashToken(token), helpers.HMACToken(token, "key"))
+	assert.Len(t, helpers.HMACToken(token, "key"), 64)
+}
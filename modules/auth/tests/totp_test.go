@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Base32 form of the RFC 6238 SHA-1 test seed "12345678901234567890".
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := helpers.TOTPCode(rfc6238Secret, helpers.TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidateTOTP_Skew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	previous, err := helpers.TOTPCode(rfc6238Secret, helpers.TOTPStep(now)-1)
+	require.NoError(t, err)
+
+	step, ok := helpers.ValidateTOTP(rfc6238Secret, previous, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, helpers.TOTPStep(now)-1, step)
+
+	_, ok = helpers.ValidateTOTP(rfc6238Secret, previous, now, 0)
+	assert.False(t, ok)
+
+	_, ok = helpers.ValidateTOTP(rfc6238Secret, "12345", now, 1)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := helpers.TOTPURI("Template", "user@example.com", rfc6238Secret)
+
+	assert.Contains(t, uri, "otpauth://totp/Template:user@example.com?")
+	assert.Contains(t, uri, "secret="+rfc6238Secret)
+	assert.Contains(t, uri, "issuer=Template")
+}
+
+func TestAESGCM_RoundTrip(t *testing.T) {
+	key := make([]byte, 32)
+
+	encrypted, err := helpers.EncryptAESGCM(rfc6238Secret, key)
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, rfc6238Secret)
+
+	decrypted, err := helpers.DecryptAESGCM(encrypted, key)
+	require.NoError(t, err)
+	assert.Equal(t, rfc6238Secret, decrypted)
+
+	key[0] = 1
+	_, err = helpers.DecryptAESGCM(encrypted, key)
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeUserAgent(t *testing.T) {
+	cases := map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36": "Chrome on Windows",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Version/17.0 Safari/604.1":       "Safari on iOS",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0":                                      "Firefox on Linux",
+		"curl/8.4.0": "curl",
+		"":           "Unknown device",
+	}
+
+	for userAgent, expected := range cases {
+		assert.Equal(t, expected, helpers.DescribeUserAgent(userAgent))
+	}
+}
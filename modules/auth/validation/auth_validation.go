@@ -2,6 +2,7 @@ package validation
 
 import (
 	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
 	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
 	"github.com/go-playground/validator/v10"
 )
@@ -50,13 +51,10 @@ func (v *AuthValidation) ValidateVerifyEmailRequest(req userDto.VerifyEmailReque
 }
 
 // Custom validators
+// validatePassword applies the configured length and character class rules.
+// Checks needing the user or the database run in PasswordPolicyService.
 func validatePassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-	if len(password) < 8 {
-		return false
-	}
-	// Add more password validation rules as needed
-	return true
+	return service.NewPasswordPolicy().CheckRules(fl.Field().String()) == nil
 }
 
 func validateEmail(fl validator.FieldLevel) bool {
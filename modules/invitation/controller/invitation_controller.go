@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation/query"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation/service"
+	rbacDto "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/dto"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/Caknoooo/go-pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+)
+
+type (
+	InvitationController interface {
+		GetInvitations(ctx *gin.Context)
+		CreateInvitation(ctx *gin.Context)
+		ResendInvitation(ctx *gin.Context)
+		RevokeInvitation(ctx *gin.Context)
+	}
+
+	invitationController struct {
+		invitationService service.InvitationService
+		db                *gorm.DB
+	}
+)
+
+func NewInvitationController(injector *do.Injector, is service.InvitationService) InvitationController {
+	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
+	return &invitationController{
+		invitationService: is,
+		db:                db,
+	}
+}
+
+// errorStatus maps the errors of InvitationService to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrInvitationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, userDto.ErrEmailAlreadyExists), errors.Is(err, dto.ErrInvitationNotPending):
+		return http.StatusConflict
+	case errors.Is(err, rbacDto.ErrGrantDenied):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (c *invitationController) GetInvitations(ctx *gin.Context) {
+	var filter = &query.InvitationFilter{}
+	filter.BindPagination(ctx)
+
+	ctx.ShouldBindQuery(filter)
+
+	invitations, total, err := pagination.PaginatedQueryWithIncludable[query.Invitation](c.db, filter)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_INVITATIONS, err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	paginationResponse := pagination.CalculatePagination(filter.Pagination, total)
+	response := pagination.NewPaginatedResponse(http.StatusOK, dto.MESSAGE_SUCCESS_GET_INVITATIONS, invitations, paginationResponse)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *invitationController) CreateInvitation(ctx *gin.Context) {
+	var req dto.InvitationCreateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	result, err := c.invitationService.Create(ctx.Request.Context(), principal, req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_INVITATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_INVITATION, result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *invitationController) ResendInvitation(ctx *gin.Context) {
+	result, err := c.invitationService.Resend(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_RESEND_INVITATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_RESEND_INVITATION, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *invitationController) RevokeInvitation(ctx *gin.Context) {
+	if err := c.invitationService.Revoke(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REVOKE_INVITATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REVOKE_INVITATION, nil)
+	ctx.JSON(http.StatusOK, res)
+}
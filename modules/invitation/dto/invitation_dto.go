@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
+
+	MESSAGE_FAILED_GET_INVITATIONS    = "failed get invitations"
+	MESSAGE_FAILED_CREATE_INVITATION  = "failed create invitation"
+	MESSAGE_FAILED_RESEND_INVITATION  = "failed resend invitation"
+	MESSAGE_FAILED_REVOKE_INVITATION  = "failed revoke invitation"
+	MESSAGE_SUCCESS_GET_INVITATIONS   = "success get invitations"
+	MESSAGE_SUCCESS_CREATE_INVITATION = "success create invitation"
+	MESSAGE_SUCCESS_RESEND_INVITATION = "success resend invitation"
+	MESSAGE_SUCCESS_REVOKE_INVITATION = "success revoke invitation"
+)
+
+const (
+	INVITATION_STATUS_PENDING  = "pending"
+	INVITATION_STATUS_ACCEPTED = "accepted"
+	INVITATION_STATUS_REVOKED  = "revoked"
+	INVITATION_STATUS_EXPIRED  = "expired"
+)
+
+var (
+	ErrInvitationNotFound      = errors.New("invitation not found")
+	ErrInvitationInvalid       = errors.New("invitation invalid, expired or already used")
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to another email")
+	ErrInvitationNotPending    = errors.New("invitation was already accepted or revoked")
+)
+
+type (
+	InvitationCreateRequest struct {
+		Email string `json:"email" binding:"required,email"`
+		Role  string `json:"role" binding:"omitempty,max=50"`
+	}
+
+	InvitationResponse struct {
+		ID          string     `json:"id"`
+		Email       string     `json:"email"`
+		Role        string     `json:"role"`
+		Status      string     `json:"status"`
+		InvitedByID string     `json:"invited_by_id,omitempty"`
+		ExpiresAt   time.Time  `json:"expires_at"`
+		AcceptedAt  *time.Time `json:"accepted_at"`
+		CreatedAt   time.Time  `json:"created_at"`
+	}
+)
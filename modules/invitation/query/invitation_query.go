@@ -0,0 +1,68 @@
+package query
+
+import (
+	"time"
+
+	"github.com/Caknoooo/go-pagination"
+	"gorm.io/gorm"
+)
+
+type Invitation struct {
+	ID          string     `json:"id"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	InvitedByID *string    `json:"invited_by_id"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type InvitationFilter struct {
+	pagination.BaseFilter
+
+	// Pending only lists invitations that can still be accepted.
+	Pending bool `form:"pending"`
+}
+
+func (f *InvitationFilter) ApplyFilters(query *gorm.DB) *gorm.DB {
+	if f.Pending {
+		return query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", time.Now())
+	}
+	return query
+}
+
+func (f *InvitationFilter) GetTableName() string {
+	return "invitations"
+}
+
+func (f *InvitationFilter) GetSearchFields() []string {
+	return []string{"email"}
+}
+
+func (f *InvitationFilter) GetDefaultSort() string {
+	return "created_at desc"
+}
+
+func (f *InvitationFilter) GetIncludes() []string {
+	return f.Includes
+}
+
+func (f *InvitationFilter) GetPagination() pagination.PaginationRequest {
+	return f.Pagination
+}
+
+func (f *InvitationFilter) Validate() {
+	var validIncludes []string
+	allowedIncludes := f.GetAllowedIncludes()
+	for _, include := range f.Includes {
+		if allowedIncludes[include] {
+			validIncludes = append(validIncludes, include)
+		}
+	}
+	f.Includes = validIncludes
+}
+
+func (f *InvitationFilter) GetAllowedIncludes() map[string]bool {
+	return map[string]bool{}
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, invitation entities.Invitation) (entities.Invitation, error)
+	FindByID(ctx context.Context, tx *gorm.DB, id string) (entities.Invitation, error)
+	FindByTokenHash(ctx context.Context, tx *gorm.DB, tokenHash string) (entities.Invitation, error)
+	FindPendingByEmail(ctx context.Context, tx *gorm.DB, email string) (entities.Invitation, error)
+	UpdateToken(ctx context.Context, tx *gorm.DB, id string, tokenHash string, expiresAt time.Time) (bool, error)
+	MarkAccepted(ctx context.Context, tx *gorm.DB, id string, userID uuid.UUID) (bool, error)
+	Revoke(ctx context.Context, tx *gorm.DB, id string) (bool, error)
+	RevokePendingByEmail(ctx context.Context, tx *gorm.DB, email string) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{
+		db: db,
+	}
+}
+
+// pending limits a query to invitations that have been neither accepted nor
+// revoked. Expiry is checked separately so callers can tell it apart.
+func pending(tx *gorm.DB) *gorm.DB {
+	return tx.Where("accepted_at IS NULL AND revoked_at IS NULL")
+}
+
+func (r *invitationRepository) Create(ctx context.Context, tx *gorm.DB, invitation entities.Invitation) (entities.Invitation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&invitation).Error; err != nil {
+		return entities.Invitation{}, err
+	}
+
+	return invitation, nil
+}
+
+func (r *invitationRepository) FindByID(ctx context.Context, tx *gorm.DB, id string) (entities.Invitation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var invitation entities.Invitation
+	if err := tx.WithContext(ctx).Where("id = ?", id).Take(&invitation).Error; err != nil {
+		return entities.Invitation{}, err
+	}
+
+	return invitation, nil
+}
+
+func (r *invitationRepository) FindByTokenHash(ctx context.Context, tx *gorm.DB, tokenHash string) (entities.Invitation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var invitation entities.Invitation
+	if err := tx.WithContext(ctx).Where("token_hash = ?", tokenHash).Take(&invitation).Error; err != nil {
+		return entities.Invitation{}, err
+	}
+
+	return invitation, nil
+}
+
+// FindPendingByEmail returns the newest unexpired invitation for the email.
+func (r *invitationRepository) FindPendingByEmail(ctx context.Context, tx *gorm.DB, email string) (entities.Invitation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var invitation entities.Invitation
+	if err := pending(tx.WithContext(ctx)).
+		Where("LOWER(email) = LOWER(?) AND expires_at > ?", email, time.Now()).
+		Order("created_at DESC").
+		Take(&invitation).Error; err != nil {
+		return entities.Invitation{}, err
+	}
+
+	return invitation, nil
+}
+
+// UpdateToken replaces the token of a pending invitation, invalidating the
+// previously sent one.
+func (r *invitationRepository) UpdateToken(
+	ctx context.Context,
+	tx *gorm.DB,
+	id string,
+	tokenHash string,
+	expiresAt time.Time,
+) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := pending(tx.WithContext(ctx).Model(&entities.Invitation{})).
+		Where("id = ?", id).
+		Updates(map[string]any{"token_hash": tokenHash, "expires_at": expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// MarkAccepted reports false when the invitation was accepted or revoked
+// concurrently, so each invitation creates at most one account.
+func (r *invitationRepository) MarkAccepted(ctx context.Context, tx *gorm.DB, id string, userID uuid.UUID) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := pending(tx.WithContext(ctx).Model(&entities.Invitation{})).
+		Where("id = ?", id).
+		Updates(map[string]any{"accepted_at": time.Now(), "accepted_user_id": userID})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Revoke reports false when there is no pending invitation with the id.
+func (r *invitationRepository) Revoke(ctx context.Context, tx *gorm.DB, id string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := pending(tx.WithContext(ctx).Model(&entities.Invitation{})).
+		Where("id = ?", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *invitationRepository) RevokePendingByEmail(ctx context.Context, tx *gorm.DB, email string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return pending(tx.WithContext(ctx).Model(&entities.Invitation{})).
+		Where("LOWER(email) = LOWER(?)", email).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,26 @@
+package invitation
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+)
+
+func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
+	invitationController := do.MustInvoke[controller.InvitationController](injector)
+	authenticator := do.MustInvokeNamed[service.Authenticator](injector, constants.Authenticator)
+
+	read := middlewares.RequirePermission(constants.PERMISSION_INVITATION_READ)
+	write := middlewares.RequirePermission(constants.PERMISSION_INVITATION_WRITE)
+
+	invitationRoutes := server.Group("/api/invitations", middlewares.Authenticate(authenticator))
+	{
+		invitationRoutes.GET("", read, invitationController.GetInvitations)
+		invitationRoutes.POST("", write, invitationController.CreateInvitation)
+		invitationRoutes.POST("/:id/resend", write, invitationController.ResendInvitation)
+		invitationRoutes.DELETE("/:id", write, invitationController.RevokeInvitation)
+	}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation/repository"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	userRepo "github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InvitationService manages invitations and lets registration redeem them.
+// Find and Accept take the caller's transaction so that the account and the
+// accepted invitation are stored together.
+type InvitationService interface {
+	Create(ctx context.Context, inviter authDto.Principal, req dto.InvitationCreateRequest) (dto.InvitationResponse, error)
+	Resend(ctx context.Context, invitationId string) (dto.InvitationResponse, error)
+	Revoke(ctx context.Context, invitationId string) error
+	Find(ctx context.Context, tx *gorm.DB, token string, email string) (entities.Invitation, error)
+	FindForEmail(ctx context.Context, tx *gorm.DB, email string) (entities.Invitation, bool, error)
+	Accept(ctx context.Context, tx *gorm.DB, invitation entities.Invitation, userId uuid.UUID) error
+}
+
+const defaultInvitationTTL = time.Hour * 24 * 7
+
+type invitationService struct {
+	invitationRepository repository.InvitationRepository
+	userRepository       userRepo.UserRepository
+	rbacService          rbacService.RBACService
+	ttl                  time.Duration
+	invitationURL        string
+	db                   *gorm.DB
+}
+
+// NewInvitationService reads how long invitations stay valid from
+// INVITATION_TTL and the registration page linked in the email from
+// INVITATION_URL. Without a URL the email only contains the token.
+func NewInvitationService(
+	invitationRepo repository.InvitationRepository,
+	userRepo userRepo.UserRepository,
+	rbacService rbacService.RBACService,
+	db *gorm.DB,
+) InvitationService {
+	ttl := helpers.GetDurationEnv("INVITATION_TTL", defaultInvitationTTL)
+
+	return &invitationService{
+		invitationRepository: invitationRepo,
+		userRepository:       userRepo,
+		rbacService:          rbacService,
+		ttl:                  ttl,
+		invitationURL:        os.Getenv("INVITATION_URL"),
+		db:                   db,
+	}
+}
+
+func toInvitationResponse(invitation entities.Invitation) dto.InvitationResponse {
+	response := dto.InvitationResponse{
+		ID:         invitation.ID.String(),
+		Email:      invitation.Email,
+		Role:       invitation.Role,
+		Status:     invitationStatus(invitation),
+		ExpiresAt:  invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+	if invitation.InvitedByID != nil {
+		response.InvitedByID = invitation.InvitedByID.String()
+	}
+
+	return response
+}
+
+func invitationStatus(invitation entities.Invitation) string {
+	switch {
+	case invitation.AcceptedAt != nil:
+		return dto.INVITATION_STATUS_ACCEPTED
+	case invitation.RevokedAt != nil:
+		return dto.INVITATION_STATUS_REVOKED
+	case !invitation.ExpiresAt.After(time.Now()):
+		return dto.INVITATION_STATUS_EXPIRED
+	default:
+		return dto.INVITATION_STATUS_PENDING
+	}
+}
+
+// Create invites the email with the requested role, replacing any invitation
+// to it that is still pending. The inviter must hold every permission of the
+// role.
+func (s *invitationService) Create(
+	ctx context.Context,
+	inviter authDto.Principal,
+	req dto.InvitationCreateRequest,
+) (dto.InvitationResponse, error) {
+	role := req.Role
+	if role == "" {
+		role = constants.ENUM_ROLE_USER
+	}
+
+	inviterId, err := uuid.Parse(inviter.UserID)
+	if err != nil {
+		return dto.InvitationResponse{}, authDto.ErrPrincipalNotFound
+	}
+
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	var invitation entities.Invitation
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.rbacService.CheckGrant(ctx, tx, inviter, role); err != nil {
+			return err
+		}
+
+		_, isExist, err := s.userRepository.CheckEmail(ctx, tx, req.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if isExist {
+			return userDto.ErrEmailAlreadyExists
+		}
+
+		if err := s.invitationRepository.RevokePendingByEmail(ctx, tx, req.Email); err != nil {
+			return err
+		}
+
+		invitation, err = s.invitationRepository.Create(ctx, tx, entities.Invitation{
+			ID:          uuid.New(),
+			Email:       req.Email,
+			Role:        role,
+			TokenHash:   helpers.HashToken(token),
+			InvitedByID: &inviterId,
+			ExpiresAt:   time.Now().Add(s.ttl),
+		})
+		return err
+	})
+	if err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	if err := s.sendInvitationEmail(invitation, token); err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	return toInvitationResponse(invitation), nil
+}
+
+// Resend emails a fresh token for a pending invitation and restarts its
+// validity period. The previously sent token stops working.
+func (s *invitationService) Resend(ctx context.Context, invitationId string) (dto.InvitationResponse, error) {
+	invitation, err := s.getInvitation(ctx, invitationId)
+	if err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	invitation.TokenHash = helpers.HashToken(token)
+	invitation.ExpiresAt = time.Now().Add(s.ttl)
+
+	updated, err := s.invitationRepository.UpdateToken(ctx, s.db, invitationId, invitation.TokenHash, invitation.ExpiresAt)
+	if err != nil {
+		return dto.InvitationResponse{}, err
+	}
+	if !updated {
+		return dto.InvitationResponse{}, dto.ErrInvitationNotPending
+	}
+
+	if err := s.sendInvitationEmail(invitation, token); err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	return toInvitationResponse(invitation), nil
+}
+
+func (s *invitationService) Revoke(ctx context.Context, invitationId string) error {
+	if _, err := s.getInvitation(ctx, invitationId); err != nil {
+		return err
+	}
+
+	revoked, err := s.invitationRepository.Revoke(ctx, s.db, invitationId)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return dto.ErrInvitationNotPending
+	}
+
+	return nil
+}
+
+// Find returns the pending, unexpired invitation for the token. It must have
+// been sent to the email being registered.
+func (s *invitationService) Find(ctx context.Context, tx *gorm.DB, token string, email string) (entities.Invitation, error) {
+	invitation, err := s.invitationRepository.FindByTokenHash(ctx, tx, helpers.HashToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Invitation{}, dto.ErrInvitationInvalid
+	}
+	if err != nil {
+		return entities.Invitation{}, err
+	}
+
+	if invitationStatus(invitation) != dto.INVITATION_STATUS_PENDING {
+		return entities.Invitation{}, dto.ErrInvitationInvalid
+	}
+	if !strings.EqualFold(invitation.Email, email) {
+		return entities.Invitation{}, dto.ErrInvitationEmailMismatch
+	}
+
+	return invitation, nil
+}
+
+// FindForEmail looks up a pending invitation without a token, for sign-ups
+// where the email is already proven, such as a verified external login.
+func (s *invitationService) FindForEmail(ctx context.Context, tx *gorm.DB, email string) (entities.Invitation, bool, error) {
+	invitation, err := s.invitationRepository.FindPendingByEmail(ctx, tx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Invitation{}, false, nil
+	}
+	if err != nil {
+		return entities.Invitation{}, false, err
+	}
+
+	return invitation, true, nil
+}
+
+// Accept records the account created from the invitation. It fails if the
+// invitation was used or revoked in the meantime.
+func (s *invitationService) Accept(ctx context.Context, tx *gorm.DB, invitation entities.Invitation, userId uuid.UUID) error {
+	accepted, err := s.invitationRepository.MarkAccepted(ctx, tx, invitation.ID.String(), userId)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return dto.ErrInvitationInvalid
+	}
+
+	return nil
+}
+
+// getInvitation looks an invitation up by id, treating malformed ids as
+// unknown invitations.
+func (s *invitationService) getInvitation(ctx context.Context, invitationId string) (entities.Invitation, error) {
+	if _, err := uuid.Parse(invitationId); err != nil {
+		return entities.Invitation{}, dto.ErrInvitationNotFound
+	}
+
+	invitation, err := s.invitationRepository.FindByID(ctx, s.db, invitationId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Invitation{}, dto.ErrInvitationNotFound
+	}
+
+	return invitation, err
+}
+
+func (s *invitationService) sendInvitationEmail(invitation entities.Invitation, token string) error {
+	subject := "You Are Invited"
+	body := "You have been invited to create an account. Register with this invitation token: " + token
+	if s.invitationURL != "" {
+		link := s.invitationURL + "?token=" + url.QueryEscape(token)
+		body = "You have been invited to create an account. Click <a href=\"" + link + "\">here</a> to register. The invitation expires on " +
+			invitation.ExpiresAt.Format(time.RFC1123) + "."
+	}
+
+	return utils.SendMail(invitation.Email, subject, body)
+}
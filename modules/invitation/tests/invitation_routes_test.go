@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/invitation/service"
+	rbacDto "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// stubAuthenticator treats every bearer token as the name of a role.
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) AuthenticateAccessToken(_ context.Context, token string) (authDto.Principal, error) {
+	return authDto.NewPrincipal("actor-id", token), nil
+}
+
+func (stubAuthenticator) AuthenticateAPIKey(context.Context, string, string) (authDto.Principal, error) {
+	return authDto.Principal{}, authDto.ErrAPIKeyInvalid
+}
+
+type stubInvitationService struct {
+	service.InvitationService
+	inviter authDto.Principal
+}
+
+func (s *stubInvitationService) Create(_ context.Context, inviter authDto.Principal, req dto.InvitationCreateRequest) (dto.InvitationResponse, error) {
+	s.inviter = inviter
+	switch req.Role {
+	case constants.ENUM_ROLE_ADMIN:
+		return dto.InvitationResponse{}, rbacDto.ErrGrantDenied
+	case "owner":
+		return dto.InvitationResponse{}, rbacDto.ErrRoleNotFound
+	}
+	return dto.InvitationResponse{Email: req.Email, Role: req.Role}, nil
+}
+
+func (s *stubInvitationService) Revoke(_ context.Context, invitationId string) error {
+	switch invitationId {
+	case "pending":
+		return nil
+	case "accepted":
+		return dto.ErrInvitationNotPending
+	default:
+		return dto.ErrInvitationNotFound
+	}
+}
+
+func newInvitationRouter(invitationService service.InvitationService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	injector := do.New()
+	do.ProvideNamedValue[*gorm.DB](injector, constants.DB, nil)
+	do.ProvideNamedValue[authService.Authenticator](injector, constants.Authenticator, stubAuthenticator{})
+	do.Provide(injector, func(i *do.Injector) (controller.InvitationController, error) {
+		return controller.NewInvitationController(i, invitationService), nil
+	})
+
+	router := gin.New()
+	invitation.RegisterRoutes(router, injector)
+	return router
+}
+
+func request(router *gin.Engine, method string, path string, role string, body string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+role)
+	req.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestInvitationRoutes_RequirePermission(t *testing.T) {
+	router := newInvitationRouter(&stubInvitationService{})
+
+	body := `{"email":"new@example.com"}`
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodPost, "/api/invitations", constants.ENUM_ROLE_USER, body))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodDelete, "/api/invitations/pending", constants.ENUM_ROLE_USER, ""))
+}
+
+func TestInvitationRoutes_Create(t *testing.T) {
+	invitationService := &stubInvitationService{}
+	router := newInvitationRouter(invitationService)
+
+	assert.Equal(t, http.StatusCreated, request(router, http.MethodPost, "/api/invitations", constants.ENUM_ROLE_ADMIN, `{"email":"new@example.com","role":"user"}`))
+	assert.Equal(t, "actor-id", invitationService.inviter.UserID)
+
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodPost, "/api/invitations", constants.ENUM_ROLE_ADMIN, `{"email":"new@example.com","role":"admin"}`))
+	assert.Equal(t, http.StatusBadRequest, request(router, http.MethodPost, "/api/invitations", constants.ENUM_ROLE_ADMIN, `{"email":"new@example.com","role":"owner"}`))
+}
+
+func TestInvitationRoutes_Revoke(t *testing.T) {
+	router := newInvitationRouter(&stubInvitationService{})
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodDelete, "/api/invitations/pending", constants.ENUM_ROLE_ADMIN, ""))
+	assert.Equal(t, http.StatusConflict, request(router, http.MethodDelete, "/api/invitations/accepted", constants.ENUM_ROLE_ADMIN, ""))
+	assert.Equal(t, http.StatusNotFound, request(router, http.MethodDelete, "/api/invitations/unknown", constants.ENUM_ROLE_ADMIN, ""))
+}
@@ -0,0 +1,261 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	OrganizationController interface {
+		GetOrganizations(ctx *gin.Context)
+		CreateOrganization(ctx *gin.Context)
+		GetOrganization(ctx *gin.Context)
+		UpdateOrganization(ctx *gin.Context)
+		DeleteOrganization(ctx *gin.Context)
+		GetMembers(ctx *gin.Context)
+		UpdateMember(ctx *gin.Context)
+		RemoveMember(ctx *gin.Context)
+		GetInvitations(ctx *gin.Context)
+		CreateInvitation(ctx *gin.Context)
+		RevokeInvitation(ctx *gin.Context)
+		AcceptInvitation(ctx *gin.Context)
+	}
+
+	organizationController struct {
+		organizationService service.OrganizationService
+		invitationService   service.OrganizationInvitationService
+	}
+)
+
+func NewOrganizationController(
+	os service.OrganizationService,
+	is service.OrganizationInvitationService,
+) OrganizationController {
+	return &organizationController{
+		organizationService: os,
+		invitationService:   is,
+	}
+}
+
+// errorStatus maps the errors of the organization services to HTTP status
+// codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrOrganizationNotFound), errors.Is(err, dto.ErrMemberNotFound),
+		errors.Is(err, dto.ErrInvitationNotFound), errors.Is(err, userDto.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrAlreadyMember), errors.Is(err, dto.ErrLastOwner), errors.Is(err, dto.ErrInvitationNotPending):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrOrganizationRoleDenied), errors.Is(err, dto.ErrInvitationEmailMismatch),
+		errors.Is(err, dto.ErrInvitationEmailUnverified):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (c *organizationController) GetOrganizations(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.organizationService.List(ctx.Request.Context(), userId)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_ORGANIZATIONS, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_ORGANIZATIONS, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *organizationController) CreateOrganization(ctx *gin.Context) {
+	var req dto.OrganizationCreateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.organizationService.Create(ctx.Request.Context(), userId, req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_ORGANIZATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_ORGANIZATION, result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *organizationController) GetOrganization(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.organizationService.Get(ctx.Request.Context(), userId, ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_ORGANIZATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_ORGANIZATION, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *organizationController) UpdateOrganization(ctx *gin.Context) {
+	var req dto.OrganizationUpdateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.organizationService.Update(ctx.Request.Context(), userId, ctx.Param("id"), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_ORGANIZATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_ORGANIZATION, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *organizationController) DeleteOrganization(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	if err := c.organizationService.Delete(ctx.Request.Context(), userId, ctx.Param("id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_ORGANIZATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_ORGANIZATION, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *organizationController) GetMembers(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.organizationService.GetMembers(ctx.Request.Context(), userId, ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_MEMBERS, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_MEMBERS, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *organizationController) UpdateMember(ctx *gin.Context) {
+	var req dto.MemberUpdateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.organizationService.UpdateMember(ctx.Request.Context(), userId, ctx.Param("id"), ctx.Param("user_id"), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_MEMBER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_MEMBER, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *organizationController) RemoveMember(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	if err := c.organizationService.RemoveMember(ctx.Request.Context(), userId, ctx.Param("id"), ctx.Param("user_id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REMOVE_MEMBER, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REMOVE_MEMBER, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *organizationController) GetInvitations(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.invitationService.List(ctx.Request.Context(), userId, ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_INVITATIONS, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_INVITATIONS, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *organizationController) CreateInvitation(ctx *gin.Context) {
+	var req dto.InvitationCreateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.invitationService.Create(ctx.Request.Context(), userId, ctx.Param("id"), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_INVITATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_INVITATION, result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *organizationController) RevokeInvitation(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(string)
+
+	if err := c.invitationService.Revoke(ctx.Request.Context(), userId, ctx.Param("id"), ctx.Param("invitation_id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REVOKE_INVITATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REVOKE_INVITATION, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *organizationController) AcceptInvitation(ctx *gin.Context) {
+	var req dto.InvitationAcceptRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userId := ctx.MustGet("user_id").(string)
+
+	result, err := c.invitationService.Accept(ctx.Request.Context(), userId, req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_ACCEPT_INVITATION, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_ACCEPT_INVITATION, result)
+	ctx.JSON(http.StatusOK, res)
+}
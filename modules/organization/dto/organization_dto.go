@@ -0,0 +1,101 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
+
+	MESSAGE_FAILED_GET_ORGANIZATIONS    = "failed get organizations"
+	MESSAGE_FAILED_GET_ORGANIZATION     = "failed get organization"
+	MESSAGE_FAILED_CREATE_ORGANIZATION  = "failed create organization"
+	MESSAGE_FAILED_UPDATE_ORGANIZATION  = "failed update organization"
+	MESSAGE_FAILED_DELETE_ORGANIZATION  = "failed delete organization"
+	MESSAGE_SUCCESS_GET_ORGANIZATIONS   = "success get organizations"
+	MESSAGE_SUCCESS_GET_ORGANIZATION    = "success get organization"
+	MESSAGE_SUCCESS_CREATE_ORGANIZATION = "success create organization"
+	MESSAGE_SUCCESS_UPDATE_ORGANIZATION = "success update organization"
+	MESSAGE_SUCCESS_DELETE_ORGANIZATION = "success delete organization"
+
+	MESSAGE_FAILED_GET_MEMBERS    = "failed get members"
+	MESSAGE_FAILED_UPDATE_MEMBER  = "failed update member"
+	MESSAGE_FAILED_REMOVE_MEMBER  = "failed remove member"
+	MESSAGE_SUCCESS_GET_MEMBERS   = "success get members"
+	MESSAGE_SUCCESS_UPDATE_MEMBER = "success update member"
+	MESSAGE_SUCCESS_REMOVE_MEMBER = "success remove member"
+
+	MESSAGE_FAILED_GET_INVITATIONS    = "failed get invitations"
+	MESSAGE_FAILED_CREATE_INVITATION  = "failed create invitation"
+	MESSAGE_FAILED_REVOKE_INVITATION  = "failed revoke invitation"
+	MESSAGE_FAILED_ACCEPT_INVITATION  = "failed accept invitation"
+	MESSAGE_SUCCESS_GET_INVITATIONS   = "success get invitations"
+	MESSAGE_SUCCESS_CREATE_INVITATION = "success create invitation"
+	MESSAGE_SUCCESS_REVOKE_INVITATION = "success revoke invitation"
+	MESSAGE_SUCCESS_ACCEPT_INVITATION = "success accept invitation"
+)
+
+var (
+	ErrOrganizationNotFound      = errors.New("organization not found")
+	ErrOrganizationRoleDenied    = errors.New("insufficient organization role")
+	ErrMemberNotFound            = errors.New("member not found")
+	ErrAlreadyMember             = errors.New("user is already a member of the organization")
+	ErrLastOwner                 = errors.New("an organization needs at least one owner")
+	ErrInvitationNotFound        = errors.New("invitation not found")
+	ErrInvitationInvalid         = errors.New("invitation invalid, expired or already used")
+	ErrInvitationEmailMismatch   = errors.New("invitation was sent to another email")
+	ErrInvitationNotPending      = errors.New("invitation was already accepted or revoked")
+	ErrInvitationEmailUnverified = errors.New("verify your email before accepting an invitation")
+)
+
+type (
+	OrganizationCreateRequest struct {
+		Name string `json:"name" binding:"required,min=2,max=100"`
+	}
+
+	OrganizationUpdateRequest struct {
+		Name string `json:"name" binding:"required,min=2,max=100"`
+	}
+
+	// OrganizationResponse includes the role of the caller in the
+	// organization.
+	OrganizationResponse struct {
+		ID        string    `json:"id"`
+		Name      string    `json:"name"`
+		Role      string    `json:"role"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	MemberUpdateRequest struct {
+		Role string `json:"role" binding:"required,oneof=owner admin member"`
+	}
+
+	MemberResponse struct {
+		UserID   string    `json:"user_id"`
+		Name     string    `json:"name"`
+		Email    string    `json:"email"`
+		Role     string    `json:"role"`
+		JoinedAt time.Time `json:"joined_at"`
+	}
+
+	InvitationCreateRequest struct {
+		Email string `json:"email" binding:"required,email"`
+		Role  string `json:"role" binding:"omitempty,oneof=owner admin member"`
+	}
+
+	InvitationAcceptRequest struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	InvitationResponse struct {
+		ID             string     `json:"id"`
+		OrganizationID string     `json:"organization_id"`
+		Email          string     `json:"email"`
+		Role           string     `json:"role"`
+		InvitedByID    string     `json:"invited_by_id,omitempty"`
+		ExpiresAt      time.Time  `json:"expires_at"`
+		AcceptedAt     *time.Time `json:"accepted_at"`
+		CreatedAt      time.Time  `json:"created_at"`
+	}
+)
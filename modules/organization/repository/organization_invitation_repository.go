@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+)
+
+type OrganizationInvitationRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, invitation entities.OrganizationInvitation) (entities.OrganizationInvitation, error)
+	FindByID(ctx context.Context, tx *gorm.DB, organizationID string, id string) (entities.OrganizationInvitation, error)
+	FindByTokenHash(ctx context.Context, tx *gorm.DB, tokenHash string) (entities.OrganizationInvitation, error)
+	FindPending(ctx context.Context, tx *gorm.DB, organizationID string) ([]entities.OrganizationInvitation, error)
+	MarkAccepted(ctx context.Context, tx *gorm.DB, id string) (bool, error)
+	Revoke(ctx context.Context, tx *gorm.DB, id string) (bool, error)
+	RevokePendingByEmail(ctx context.Context, tx *gorm.DB, organizationID string, email string) error
+}
+
+type organizationInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationInvitationRepository(db *gorm.DB) OrganizationInvitationRepository {
+	return &organizationInvitationRepository{
+		db: db,
+	}
+}
+
+// pending limits a query to invitations that have been neither accepted nor
+// revoked. Expiry is checked separately so callers can tell it apart.
+func pending(tx *gorm.DB) *gorm.DB {
+	return tx.Where("accepted_at IS NULL AND revoked_at IS NULL")
+}
+
+func (r *organizationInvitationRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	invitation entities.OrganizationInvitation,
+) (entities.OrganizationInvitation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&invitation).Error; err != nil {
+		return entities.OrganizationInvitation{}, err
+	}
+
+	return invitation, nil
+}
+
+func (r *organizationInvitationRepository) FindByID(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationID string,
+	id string,
+) (entities.OrganizationInvitation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var invitation entities.OrganizationInvitation
+	if err := tx.WithContext(ctx).
+		Where("organization_id = ? AND id = ?", organizationID, id).
+		Take(&invitation).Error; err != nil {
+		return entities.OrganizationInvitation{}, err
+	}
+
+	return invitation, nil
+}
+
+func (r *organizationInvitationRepository) FindByTokenHash(
+	ctx context.Context,
+	tx *gorm.DB,
+	tokenHash string,
+) (entities.OrganizationInvitation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var invitation entities.OrganizationInvitation
+	if err := tx.WithContext(ctx).
+		Where("token_hash = ?", tokenHash).
+		Preload("Organization").
+		Take(&invitation).Error; err != nil {
+		return entities.OrganizationInvitation{}, err
+	}
+
+	return invitation, nil
+}
+
+// FindPending returns the unexpired invitations of the organization that
+// are still waiting for an answer, newest first.
+func (r *organizationInvitationRepository) FindPending(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationID string,
+) ([]entities.OrganizationInvitation, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var invitations []entities.OrganizationInvitation
+	if err := pending(tx.WithContext(ctx)).
+		Where("organization_id = ? AND expires_at > ?", organizationID, time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+// MarkAccepted reports false when the invitation was accepted or revoked
+// concurrently, so each invitation adds at most one member.
+func (r *organizationInvitationRepository) MarkAccepted(ctx context.Context, tx *gorm.DB, id string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := pending(tx.WithContext(ctx).Model(&entities.OrganizationInvitation{})).
+		Where("id = ?", id).
+		Update("accepted_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Revoke reports false when there is no pending invitation with the id.
+func (r *organizationInvitationRepository) Revoke(ctx context.Context, tx *gorm.DB, id string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := pending(tx.WithContext(ctx).Model(&entities.OrganizationInvitation{})).
+		Where("id = ?", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *organizationInvitationRepository) RevokePendingByEmail(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationID string,
+	email string,
+) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return pending(tx.WithContext(ctx).Model(&entities.OrganizationInvitation{})).
+		Where("organization_id = ? AND LOWER(email) = LOWER(?)", organizationID, email).
+		Update("revoked_at", time.Now()).Error
+}
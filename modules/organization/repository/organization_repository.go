@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, organization entities.Organization) (entities.Organization, error)
+	FindByID(ctx context.Context, tx *gorm.DB, id string) (entities.Organization, error)
+	LockByID(ctx context.Context, tx *gorm.DB, id string) (entities.Organization, error)
+	UpdateName(ctx context.Context, tx *gorm.DB, id string, name string) (bool, error)
+	Delete(ctx context.Context, tx *gorm.DB, id string) (bool, error)
+	AddMember(ctx context.Context, tx *gorm.DB, member entities.OrganizationMember) (entities.OrganizationMember, error)
+	FindMember(ctx context.Context, tx *gorm.DB, organizationID string, userID string) (entities.OrganizationMember, error)
+	FindMembers(ctx context.Context, tx *gorm.DB, organizationID string) ([]entities.OrganizationMember, error)
+	FindMembershipsByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]entities.OrganizationMember, error)
+	UpdateMemberRole(ctx context.Context, tx *gorm.DB, organizationID string, userID string, role string) (bool, error)
+	RemoveMember(ctx context.Context, tx *gorm.DB, organizationID string, userID string) (bool, error)
+	CountMembersWithRole(ctx context.Context, tx *gorm.DB, organizationID string, role string) (int64, error)
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{
+		db: db,
+	}
+}
+
+func (r *organizationRepository) Create(
+	ctx context.Context,
+	tx *gorm.DB,
+	organization entities.Organization,
+) (entities.Organization, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&organization).Error; err != nil {
+		return entities.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+func (r *organizationRepository) FindByID(ctx context.Context, tx *gorm.DB, id string) (entities.Organization, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var organization entities.Organization
+	if err := tx.WithContext(ctx).Where("id = ?", id).Take(&organization).Error; err != nil {
+		return entities.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+// LockByID reads the organization and locks its row until the transaction
+// ends, serializing membership changes so the last owner cannot be removed
+// by two concurrent requests.
+func (r *organizationRepository) LockByID(ctx context.Context, tx *gorm.DB, id string) (entities.Organization, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var organization entities.Organization
+	if err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		Take(&organization).Error; err != nil {
+		return entities.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+func (r *organizationRepository) UpdateName(ctx context.Context, tx *gorm.DB, id string, name string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).Model(&entities.Organization{}).Where("id = ?", id).Update("name", name)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Delete removes the organization. Memberships, invitations and tenant-owned
+// rows are removed with it by their foreign keys.
+func (r *organizationRepository) Delete(ctx context.Context, tx *gorm.DB, id string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).Where("id = ?", id).Delete(&entities.Organization{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *organizationRepository) AddMember(
+	ctx context.Context,
+	tx *gorm.DB,
+	member entities.OrganizationMember,
+) (entities.OrganizationMember, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&member).Error; err != nil {
+		return entities.OrganizationMember{}, err
+	}
+
+	return member, nil
+}
+
+func (r *organizationRepository) FindMember(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationID string,
+	userID string,
+) (entities.OrganizationMember, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var member entities.OrganizationMember
+	if err := tx.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Preload("Organization").
+		Preload("User").
+		Take(&member).Error; err != nil {
+		return entities.OrganizationMember{}, err
+	}
+
+	return member, nil
+}
+
+// FindMembers returns the members with their users, oldest first. Members
+// whose account was soft-deleted are left out.
+func (r *organizationRepository) FindMembers(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationID string,
+) ([]entities.OrganizationMember, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var members []entities.OrganizationMember
+	if err := tx.WithContext(ctx).
+		InnerJoins("User").
+		Where("organization_members.organization_id = ?", organizationID).
+		Order("organization_members.created_at ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// FindMembershipsByUserID returns the organizations of the user, in the
+// order they were joined.
+func (r *organizationRepository) FindMembershipsByUserID(
+	ctx context.Context,
+	tx *gorm.DB,
+	userID string,
+) ([]entities.OrganizationMember, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var members []entities.OrganizationMember
+	if err := tx.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Preload("Organization").
+		Order("created_at ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *organizationRepository) UpdateMemberRole(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationID string,
+	userID string,
+	role string,
+) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Model(&entities.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Update("role", role)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, tx *gorm.DB, organizationID string, userID string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(&entities.OrganizationMember{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *organizationRepository) CountMembersWithRole(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationID string,
+	role string,
+) (int64, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var count int64
+	if err := tx.WithContext(ctx).
+		Model(&entities.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", organizationID, role).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
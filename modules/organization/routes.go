@@ -0,0 +1,34 @@
+package organization
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+)
+
+// RegisterRoutes exposes the organizations of the caller. Organization roles
+// are checked by the services against the stored memberships, not by
+// middleware, because the routes name the organization explicitly.
+func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
+	organizationController := do.MustInvoke[controller.OrganizationController](injector)
+	authenticator := do.MustInvokeNamed[service.Authenticator](injector, constants.Authenticator)
+
+	organizationRoutes := server.Group("/api/organizations", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey())
+	{
+		organizationRoutes.GET("", organizationController.GetOrganizations)
+		organizationRoutes.POST("", organizationController.CreateOrganization)
+		organizationRoutes.POST("/invitations/accept", middlewares.DenyImpersonation(), organizationController.AcceptInvitation)
+		organizationRoutes.GET("/:id", organizationController.GetOrganization)
+		organizationRoutes.PATCH("/:id", organizationController.UpdateOrganization)
+		organizationRoutes.DELETE("/:id", organizationController.DeleteOrganization)
+		organizationRoutes.GET("/:id/members", organizationController.GetMembers)
+		organizationRoutes.PATCH("/:id/members/:user_id", organizationController.UpdateMember)
+		organizationRoutes.DELETE("/:id/members/:user_id", organizationController.RemoveMember)
+		organizationRoutes.GET("/:id/invitations", organizationController.GetInvitations)
+		organizationRoutes.POST("/:id/invitations", organizationController.CreateInvitation)
+		organizationRoutes.DELETE("/:id/invitations/:invitation_id", organizationController.RevokeInvitation)
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"html"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/repository"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	userRepo "github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrganizationInvitationService invites people to organizations. Owners and
+// admins send invitations; the invitee accepts with the emailed token while
+// signed in to an account with the invited, verified email.
+type OrganizationInvitationService interface {
+	List(ctx context.Context, userId string, organizationId string) ([]dto.InvitationResponse, error)
+	Create(ctx context.Context, userId string, organizationId string, req dto.InvitationCreateRequest) (dto.InvitationResponse, error)
+	Revoke(ctx context.Context, userId string, organizationId string, invitationId string) error
+	Accept(ctx context.Context, userId string, req dto.InvitationAcceptRequest) (dto.OrganizationResponse, error)
+}
+
+const defaultOrganizationInvitationTTL = time.Hour * 24 * 7
+
+type organizationInvitationService struct {
+	organizationService    OrganizationService
+	organizationRepository repository.OrganizationRepository
+	invitationRepository   repository.OrganizationInvitationRepository
+	userRepository         userRepo.UserRepository
+	ttl                    time.Duration
+	invitationURL          string
+	db                     *gorm.DB
+}
+
+// NewOrganizationInvitationService shares INVITATION_TTL with account
+// invitations. The email links to ORGANIZATION_INVITATION_URL when set and
+// otherwise only contains the token.
+func NewOrganizationInvitationService(
+	organizationService OrganizationService,
+	organizationRepo repository.OrganizationRepository,
+	invitationRepo repository.OrganizationInvitationRepository,
+	userRepo userRepo.UserRepository,
+	db *gorm.DB,
+) OrganizationInvitationService {
+	ttl := helpers.GetDurationEnv("INVITATION_TTL", defaultOrganizationInvitationTTL)
+
+	return &organizationInvitationService{
+		organizationService:    organizationService,
+		organizationRepository: organizationRepo,
+		invitationRepository:   invitationRepo,
+		userRepository:         userRepo,
+		ttl:                    ttl,
+		invitationURL:          os.Getenv("ORGANIZATION_INVITATION_URL"),
+		db:                     db,
+	}
+}
+
+func toInvitationResponse(invitation entities.OrganizationInvitation) dto.InvitationResponse {
+	response := dto.InvitationResponse{
+		ID:             invitation.ID.String(),
+		OrganizationID: invitation.OrganizationID.String(),
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		ExpiresAt:      invitation.ExpiresAt,
+		AcceptedAt:     invitation.AcceptedAt,
+		CreatedAt:      invitation.CreatedAt,
+	}
+	if invitation.InvitedByID != nil {
+		response.InvitedByID = invitation.InvitedByID.String()
+	}
+
+	return response
+}
+
+// List returns the pending invitations of the organization.
+func (s *organizationInvitationService) List(
+	ctx context.Context,
+	userId string,
+	organizationId string,
+) ([]dto.InvitationResponse, error) {
+	if _, err := s.organizationService.Authorize(ctx, s.db, organizationId, userId, constants.ORGANIZATION_ROLE_ADMIN); err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.invitationRepository.FindPending(ctx, s.db, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		response = append(response, toInvitationResponse(invitation))
+	}
+
+	return response, nil
+}
+
+// Create invites the email as a member unless another role is requested,
+// replacing any invitation to it that is still pending. Only owners can
+// invite owners.
+func (s *organizationInvitationService) Create(
+	ctx context.Context,
+	userId string,
+	organizationId string,
+	req dto.InvitationCreateRequest,
+) (dto.InvitationResponse, error) {
+	role := req.Role
+	if role == "" {
+		role = constants.ORGANIZATION_ROLE_MEMBER
+	}
+
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	var invitation entities.OrganizationInvitation
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inviter, err := s.organizationService.Authorize(ctx, tx, organizationId, userId, constants.ORGANIZATION_ROLE_ADMIN)
+		if err != nil {
+			return err
+		}
+		if err := canManage(inviter, "", role); err != nil {
+			return err
+		}
+
+		user, isExist, err := s.userRepository.CheckEmail(ctx, tx, req.Email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if isExist {
+			_, err := s.organizationRepository.FindMember(ctx, tx, organizationId, user.ID.String())
+			if err == nil {
+				return dto.ErrAlreadyMember
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		if err := s.invitationRepository.RevokePendingByEmail(ctx, tx, organizationId, req.Email); err != nil {
+			return err
+		}
+
+		invitation, err = s.invitationRepository.Create(ctx, tx, entities.OrganizationInvitation{
+			ID:             uuid.New(),
+			OrganizationID: inviter.OrganizationID,
+			Email:          req.Email,
+			Role:           role,
+			TokenHash:      helpers.HashToken(token),
+			InvitedByID:    &inviter.UserID,
+			ExpiresAt:      time.Now().Add(s.ttl),
+		})
+		invitation.Organization = inviter.Organization
+		return err
+	})
+	if err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	if err := s.sendInvitationEmail(invitation, token); err != nil {
+		return dto.InvitationResponse{}, err
+	}
+
+	return toInvitationResponse(invitation), nil
+}
+
+func (s *organizationInvitationService) Revoke(
+	ctx context.Context,
+	userId string,
+	organizationId string,
+	invitationId string,
+) error {
+	if _, err := s.organizationService.Authorize(ctx, s.db, organizationId, userId, constants.ORGANIZATION_ROLE_ADMIN); err != nil {
+		return err
+	}
+
+	if _, err := uuid.Parse(invitationId); err != nil {
+		return dto.ErrInvitationNotFound
+	}
+
+	if _, err := s.invitationRepository.FindByID(ctx, s.db, organizationId, invitationId); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ErrInvitationNotFound
+		}
+		return err
+	}
+
+	revoked, err := s.invitationRepository.Revoke(ctx, s.db, invitationId)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return dto.ErrInvitationNotPending
+	}
+
+	return nil
+}
+
+// Accept adds the user to the organization of the invitation with the
+// invited role.
+func (s *organizationInvitationService) Accept(
+	ctx context.Context,
+	userId string,
+	req dto.InvitationAcceptRequest,
+) (dto.OrganizationResponse, error) {
+	var invitation entities.OrganizationInvitation
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		invitation, err = s.invitationRepository.FindByTokenHash(ctx, tx, helpers.HashToken(req.Token))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.ErrInvitationInvalid
+		}
+		if err != nil {
+			return err
+		}
+		if invitation.AcceptedAt != nil || invitation.RevokedAt != nil || !invitation.ExpiresAt.After(time.Now()) {
+			return dto.ErrInvitationInvalid
+		}
+
+		user, err := s.userRepository.GetUserById(ctx, tx, userId)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return userDto.ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(invitation.Email, user.Email) {
+			return dto.ErrInvitationEmailMismatch
+		}
+		if !user.IsVerified {
+			return dto.ErrInvitationEmailUnverified
+		}
+
+		_, err = s.organizationRepository.FindMember(ctx, tx, invitation.OrganizationID.String(), userId)
+		if err == nil {
+			return dto.ErrAlreadyMember
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		accepted, err := s.invitationRepository.MarkAccepted(ctx, tx, invitation.ID.String())
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return dto.ErrInvitationInvalid
+		}
+
+		_, err = s.organizationRepository.AddMember(ctx, tx, entities.OrganizationMember{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         user.ID,
+			Role:           invitation.Role,
+		})
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return dto.ErrAlreadyMember
+		}
+		return err
+	})
+	if err != nil {
+		return dto.OrganizationResponse{}, err
+	}
+
+	return toOrganizationResponse(*invitation.Organization, invitation.Role), nil
+}
+
+func (s *organizationInvitationService) sendInvitationEmail(invitation entities.OrganizationInvitation, token string) error {
+	name := "an organization"
+	if invitation.Organization != nil {
+		name = html.EscapeString(invitation.Organization.Name)
+	}
+
+	subject := "Organization Invitation"
+	body := "You have been invited to join " + name + ". Accept the invitation with this token: " + token
+	if s.invitationURL != "" {
+		link := s.invitationURL + "?token=" + url.QueryEscape(token)
+		body = "You have been invited to join " + name + ". Click <a href=\"" + link + "\">here</a> to accept. The invitation expires on " +
+			invitation.ExpiresAt.Format(time.RFC1123) + "."
+	}
+
+	return utils.SendMail(invitation.Email, subject, body)
+}
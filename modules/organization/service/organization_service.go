@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrganizationService manages organizations and their members on behalf of
+// userId. Organizations the user is not a member of are reported as not
+// found. Admins manage members and invitations; only owners can delete the
+// organization or hand out and take away the owner role.
+type OrganizationService interface {
+	Create(ctx context.Context, userId string, req dto.OrganizationCreateRequest) (dto.OrganizationResponse, error)
+	List(ctx context.Context, userId string) ([]dto.OrganizationResponse, error)
+	Get(ctx context.Context, userId string, organizationId string) (dto.OrganizationResponse, error)
+	Update(ctx context.Context, userId string, organizationId string, req dto.OrganizationUpdateRequest) (dto.OrganizationResponse, error)
+	Delete(ctx context.Context, userId string, organizationId string) error
+	GetMembers(ctx context.Context, userId string, organizationId string) ([]dto.MemberResponse, error)
+	UpdateMember(ctx context.Context, userId string, organizationId string, memberId string, req dto.MemberUpdateRequest) (dto.MemberResponse, error)
+	RemoveMember(ctx context.Context, userId string, organizationId string, memberId string) error
+	Authorize(ctx context.Context, tx *gorm.DB, organizationId string, userId string, role string) (entities.OrganizationMember, error)
+}
+
+// organizationRoleRank orders the organization roles from least to most
+// privileged.
+var organizationRoleRank = map[string]int{
+	constants.ORGANIZATION_ROLE_MEMBER: 1,
+	constants.ORGANIZATION_ROLE_ADMIN:  2,
+	constants.ORGANIZATION_ROLE_OWNER:  3,
+}
+
+type organizationService struct {
+	organizationRepository repository.OrganizationRepository
+	db                     *gorm.DB
+}
+
+func NewOrganizationService(organizationRepo repository.OrganizationRepository, db *gorm.DB) OrganizationService {
+	return &organizationService{
+		organizationRepository: organizationRepo,
+		db:                     db,
+	}
+}
+
+func toOrganizationResponse(organization entities.Organization, role string) dto.OrganizationResponse {
+	return dto.OrganizationResponse{
+		ID:        organization.ID.String(),
+		Name:      organization.Name,
+		Role:      role,
+		CreatedAt: organization.CreatedAt,
+	}
+}
+
+func toMemberResponse(member entities.OrganizationMember) dto.MemberResponse {
+	response := dto.MemberResponse{
+		UserID:   member.UserID.String(),
+		Role:     member.Role,
+		JoinedAt: member.CreatedAt,
+	}
+	if member.User != nil {
+		response.Name = member.User.Name
+		response.Email = member.User.Email
+	}
+
+	return response
+}
+
+// Create makes the user the first owner of a new organization.
+func (s *organizationService) Create(
+	ctx context.Context,
+	userId string,
+	req dto.OrganizationCreateRequest,
+) (dto.OrganizationResponse, error) {
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return dto.OrganizationResponse{}, authDto.ErrPrincipalNotFound
+	}
+
+	var organization entities.Organization
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		organization, err = s.organizationRepository.Create(ctx, tx, entities.Organization{
+			ID:          uuid.New(),
+			Name:        req.Name,
+			CreatedByID: &userUUID,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = s.organizationRepository.AddMember(ctx, tx, entities.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         userUUID,
+			Role:           constants.ORGANIZATION_ROLE_OWNER,
+		})
+		return err
+	})
+	if err != nil {
+		return dto.OrganizationResponse{}, err
+	}
+
+	return toOrganizationResponse(organization, constants.ORGANIZATION_ROLE_OWNER), nil
+}
+
+func (s *organizationService) List(ctx context.Context, userId string) ([]dto.OrganizationResponse, error) {
+	memberships, err := s.organizationRepository.FindMembershipsByUserID(ctx, s.db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.OrganizationResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.Organization != nil {
+			response = append(response, toOrganizationResponse(*membership.Organization, membership.Role))
+		}
+	}
+
+	return response, nil
+}
+
+func (s *organizationService) Get(ctx context.Context, userId string, organizationId string) (dto.OrganizationResponse, error) {
+	member, err := s.Authorize(ctx, s.db, organizationId, userId, constants.ORGANIZATION_ROLE_MEMBER)
+	if err != nil {
+		return dto.OrganizationResponse{}, err
+	}
+
+	return toOrganizationResponse(*member.Organization, member.Role), nil
+}
+
+func (s *organizationService) Update(
+	ctx context.Context,
+	userId string,
+	organizationId string,
+	req dto.OrganizationUpdateRequest,
+) (dto.OrganizationResponse, error) {
+	member, err := s.Authorize(ctx, s.db, organizationId, userId, constants.ORGANIZATION_ROLE_ADMIN)
+	if err != nil {
+		return dto.OrganizationResponse{}, err
+	}
+
+	updated, err := s.organizationRepository.UpdateName(ctx, s.db, organizationId, req.Name)
+	if err != nil {
+		return dto.OrganizationResponse{}, err
+	}
+	if !updated {
+		return dto.OrganizationResponse{}, dto.ErrOrganizationNotFound
+	}
+
+	organization := *member.Organization
+	organization.Name = req.Name
+	return toOrganizationResponse(organization, member.Role), nil
+}
+
+func (s *organizationService) Delete(ctx context.Context, userId string, organizationId string) error {
+	if _, err := s.Authorize(ctx, s.db, organizationId, userId, constants.ORGANIZATION_ROLE_OWNER); err != nil {
+		return err
+	}
+
+	deleted, err := s.organizationRepository.Delete(ctx, s.db, organizationId)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return dto.ErrOrganizationNotFound
+	}
+
+	return nil
+}
+
+func (s *organizationService) GetMembers(ctx context.Context, userId string, organizationId string) ([]dto.MemberResponse, error) {
+	if _, err := s.Authorize(ctx, s.db, organizationId, userId, constants.ORGANIZATION_ROLE_MEMBER); err != nil {
+		return nil, err
+	}
+
+	members, err := s.organizationRepository.FindMembers(ctx, s.db, organizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.MemberResponse, 0, len(members))
+	for _, member := range members {
+		response = append(response, toMemberResponse(member))
+	}
+
+	return response, nil
+}
+
+// UpdateMember changes the role of a member. The organization keeps at least
+// one owner.
+func (s *organizationService) UpdateMember(
+	ctx context.Context,
+	userId string,
+	organizationId string,
+	memberId string,
+	req dto.MemberUpdateRequest,
+) (dto.MemberResponse, error) {
+	var target entities.OrganizationMember
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		actor, err := s.lockAndAuthorize(ctx, tx, organizationId, userId, constants.ORGANIZATION_ROLE_ADMIN)
+		if err != nil {
+			return err
+		}
+
+		target, err = s.getMember(ctx, tx, organizationId, memberId)
+		if err != nil {
+			return err
+		}
+
+		if err := canManage(actor, target.Role, req.Role); err != nil {
+			return err
+		}
+		if err := s.keepOwner(ctx, tx, target, req.Role); err != nil {
+			return err
+		}
+
+		updated, err := s.organizationRepository.UpdateMemberRole(ctx, tx, organizationId, memberId, req.Role)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return dto.ErrMemberNotFound
+		}
+
+		target.Role = req.Role
+		return nil
+	})
+	if err != nil {
+		return dto.MemberResponse{}, err
+	}
+
+	return toMemberResponse(target), nil
+}
+
+// RemoveMember removes a member, or lets any member leave on their own. The
+// organization keeps at least one owner.
+func (s *organizationService) RemoveMember(ctx context.Context, userId string, organizationId string, memberId string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		requiredRole := constants.ORGANIZATION_ROLE_ADMIN
+		if memberId == userId {
+			requiredRole = constants.ORGANIZATION_ROLE_MEMBER
+		}
+
+		actor, err := s.lockAndAuthorize(ctx, tx, organizationId, userId, requiredRole)
+		if err != nil {
+			return err
+		}
+
+		target, err := s.getMember(ctx, tx, organizationId, memberId)
+		if err != nil {
+			return err
+		}
+
+		if memberId != userId {
+			if err := canManage(actor, target.Role, ""); err != nil {
+				return err
+			}
+		}
+		if err := s.keepOwner(ctx, tx, target, ""); err != nil {
+			return err
+		}
+
+		removed, err := s.organizationRepository.RemoveMember(ctx, tx, organizationId, memberId)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return dto.ErrMemberNotFound
+		}
+
+		return nil
+	})
+}
+
+// Authorize returns the membership of the user when it has at least the
+// given organization role. Non-members get ErrOrganizationNotFound, so the
+// existence of other organizations is not disclosed.
+func (s *organizationService) Authorize(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationId string,
+	userId string,
+	role string,
+) (entities.OrganizationMember, error) {
+	if _, err := uuid.Parse(organizationId); err != nil {
+		return entities.OrganizationMember{}, dto.ErrOrganizationNotFound
+	}
+
+	member, err := s.organizationRepository.FindMember(ctx, tx, organizationId, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.OrganizationMember{}, dto.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return entities.OrganizationMember{}, err
+	}
+
+	if organizationRoleRank[member.Role] < organizationRoleRank[role] {
+		return entities.OrganizationMember{}, dto.ErrOrganizationRoleDenied
+	}
+
+	return member, nil
+}
+
+// lockAndAuthorize locks the organization for the rest of the transaction
+// before authorizing, so that concurrent membership changes are serialized.
+func (s *organizationService) lockAndAuthorize(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationId string,
+	userId string,
+	role string,
+) (entities.OrganizationMember, error) {
+	if _, err := uuid.Parse(organizationId); err != nil {
+		return entities.OrganizationMember{}, dto.ErrOrganizationNotFound
+	}
+
+	_, err := s.organizationRepository.LockByID(ctx, tx, organizationId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.OrganizationMember{}, dto.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return entities.OrganizationMember{}, err
+	}
+
+	return s.Authorize(ctx, tx, organizationId, userId, role)
+}
+
+// getMember looks a member up, treating malformed user ids as unknown
+// members.
+func (s *organizationService) getMember(
+	ctx context.Context,
+	tx *gorm.DB,
+	organizationId string,
+	memberId string,
+) (entities.OrganizationMember, error) {
+	if _, err := uuid.Parse(memberId); err != nil {
+		return entities.OrganizationMember{}, dto.ErrMemberNotFound
+	}
+
+	member, err := s.organizationRepository.FindMember(ctx, tx, organizationId, memberId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.OrganizationMember{}, dto.ErrMemberNotFound
+	}
+
+	return member, err
+}
+
+// canManage checks that the actor may change a member from currentRole to
+// newRole, or remove them when newRole is empty. Only owners manage owners.
+func canManage(actor entities.OrganizationMember, currentRole string, newRole string) error {
+	if actor.Role == constants.ORGANIZATION_ROLE_OWNER {
+		return nil
+	}
+	if currentRole == constants.ORGANIZATION_ROLE_OWNER || newRole == constants.ORGANIZATION_ROLE_OWNER {
+		return dto.ErrOrganizationRoleDenied
+	}
+
+	return nil
+}
+
+// keepOwner returns ErrLastOwner when the member is the only owner and would
+// lose the role.
+func (s *organizationService) keepOwner(
+	ctx context.Context,
+	tx *gorm.DB,
+	member entities.OrganizationMember,
+	newRole string,
+) error {
+	if member.Role != constants.ORGANIZATION_ROLE_OWNER || newRole == constants.ORGANIZATION_ROLE_OWNER {
+		return nil
+	}
+
+	owners, err := s.organizationRepository.CountMembersWithRole(
+		ctx, tx, member.OrganizationID.String(), constants.ORGANIZATION_ROLE_OWNER,
+	)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return dto.ErrLastOwner
+	}
+
+	return nil
+}
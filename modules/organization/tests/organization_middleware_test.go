@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthorizedRouter(principal *authDto.Principal, guard gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", func(ctx *gin.Context) {
+		if principal != nil {
+			ctx.Set("principal", *principal)
+		}
+		ctx.Next()
+	}, guard, func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return router
+}
+
+func serve(router *gin.Engine) int {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	return recorder.Code
+}
+
+func TestRequireOrganization_Allowed(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	principal.OrganizationID = uuid.NewString()
+	principal.OrganizationRole = constants.ORGANIZATION_ROLE_ADMIN
+	router := newAuthorizedRouter(&principal, middlewares.RequireOrganization(constants.ORGANIZATION_ROLE_OWNER, constants.ORGANIZATION_ROLE_ADMIN))
+
+	assert.Equal(t, http.StatusOK, serve(router))
+}
+
+func TestRequireOrganization_RoleDenied(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	principal.OrganizationID = uuid.NewString()
+	principal.OrganizationRole = constants.ORGANIZATION_ROLE_MEMBER
+	router := newAuthorizedRouter(&principal, middlewares.RequireOrganization(constants.ORGANIZATION_ROLE_OWNER))
+
+	assert.Equal(t, http.StatusForbidden, serve(router))
+}
+
+func TestRequireOrganization_NoActiveOrganization(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_ADMIN)
+	router := newAuthorizedRouter(&principal, middlewares.RequireOrganization())
+
+	assert.Equal(t, http.StatusForbidden, serve(router))
+}
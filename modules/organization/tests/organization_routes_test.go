@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/organization/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAuthenticator treats every bearer token as the name of a role.
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) AuthenticateAccessToken(_ context.Context, token string) (authDto.Principal, error) {
+	return authDto.NewPrincipal("actor-id", token), nil
+}
+
+func (stubAuthenticator) AuthenticateAPIKey(context.Context, string, string) (authDto.Principal, error) {
+	return authDto.Principal{}, authDto.ErrAPIKeyInvalid
+}
+
+type stubOrganizationService struct {
+	service.OrganizationService
+	userId string
+}
+
+func (s *stubOrganizationService) Create(_ context.Context, userId string, req dto.OrganizationCreateRequest) (dto.OrganizationResponse, error) {
+	s.userId = userId
+	return dto.OrganizationResponse{Name: req.Name, Role: constants.ORGANIZATION_ROLE_OWNER}, nil
+}
+
+func (s *stubOrganizationService) Get(_ context.Context, _ string, organizationId string) (dto.OrganizationResponse, error) {
+	if organizationId != "org-id" {
+		return dto.OrganizationResponse{}, dto.ErrOrganizationNotFound
+	}
+	return dto.OrganizationResponse{ID: organizationId}, nil
+}
+
+func (s *stubOrganizationService) UpdateMember(_ context.Context, _ string, _ string, memberId string, req dto.MemberUpdateRequest) (dto.MemberResponse, error) {
+	if memberId == "last-owner" {
+		return dto.MemberResponse{}, dto.ErrLastOwner
+	}
+	return dto.MemberResponse{UserID: memberId, Role: req.Role}, nil
+}
+
+func (s *stubOrganizationService) RemoveMember(_ context.Context, _ string, _ string, memberId string) error {
+	if memberId == "owner" {
+		return dto.ErrOrganizationRoleDenied
+	}
+	return nil
+}
+
+type stubInvitationService struct {
+	service.OrganizationInvitationService
+}
+
+func (s *stubInvitationService) Create(_ context.Context, _ string, _ string, req dto.InvitationCreateRequest) (dto.InvitationResponse, error) {
+	if req.Email == "member@example.com" {
+		return dto.InvitationResponse{}, dto.ErrAlreadyMember
+	}
+	return dto.InvitationResponse{Email: req.Email, Role: req.Role}, nil
+}
+
+func (s *stubInvitationService) Accept(_ context.Context, _ string, req dto.InvitationAcceptRequest) (dto.OrganizationResponse, error) {
+	if req.Token != "valid" {
+		return dto.OrganizationResponse{}, dto.ErrInvitationEmailMismatch
+	}
+	return dto.OrganizationResponse{ID: "org-id", Role: constants.ORGANIZATION_ROLE_MEMBER}, nil
+}
+
+func newOrganizationRouter(organizationService service.OrganizationService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	injector := do.New()
+	do.ProvideNamedValue[authService.Authenticator](injector, constants.Authenticator, stubAuthenticator{})
+	do.Provide(injector, func(i *do.Injector) (controller.OrganizationController, error) {
+		return controller.NewOrganizationController(organizationService, &stubInvitationService{}), nil
+	})
+
+	router := gin.New()
+	organization.RegisterRoutes(router, injector)
+	return router
+}
+
+func request(router *gin.Engine, method string, path string, body string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+constants.ENUM_ROLE_USER)
+	req.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestOrganizationRoutes_Organizations(t *testing.T) {
+	organizationService := &stubOrganizationService{}
+	router := newOrganizationRouter(organizationService)
+
+	assert.Equal(t, http.StatusCreated, request(router, http.MethodPost, "/api/organizations", `{"name":"Acme"}`))
+	assert.Equal(t, "actor-id", organizationService.userId)
+	assert.Equal(t, http.StatusBadRequest, request(router, http.MethodPost, "/api/organizations", `{}`))
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/api/organizations/org-id", ""))
+	assert.Equal(t, http.StatusNotFound, request(router, http.MethodGet, "/api/organizations/unknown", ""))
+}
+
+func TestOrganizationRoutes_Members(t *testing.T) {
+	router := newOrganizationRouter(&stubOrganizationService{})
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodPatch, "/api/organizations/org-id/members/user-id", `{"role":"admin"}`))
+	assert.Equal(t, http.StatusBadRequest, request(router, http.MethodPatch, "/api/organizations/org-id/members/user-id", `{"role":"root"}`))
+	assert.Equal(t, http.StatusConflict, request(router, http.MethodPatch, "/api/organizations/org-id/members/last-owner", `{"role":"member"}`))
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodDelete, "/api/organizations/org-id/members/user-id", ""))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodDelete, "/api/organizations/org-id/members/owner", ""))
+}
+
+func TestOrganizationRoutes_Invitations(t *testing.T) {
+	router := newOrganizationRouter(&stubOrganizationService{})
+
+	assert.Equal(t, http.StatusCreated, request(router, http.MethodPost, "/api/organizations/org-id/invitations", `{"email":"new@example.com"}`))
+	assert.Equal(t, http.StatusConflict, request(router, http.MethodPost, "/api/organizations/org-id/invitations", `{"email":"member@example.com"}`))
+	assert.Equal(t, http.StatusBadRequest, request(router, http.MethodPost, "/api/organizations/org-id/invitations", `{"email":"not-an-email"}`))
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodPost, "/api/organizations/invitations/accept", `{"token":"valid"}`))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodPost, "/api/organizations/invitations/accept", `{"token":"other"}`))
+}
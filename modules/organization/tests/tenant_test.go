@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type tenantNote struct {
+	ID   uuid.UUID `gorm:"type:uuid;primary_key"`
+	Body string
+	entities.TenantOwned
+}
+
+func newDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	return db
+}
+
+func TestTenantScope_FiltersByOrganization(t *testing.T) {
+	organizationID := uuid.New()
+	ctx := tenant.WithOrganizationID(context.Background(), organizationID)
+
+	var notes []tenantNote
+	statement := newDryRunDB(t).WithContext(ctx).Scopes(tenant.Scope(ctx)).Find(&notes).Statement
+
+	require.NoError(t, statement.Error)
+	assert.Contains(t, statement.SQL.String(), "`tenant_notes`.`organization_id` = ?")
+	assert.Contains(t, statement.Vars, organizationID)
+}
+
+func TestTenantScope_RequiresOrganization(t *testing.T) {
+	var notes []tenantNote
+	err := newDryRunDB(t).Scopes(tenant.Scope(context.Background())).Find(&notes).Error
+
+	assert.ErrorIs(t, err, tenant.ErrNoOrganization)
+}
+
+func TestTenantOwned_SetsOrganizationOnCreate(t *testing.T) {
+	organizationID := uuid.New()
+	ctx := tenant.WithOrganizationID(context.Background(), organizationID)
+
+	note := tenantNote{ID: uuid.New(), Body: "hello"}
+	require.NoError(t, newDryRunDB(t).WithContext(ctx).Create(&note).Error)
+	assert.Equal(t, organizationID, note.OrganizationID)
+
+	err := newDryRunDB(t).Create(&tenantNote{ID: uuid.New()}).Error
+	assert.ErrorIs(t, err, tenant.ErrNoOrganization)
+}
+
+func TestTenantScope_HidesRowsOfOtherOrganizations(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Migrator().CreateTable(&tenantNote{}))
+
+	ownCtx := tenant.WithOrganizationID(context.Background(), uuid.New())
+	otherCtx := tenant.WithOrganizationID(context.Background(), uuid.New())
+	require.NoError(t, db.WithContext(ownCtx).Create(&tenantNote{ID: uuid.New(), Body: "own"}).Error)
+
+	var notes []tenantNote
+	require.NoError(t, db.WithContext(otherCtx).Scopes(tenant.Scope(otherCtx)).Find(&notes).Error)
+	assert.Empty(t, notes)
+
+	require.NoError(t, db.WithContext(ownCtx).Scopes(tenant.Scope(ownCtx)).Find(&notes).Error)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "own", notes[0].Body)
+
+	// Scoping is opt-in: a query without the scope sees every organization.
+	require.NoError(t, db.WithContext(otherCtx).Find(&notes).Error)
+	assert.Len(t, notes, 1)
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	RBACController interface {
+		GetPermissions(ctx *gin.Context)
+		GetRoles(ctx *gin.Context)
+		GetRole(ctx *gin.Context)
+		CreateRole(ctx *gin.Context)
+		UpdateRole(ctx *gin.Context)
+		DeleteRole(ctx *gin.Context)
+		GetUserRoles(ctx *gin.Context)
+		SetUserRoles(ctx *gin.Context)
+	}
+
+	rbacController struct {
+		rbacService service.RBACService
+	}
+)
+
+func NewRBACController(rs service.RBACService) RBACController {
+	return &rbacController{
+		rbacService: rs,
+	}
+}
+
+// errorStatus maps the errors of RBACService to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrRoleNotFound), errors.Is(err, userDto.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dto.ErrRoleAlreadyExists), errors.Is(err, dto.ErrRoleInUse):
+		return http.StatusConflict
+	case errors.Is(err, dto.ErrRoleProtected), errors.Is(err, dto.ErrGrantDenied):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (c *rbacController) GetPermissions(ctx *gin.Context) {
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_PERMISSIONS, c.rbacService.ListPermissions())
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *rbacController) GetRoles(ctx *gin.Context) {
+	result, err := c.rbacService.ListRoles(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_ROLES, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_ROLES, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *rbacController) GetRole(ctx *gin.Context) {
+	result, err := c.rbacService.GetRole(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_ROLE, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_ROLE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *rbacController) CreateRole(ctx *gin.Context) {
+	var req dto.RoleCreateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	result, err := c.rbacService.CreateRole(ctx.Request.Context(), principal, req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_ROLE, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_ROLE, result)
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *rbacController) UpdateRole(ctx *gin.Context) {
+	var req dto.RoleUpdateRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	result, err := c.rbacService.UpdateRole(ctx.Request.Context(), principal, ctx.Param("id"), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_ROLE, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_ROLE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *rbacController) DeleteRole(ctx *gin.Context) {
+	if err := c.rbacService.DeleteRole(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_ROLE, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_ROLE, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *rbacController) GetUserRoles(ctx *gin.Context) {
+	result, err := c.rbacService.GetUserRoles(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_USER_ROLES, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_USER_ROLES, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *rbacController) SetUserRoles(ctx *gin.Context) {
+	var req dto.UserRolesRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	principal, _ := middlewares.GetPrincipal(ctx)
+
+	result, err := c.rbacService.SetUserRoles(ctx.Request.Context(), principal, ctx.Param("id"), req)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_USER_ROLE, err.Error(), nil)
+		ctx.JSON(errorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_USER_ROLE, result)
+	ctx.JSON(http.StatusOK, res)
+}
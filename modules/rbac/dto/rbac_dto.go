@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MESSAGE_FAILED_GET_DATA_FROM_BODY = "failed get data from body"
+
+	MESSAGE_FAILED_GET_ROLES        = "failed get roles"
+	MESSAGE_FAILED_GET_ROLE         = "failed get role"
+	MESSAGE_FAILED_CREATE_ROLE      = "failed create role"
+	MESSAGE_FAILED_UPDATE_ROLE      = "failed update role"
+	MESSAGE_FAILED_DELETE_ROLE      = "failed delete role"
+	MESSAGE_FAILED_GET_USER_ROLES   = "failed get user roles"
+	MESSAGE_FAILED_UPDATE_USER_ROLE = "failed update user roles"
+
+	MESSAGE_SUCCESS_GET_ROLES        = "success get roles"
+	MESSAGE_SUCCESS_GET_ROLE         = "success get role"
+	MESSAGE_SUCCESS_CREATE_ROLE      = "success create role"
+	MESSAGE_SUCCESS_UPDATE_ROLE      = "success update role"
+	MESSAGE_SUCCESS_DELETE_ROLE      = "success delete role"
+	MESSAGE_SUCCESS_GET_PERMISSIONS  = "success get permissions"
+	MESSAGE_SUCCESS_GET_USER_ROLES   = "success get user roles"
+	MESSAGE_SUCCESS_UPDATE_USER_ROLE = "success update user roles"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleAlreadyExists  = errors.New("role already exists")
+	ErrRoleProtected      = errors.New("built-in roles cannot be deleted and the admin role cannot be changed")
+	ErrRoleInUse          = errors.New("role is the primary role of existing users")
+	ErrPermissionNotFound = errors.New("unknown permission")
+	ErrGrantDenied        = errors.New("cannot grant permissions you do not hold")
+)
+
+type (
+	RoleCreateRequest struct {
+		Name        string   `json:"name" binding:"required,min=2,max=50"`
+		Description string   `json:"description" binding:"omitempty,max=255"`
+		Permissions []string `json:"permissions" binding:"omitempty,dive,required"`
+	}
+
+	// RoleUpdateRequest leaves fields that are omitted unchanged. An empty
+	// permissions list removes every permission.
+	RoleUpdateRequest struct {
+		Description *string  `json:"description" binding:"omitempty,max=255"`
+		Permissions []string `json:"permissions" binding:"omitempty,dive,required"`
+	}
+
+	RoleResponse struct {
+		ID          string    `json:"id"`
+		Name        string    `json:"name"`
+		Description string    `json:"description"`
+		IsSystem    bool      `json:"is_system"`
+		Permissions []string  `json:"permissions"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+
+	PermissionsResponse struct {
+		Permissions []string `json:"permissions"`
+	}
+
+	// UserRolesRequest replaces the additional roles of a user. The primary
+	// role is changed through the user itself.
+	UserRolesRequest struct {
+		Roles []string `json:"roles" binding:"omitempty,dive,required"`
+	}
+
+	UserRolesResponse struct {
+		UserID      string   `json:"user_id"`
+		Role        string   `json:"role"`
+		Roles       []string `json:"roles"`
+		Permissions []string `json:"permissions"`
+	}
+)
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PermissionRepository interface {
+	Ensure(ctx context.Context, tx *gorm.DB, names []string) ([]entities.Permission, error)
+}
+
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &permissionRepository{
+		db: db,
+	}
+}
+
+// Ensure returns the permissions with the given names, creating the missing
+// ones, so permissions declared after the last seed can be granted.
+func (r *permissionRepository) Ensure(ctx context.Context, tx *gorm.DB, names []string) ([]entities.Permission, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	for _, name := range names {
+		permission := entities.Permission{ID: uuid.New(), Name: name}
+		if err := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&permission).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var permissions []entities.Permission
+	if err := tx.WithContext(ctx).Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
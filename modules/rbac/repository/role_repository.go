@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, tx *gorm.DB, role entities.Role) (entities.Role, error)
+	FindAll(ctx context.Context, tx *gorm.DB) ([]entities.Role, error)
+	FindByID(ctx context.Context, tx *gorm.DB, id string) (entities.Role, error)
+	FindByName(ctx context.Context, tx *gorm.DB, name string) (entities.Role, error)
+	FindByNames(ctx context.Context, tx *gorm.DB, names []string) ([]entities.Role, error)
+	UpdateDescription(ctx context.Context, tx *gorm.DB, id string, description string) error
+	Delete(ctx context.Context, tx *gorm.DB, id string) error
+	CountPrimaryUsers(ctx context.Context, tx *gorm.DB, name string) (int64, error)
+	GetPermissionNames(ctx context.Context, tx *gorm.DB, roleIds []uuid.UUID) (map[uuid.UUID][]string, error)
+	SetPermissions(ctx context.Context, tx *gorm.DB, roleId uuid.UUID, permissionIds []uuid.UUID) error
+	GetUserRoles(ctx context.Context, tx *gorm.DB, userId string) ([]entities.Role, error)
+	SetUserRoles(ctx context.Context, tx *gorm.DB, userId uuid.UUID, roleIds []uuid.UUID) error
+	GetUserPermissionNames(ctx context.Context, tx *gorm.DB, userId string, primaryRole string) ([]string, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+func (r *roleRepository) Create(ctx context.Context, tx *gorm.DB, role entities.Role) (entities.Role, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Create(&role).Error; err != nil {
+		return entities.Role{}, err
+	}
+
+	return role, nil
+}
+
+func (r *roleRepository) FindAll(ctx context.Context, tx *gorm.DB) ([]entities.Role, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var roles []entities.Role
+	if err := tx.WithContext(ctx).Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepository) FindByID(ctx context.Context, tx *gorm.DB, id string) (entities.Role, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var role entities.Role
+	if err := tx.WithContext(ctx).Where("id = ?", id).Take(&role).Error; err != nil {
+		return entities.Role{}, err
+	}
+
+	return role, nil
+}
+
+func (r *roleRepository) FindByName(ctx context.Context, tx *gorm.DB, name string) (entities.Role, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var role entities.Role
+	if err := tx.WithContext(ctx).Where("name = ?", name).Take(&role).Error; err != nil {
+		return entities.Role{}, err
+	}
+
+	return role, nil
+}
+
+func (r *roleRepository) FindByNames(ctx context.Context, tx *gorm.DB, names []string) ([]entities.Role, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var roles []entities.Role
+	if err := tx.WithContext(ctx).Where("name IN ?", names).Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepository) UpdateDescription(ctx context.Context, tx *gorm.DB, id string, description string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entities.Role{}).
+		Where("id = ?", id).
+		Update("description", description).Error
+}
+
+// Delete removes the role; its permissions and user assignments cascade.
+func (r *roleRepository) Delete(ctx context.Context, tx *gorm.DB, id string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).Where("id = ?", id).Delete(&entities.Role{}).Error
+}
+
+// CountPrimaryUsers counts the users, soft-deleted ones included, whose
+// primary role is the given one.
+func (r *roleRepository) CountPrimaryUsers(ctx context.Context, tx *gorm.DB, name string) (int64, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var count int64
+	if err := tx.WithContext(ctx).Unscoped().Model(&entities.User{}).Where("role = ?", name).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetPermissionNames returns the permission names of each role.
+func (r *roleRepository) GetPermissionNames(ctx context.Context, tx *gorm.DB, roleIds []uuid.UUID) (map[uuid.UUID][]string, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	names := make(map[uuid.UUID][]string, len(roleIds))
+	if len(roleIds) == 0 {
+		return names, nil
+	}
+
+	var rows []struct {
+		RoleID uuid.UUID
+		Name   string
+	}
+	if err := tx.WithContext(ctx).
+		Table("role_permissions").
+		Select("role_permissions.role_id, permissions.name").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("role_permissions.role_id IN ?", roleIds).
+		Order("permissions.name ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		names[row.RoleID] = append(names[row.RoleID], row.Name)
+	}
+
+	return names, nil
+}
+
+// SetPermissions replaces the permissions of a role.
+func (r *roleRepository) SetPermissions(ctx context.Context, tx *gorm.DB, roleId uuid.UUID, permissionIds []uuid.UUID) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("role_id = ?", roleId).Delete(&entities.RolePermission{}).Error; err != nil {
+		return err
+	}
+
+	if len(permissionIds) == 0 {
+		return nil
+	}
+
+	rolePermissions := make([]entities.RolePermission, 0, len(permissionIds))
+	for _, permissionId := range permissionIds {
+		rolePermissions = append(rolePermissions, entities.RolePermission{RoleID: roleId, PermissionID: permissionId})
+	}
+
+	return tx.WithContext(ctx).Create(&rolePermissions).Error
+}
+
+// GetUserRoles returns the additional roles granted to the user.
+func (r *roleRepository) GetUserRoles(ctx context.Context, tx *gorm.DB, userId string) ([]entities.Role, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var roles []entities.Role
+	if err := tx.WithContext(ctx).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userId).
+		Order("roles.name ASC").
+		Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// SetUserRoles replaces the additional roles of the user.
+func (r *roleRepository) SetUserRoles(ctx context.Context, tx *gorm.DB, userId uuid.UUID, roleIds []uuid.UUID) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	if err := tx.WithContext(ctx).Where("user_id = ?", userId).Delete(&entities.UserRole{}).Error; err != nil {
+		return err
+	}
+
+	if len(roleIds) == 0 {
+		return nil
+	}
+
+	userRoles := make([]entities.UserRole, 0, len(roleIds))
+	for _, roleId := range roleIds {
+		userRoles = append(userRoles, entities.UserRole{UserID: userId, RoleID: roleId})
+	}
+
+	return tx.WithContext(ctx).Create(&userRoles).Error
+}
+
+// GetUserPermissionNames returns the distinct permissions granted by the
+// primary role and every additional role of the user.
+func (r *roleRepository) GetUserPermissionNames(ctx context.Context, tx *gorm.DB, userId string, primaryRole string) ([]string, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var names []string
+	if err := tx.WithContext(ctx).
+		Table("permissions").
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ? OR roles.id IN (?)", primaryRole,
+			tx.Model(&entities.UserRole{}).Select("role_id").Where("user_id = ?", userId)).
+		Order("permissions.name ASC").
+		Pluck("permissions.name", &names).Error; err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
@@ -0,0 +1,30 @@
+package rbac
+
+import (
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+)
+
+func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
+	rbacController := do.MustInvoke[controller.RBACController](injector)
+	authenticator := do.MustInvokeNamed[service.Authenticator](injector, constants.Authenticator)
+
+	read := middlewares.RequirePermission(constants.PERMISSION_ROLE_READ)
+	write := middlewares.RequirePermission(constants.PERMISSION_ROLE_WRITE)
+
+	adminRoutes := server.Group("/api/admin", middlewares.Authenticate(authenticator))
+	{
+		adminRoutes.GET("/permissions", read, rbacController.GetPermissions)
+		adminRoutes.GET("/roles", read, rbacController.GetRoles)
+		adminRoutes.POST("/roles", write, rbacController.CreateRole)
+		adminRoutes.GET("/roles/:id", read, rbacController.GetRole)
+		adminRoutes.PATCH("/roles/:id", write, rbacController.UpdateRole)
+		adminRoutes.DELETE("/roles/:id", write, rbacController.DeleteRole)
+		adminRoutes.GET("/users/:id/roles", read, rbacController.GetUserRoles)
+		adminRoutes.PUT("/users/:id/roles", write, rbacController.SetUserRoles)
+	}
+}
@@ -0,0 +1,443 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac/repository"
+	userDto "github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	userRepo "github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RBACService manages roles and resolves the permissions of users. A user has
+// a primary role, User.Role, and any number of additional roles; they are
+// granted the permissions of all of them. Callers can only grant permissions
+// they hold themselves.
+type RBACService interface {
+	ListPermissions() dto.PermissionsResponse
+	ListRoles(ctx context.Context) ([]dto.RoleResponse, error)
+	GetRole(ctx context.Context, roleId string) (dto.RoleResponse, error)
+	CreateRole(ctx context.Context, actor authDto.Principal, req dto.RoleCreateRequest) (dto.RoleResponse, error)
+	UpdateRole(ctx context.Context, actor authDto.Principal, roleId string, req dto.RoleUpdateRequest) (dto.RoleResponse, error)
+	DeleteRole(ctx context.Context, roleId string) error
+	GetUserRoles(ctx context.Context, userId string) (dto.UserRolesResponse, error)
+	SetUserRoles(ctx context.Context, actor authDto.Principal, userId string, req dto.UserRolesRequest) (dto.UserRolesResponse, error)
+	ValidateRole(ctx context.Context, tx *gorm.DB, name string) error
+	CheckGrant(ctx context.Context, tx *gorm.DB, actor authDto.Principal, roleName string) error
+	CheckUserGrant(ctx context.Context, tx *gorm.DB, actor authDto.Principal, user entities.User) error
+	GetUserPermissions(ctx context.Context, tx *gorm.DB, user entities.User) ([]string, error)
+}
+
+type rbacService struct {
+	roleRepository       repository.RoleRepository
+	permissionRepository repository.PermissionRepository
+	userRepository       userRepo.UserRepository
+	db                   *gorm.DB
+}
+
+func NewRBACService(
+	roleRepo repository.RoleRepository,
+	permissionRepo repository.PermissionRepository,
+	userRepo userRepo.UserRepository,
+	db *gorm.DB,
+) RBACService {
+	return &rbacService{
+		roleRepository:       roleRepo,
+		permissionRepository: permissionRepo,
+		userRepository:       userRepo,
+		db:                   db,
+	}
+}
+
+func toRoleResponse(role entities.Role, permissions []string) dto.RoleResponse {
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return dto.RoleResponse{
+		ID:          role.ID.String(),
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// ListPermissions returns the permissions declared in code, which are the
+// ones that can be granted.
+func (s *rbacService) ListPermissions() dto.PermissionsResponse {
+	return dto.PermissionsResponse{Permissions: constants.Permissions}
+}
+
+func (s *rbacService) ListRoles(ctx context.Context) ([]dto.RoleResponse, error) {
+	roles, err := s.roleRepository.FindAll(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIds := make([]uuid.UUID, 0, len(roles))
+	for _, role := range roles {
+		roleIds = append(roleIds, role.ID)
+	}
+
+	permissions, err := s.roleRepository.GetPermissionNames(ctx, s.db, roleIds)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responses = append(responses, toRoleResponse(role, permissions[role.ID]))
+	}
+
+	return responses, nil
+}
+
+func (s *rbacService) GetRole(ctx context.Context, roleId string) (dto.RoleResponse, error) {
+	role, err := s.getRole(ctx, s.db, roleId)
+	if err != nil {
+		return dto.RoleResponse{}, err
+	}
+
+	return s.roleResponse(ctx, s.db, role)
+}
+
+func (s *rbacService) CreateRole(ctx context.Context, actor authDto.Principal, req dto.RoleCreateRequest) (dto.RoleResponse, error) {
+	var response dto.RoleResponse
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := s.roleRepository.FindByName(ctx, tx, req.Name); err == nil {
+			return dto.ErrRoleAlreadyExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		role, err := s.roleRepository.Create(ctx, tx, entities.Role{
+			ID:          uuid.New(),
+			Name:        req.Name,
+			Description: req.Description,
+		})
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return dto.ErrRoleAlreadyExists
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.setPermissions(ctx, tx, actor, role, req.Permissions); err != nil {
+			return err
+		}
+
+		response, err = s.roleResponse(ctx, tx, role)
+		return err
+	})
+
+	return response, err
+}
+
+// UpdateRole changes the description and, when given, replaces the
+// permissions. Users holding the role get the new permissions with their next
+// access token.
+func (s *rbacService) UpdateRole(
+	ctx context.Context,
+	actor authDto.Principal,
+	roleId string,
+	req dto.RoleUpdateRequest,
+) (dto.RoleResponse, error) {
+	var response dto.RoleResponse
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role, err := s.getRole(ctx, tx, roleId)
+		if err != nil {
+			return err
+		}
+		if role.Name == constants.ENUM_ROLE_ADMIN {
+			return dto.ErrRoleProtected
+		}
+
+		if req.Description != nil {
+			if err := s.roleRepository.UpdateDescription(ctx, tx, roleId, *req.Description); err != nil {
+				return err
+			}
+			role.Description = *req.Description
+		}
+
+		if req.Permissions != nil {
+			if err := s.setPermissions(ctx, tx, actor, role, req.Permissions); err != nil {
+				return err
+			}
+		}
+
+		response, err = s.roleResponse(ctx, tx, role)
+		return err
+	})
+
+	return response, err
+}
+
+// DeleteRole removes a custom role that is nobody's primary role. Additional
+// grants of the role are removed with it.
+func (s *rbacService) DeleteRole(ctx context.Context, roleId string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role, err := s.getRole(ctx, tx, roleId)
+		if err != nil {
+			return err
+		}
+		if role.IsSystem {
+			return dto.ErrRoleProtected
+		}
+
+		count, err := s.roleRepository.CountPrimaryUsers(ctx, tx, role.Name)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return dto.ErrRoleInUse
+		}
+
+		return s.roleRepository.Delete(ctx, tx, roleId)
+	})
+}
+
+func (s *rbacService) GetUserRoles(ctx context.Context, userId string) (dto.UserRolesResponse, error) {
+	user, err := s.getUser(ctx, s.db, userId)
+	if err != nil {
+		return dto.UserRolesResponse{}, err
+	}
+
+	return s.userRolesResponse(ctx, s.db, user)
+}
+
+// SetUserRoles replaces the additional roles of a user. Roles the user keeps
+// are not checked again, so a caller can remove roles it could not grant.
+func (s *rbacService) SetUserRoles(
+	ctx context.Context,
+	actor authDto.Principal,
+	userId string,
+	req dto.UserRolesRequest,
+) (dto.UserRolesResponse, error) {
+	var response dto.UserRolesResponse
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := s.getUser(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+
+		names := uniqueSorted(req.Roles)
+		roles, err := s.roleRepository.FindByNames(ctx, tx, names)
+		if err != nil {
+			return err
+		}
+		if len(roles) != len(names) {
+			return dto.ErrRoleNotFound
+		}
+
+		current, err := s.roleRepository.GetUserRoles(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+
+		roleIds := make([]uuid.UUID, 0, len(roles))
+		for _, role := range roles {
+			roleIds = append(roleIds, role.ID)
+
+			held := slices.ContainsFunc(current, func(r entities.Role) bool { return r.ID == role.ID })
+			if held {
+				continue
+			}
+			if err := s.CheckGrant(ctx, tx, actor, role.Name); err != nil {
+				return err
+			}
+		}
+
+		if err := s.roleRepository.SetUserRoles(ctx, tx, user.ID, roleIds); err != nil {
+			return err
+		}
+
+		response, err = s.userRolesResponse(ctx, tx, user)
+		return err
+	})
+
+	return response, err
+}
+
+// ValidateRole returns ErrRoleNotFound unless a role with the name exists.
+func (s *rbacService) ValidateRole(ctx context.Context, tx *gorm.DB, name string) error {
+	_, err := s.roleRepository.FindByName(ctx, tx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.ErrRoleNotFound
+	}
+
+	return err
+}
+
+// CheckGrant returns ErrGrantDenied unless the actor holds every permission of
+// the role, so that nobody can hand out more than they have.
+func (s *rbacService) CheckGrant(ctx context.Context, tx *gorm.DB, actor authDto.Principal, roleName string) error {
+	role, err := s.roleRepository.FindByName(ctx, tx, roleName)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.ErrRoleNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	permissions, err := s.roleRepository.GetPermissionNames(ctx, tx, []uuid.UUID{role.ID})
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range permissions[role.ID] {
+		if !actor.HasPermission(permission) {
+			return dto.ErrGrantDenied
+		}
+	}
+
+	return nil
+}
+
+// CheckUserGrant returns ErrGrantDenied unless the actor holds every
+// permission of the user, so that nobody can take over, lock out or remove an
+// account more privileged than their own.
+func (s *rbacService) CheckUserGrant(ctx context.Context, tx *gorm.DB, actor authDto.Principal, user entities.User) error {
+	permissions, err := s.GetUserPermissions(ctx, tx, user)
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if !actor.HasPermission(permission) {
+			return dto.ErrGrantDenied
+		}
+	}
+
+	return nil
+}
+
+// GetUserPermissions returns the permissions to embed in the user's access
+// tokens. Built-in roles fall back to constants.RolePermissions when they have
+// not been stored yet.
+func (s *rbacService) GetUserPermissions(ctx context.Context, tx *gorm.DB, user entities.User) ([]string, error) {
+	permissions, err := s.roleRepository.GetUserPermissionNames(ctx, tx, user.ID.String(), user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions) > 0 {
+		return permissions, nil
+	}
+
+	_, err = s.roleRepository.FindByName(ctx, tx, user.Role)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return append([]string{}, constants.RolePermissions[user.Role]...), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{}, nil
+}
+
+// setPermissions replaces the permissions of the role with declared ones the
+// actor holds.
+func (s *rbacService) setPermissions(
+	ctx context.Context,
+	tx *gorm.DB,
+	actor authDto.Principal,
+	role entities.Role,
+	names []string,
+) error {
+	names = uniqueSorted(names)
+	for _, name := range names {
+		if !slices.Contains(constants.Permissions, name) {
+			return dto.ErrPermissionNotFound
+		}
+		if !actor.HasPermission(name) {
+			return dto.ErrGrantDenied
+		}
+	}
+
+	permissions, err := s.permissionRepository.Ensure(ctx, tx, names)
+	if err != nil {
+		return err
+	}
+
+	permissionIds := make([]uuid.UUID, 0, len(permissions))
+	for _, permission := range permissions {
+		permissionIds = append(permissionIds, permission.ID)
+	}
+
+	return s.roleRepository.SetPermissions(ctx, tx, role.ID, permissionIds)
+}
+
+func (s *rbacService) roleResponse(ctx context.Context, tx *gorm.DB, role entities.Role) (dto.RoleResponse, error) {
+	permissions, err := s.roleRepository.GetPermissionNames(ctx, tx, []uuid.UUID{role.ID})
+	if err != nil {
+		return dto.RoleResponse{}, err
+	}
+
+	return toRoleResponse(role, permissions[role.ID]), nil
+}
+
+func (s *rbacService) userRolesResponse(ctx context.Context, tx *gorm.DB, user entities.User) (dto.UserRolesResponse, error) {
+	roles, err := s.roleRepository.GetUserRoles(ctx, tx, user.ID.String())
+	if err != nil {
+		return dto.UserRolesResponse{}, err
+	}
+
+	permissions, err := s.GetUserPermissions(ctx, tx, user)
+	if err != nil {
+		return dto.UserRolesResponse{}, err
+	}
+
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+
+	return dto.UserRolesResponse{
+		UserID:      user.ID.String(),
+		Role:        user.Role,
+		Roles:       names,
+		Permissions: permissions,
+	}, nil
+}
+
+// getRole looks a role up by id, treating malformed ids as unknown roles.
+func (s *rbacService) getRole(ctx context.Context, tx *gorm.DB, roleId string) (entities.Role, error) {
+	if _, err := uuid.Parse(roleId); err != nil {
+		return entities.Role{}, dto.ErrRoleNotFound
+	}
+
+	role, err := s.roleRepository.FindByID(ctx, tx, roleId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.Role{}, dto.ErrRoleNotFound
+	}
+
+	return role, err
+}
+
+func (s *rbacService) getUser(ctx context.Context, tx *gorm.DB, userId string) (entities.User, error) {
+	if _, err := uuid.Parse(userId); err != nil {
+		return entities.User{}, userDto.ErrUserNotFound
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, tx, userId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.User{}, userDto.ErrUserNotFound
+	}
+
+	return user, err
+}
+
+func uniqueSorted(values []string) []string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return slices.Compact(values)
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthorizedRouter(principal *authDto.Principal, guard gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", func(ctx *gin.Context) {
+		if principal != nil {
+			ctx.Set("principal", *principal)
+		}
+		ctx.Next()
+	}, guard, func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return router
+}
+
+func serve(router *gin.Engine) int {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	return recorder.Code
+}
+
+func TestRequireRole_Allowed(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_ADMIN)
+	router := newAuthorizedRouter(&principal, middlewares.RequireRole(constants.ENUM_ROLE_ADMIN))
+
+	assert.Equal(t, http.StatusOK, serve(router))
+}
+
+func TestRequireRole_Forbidden(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	router := newAuthorizedRouter(&principal, middlewares.RequireRole(constants.ENUM_ROLE_ADMIN))
+
+	assert.Equal(t, http.StatusForbidden, serve(router))
+}
+
+func TestRequireRole_Unauthenticated(t *testing.T) {
+	router := newAuthorizedRouter(nil, middlewares.RequireRole(constants.ENUM_ROLE_ADMIN))
+
+	assert.Equal(t, http.StatusUnauthorized, serve(router))
+}
+
+func TestRequirePermission_AdminWildcard(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_ADMIN)
+	router := newAuthorizedRouter(&principal, middlewares.RequirePermission(constants.PERMISSION_USER_WRITE))
+
+	assert.Equal(t, http.StatusOK, serve(router))
+}
+
+func TestRequirePermission_Forbidden(t *testing.T) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	router := newAuthorizedRouter(&principal, middlewares.RequirePermission(constants.PERMISSION_USER_READ))
+
+	assert.Equal(t, http.StatusForbidden, serve(router))
+}
+
+func serveUser(principal authDto.Principal, userId string) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/users/:id", func(ctx *gin.Context) {
+		ctx.Set("principal", principal)
+		ctx.Next()
+	}, middlewares.RequireSelfOrPermission("id", constants.PERMISSION_USER_WRITE), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/users/"+userId, nil))
+	return recorder.Code
+}
+
+func TestRequireSelfOrPermission(t *testing.T) {
+	user := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	admin := authDto.NewPrincipal("admin-id", constants.ENUM_ROLE_ADMIN)
+
+	assert.Equal(t, http.StatusOK, serveUser(user, "user-id"))
+	assert.Equal(t, http.StatusForbidden, serveUser(user, "other-id"))
+	assert.Equal(t, http.StatusOK, serveUser(admin, "other-id"))
+}
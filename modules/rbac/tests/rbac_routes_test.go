@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAuthenticator treats every bearer token as the name of a role.
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) AuthenticateAccessToken(_ context.Context, token string) (authDto.Principal, error) {
+	return authDto.NewPrincipal("actor-id", token), nil
+}
+
+func (stubAuthenticator) AuthenticateAPIKey(context.Context, string, string) (authDto.Principal, error) {
+	return authDto.Principal{}, authDto.ErrAPIKeyInvalid
+}
+
+type stubRBACService struct {
+	service.RBACService
+	actor authDto.Principal
+}
+
+func (s *stubRBACService) ListPermissions() dto.PermissionsResponse {
+	return dto.PermissionsResponse{Permissions: constants.Permissions}
+}
+
+func (s *stubRBACService) CreateRole(_ context.Context, actor authDto.Principal, req dto.RoleCreateRequest) (dto.RoleResponse, error) {
+	s.actor = actor
+	if req.Name == "support" {
+		return dto.RoleResponse{}, dto.ErrRoleAlreadyExists
+	}
+	return dto.RoleResponse{Name: req.Name, Permissions: req.Permissions}, nil
+}
+
+func (s *stubRBACService) DeleteRole(_ context.Context, roleId string) error {
+	switch roleId {
+	case "custom":
+		return nil
+	case "admin":
+		return dto.ErrRoleProtected
+	case "assigned":
+		return dto.ErrRoleInUse
+	default:
+		return dto.ErrRoleNotFound
+	}
+}
+
+func (s *stubRBACService) SetUserRoles(_ context.Context, _ authDto.Principal, userId string, req dto.UserRolesRequest) (dto.UserRolesResponse, error) {
+	for _, role := range req.Roles {
+		if role == constants.ENUM_ROLE_ADMIN {
+			return dto.UserRolesResponse{}, dto.ErrGrantDenied
+		}
+	}
+	return dto.UserRolesResponse{UserID: userId, Roles: req.Roles}, nil
+}
+
+func newRBACRouter(rbacService service.RBACService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	injector := do.New()
+	do.ProvideNamedValue[authService.Authenticator](injector, constants.Authenticator, stubAuthenticator{})
+	do.Provide(injector, func(i *do.Injector) (controller.RBACController, error) {
+		return controller.NewRBACController(rbacService), nil
+	})
+
+	router := gin.New()
+	rbac.RegisterRoutes(router, injector)
+	return router
+}
+
+func request(router *gin.Engine, method string, path string, role string, body string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+role)
+	req.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestRBACRoutes_RequirePermission(t *testing.T) {
+	router := newRBACRouter(&stubRBACService{})
+
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodGet, "/api/admin/permissions", constants.ENUM_ROLE_USER, ""))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodPost, "/api/admin/roles", constants.ENUM_ROLE_USER, `{"name":"editor"}`))
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/api/admin/permissions", constants.ENUM_ROLE_ADMIN, ""))
+}
+
+func TestRBACRoutes_CreateRole(t *testing.T) {
+	rbacService := &stubRBACService{}
+	router := newRBACRouter(rbacService)
+
+	assert.Equal(t, http.StatusCreated, request(router, http.MethodPost, "/api/admin/roles", constants.ENUM_ROLE_ADMIN, `{"name":"editor","permissions":["users:read"]}`))
+	assert.Equal(t, "actor-id", rbacService.actor.UserID)
+
+	assert.Equal(t, http.StatusConflict, request(router, http.MethodPost, "/api/admin/roles", constants.ENUM_ROLE_ADMIN, `{"name":"support"}`))
+	assert.Equal(t, http.StatusBadRequest, request(router, http.MethodPost, "/api/admin/roles", constants.ENUM_ROLE_ADMIN, `{}`))
+}
+
+func TestRBACRoutes_DeleteRole(t *testing.T) {
+	router := newRBACRouter(&stubRBACService{})
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodDelete, "/api/admin/roles/custom", constants.ENUM_ROLE_ADMIN, ""))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodDelete, "/api/admin/roles/admin", constants.ENUM_ROLE_ADMIN, ""))
+	assert.Equal(t, http.StatusConflict, request(router, http.MethodDelete, "/api/admin/roles/assigned", constants.ENUM_ROLE_ADMIN, ""))
+	assert.Equal(t, http.StatusNotFound, request(router, http.MethodDelete, "/api/admin/roles/unknown", constants.ENUM_ROLE_ADMIN, ""))
+}
+
+func TestRBACRoutes_SetUserRoles(t *testing.T) {
+	router := newRBACRouter(&stubRBACService{})
+
+	assert.Equal(t, http.StatusOK, request(router, http.MethodPut, "/api/admin/users/user-id/roles", constants.ENUM_ROLE_ADMIN, `{"roles":["editor"]}`))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodPut, "/api/admin/users/user-id/roles", constants.ENUM_ROLE_ADMIN, `{"roles":["admin"]}`))
+}
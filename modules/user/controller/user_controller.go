@@ -1,8 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/Caknoooo/go-gin-clean-starter/middlewares"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	rbacDto "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user/query"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user/service"
@@ -21,6 +25,10 @@ type (
 		GetAllUser(ctx *gin.Context)
 		Update(ctx *gin.Context)
 		Delete(ctx *gin.Context)
+		Unlock(ctx *gin.Context)
+		ChangePassword(ctx *gin.Context)
+		ConfirmEmailChange(ctx *gin.Context)
+		CancelEmailChange(ctx *gin.Context)
 	}
 
 	userController struct {
@@ -68,6 +76,10 @@ func (c *userController) Me(ctx *gin.Context) {
 		return
 	}
 
+	if principal, ok := middlewares.GetPrincipal(ctx); ok {
+		result.ImpersonatedBy = principal.ImpersonatorID
+	}
+
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_USER, result)
 	ctx.JSON(http.StatusOK, res)
 }
@@ -86,11 +98,24 @@ func (c *userController) Update(ctx *gin.Context) {
 		return
 	}
 
-	userId := ctx.MustGet("user_id").(string)
-	result, err := c.userService.Update(ctx.Request.Context(), req, userId)
+	principal, _ := middlewares.GetPrincipal(ctx)
+	if principal.ImpersonatorID != "" && req.Email != "" {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_USER, authDto.ErrImpersonationForbidden.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, res)
+		return
+	}
+
+	result, err := c.userService.Update(ctx.Request.Context(), principal, req, targetUserId(ctx))
 	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, dto.ErrEmailAlreadyExists):
+			status = http.StatusConflict
+		case errors.Is(err, rbacDto.ErrGrantDenied):
+			status = http.StatusForbidden
+		}
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_USER, err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
+		ctx.JSON(status, res)
 		return
 	}
 
@@ -99,14 +124,115 @@ func (c *userController) Update(ctx *gin.Context) {
 }
 
 func (c *userController) Delete(ctx *gin.Context) {
-	userId := ctx.MustGet("user_id").(string)
+	principal, _ := middlewares.GetPrincipal(ctx)
 
-	if err := c.userService.Delete(ctx.Request.Context(), userId); err != nil {
+	if err := c.userService.Delete(ctx.Request.Context(), principal, targetUserId(ctx)); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, rbacDto.ErrGrantDenied) {
+			status = http.StatusForbidden
+		}
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_USER, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		ctx.AbortWithStatusJSON(status, res)
 		return
 	}
 
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_USER, nil)
 	ctx.JSON(http.StatusOK, res)
 }
+
+func (c *userController) Unlock(ctx *gin.Context) {
+	client := authDto.ClientInfo{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+
+	if err := c.userService.Unlock(ctx.Request.Context(), ctx.Param("id"), client); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UNLOCK_USER, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UNLOCK_USER, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) ChangePassword(ctx *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	userId := ctx.MustGet("user_id").(string)
+
+	if err := c.userService.ChangePassword(ctx.Request.Context(), userId, ctx.GetString("session_id"), req); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrPasswordIncorrect) {
+			status = http.StatusForbidden
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CHANGE_PASSWORD, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CHANGE_PASSWORD, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) ConfirmEmailChange(ctx *gin.Context) {
+	var req dto.EmailChangeTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.userService.ConfirmEmailChange(ctx.Request.Context(), req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrEmailAlreadyExists) {
+			status = http.StatusConflict
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CONFIRM_EMAIL, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CONFIRM_EMAIL, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *userController) CancelEmailChange(ctx *gin.Context) {
+	var req dto.EmailChangeTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.userService.CancelEmailChange(ctx.Request.Context(), req); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, dto.ErrEmailAlreadyExists) {
+			status = http.StatusConflict
+		}
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CANCEL_EMAIL, err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CANCEL_EMAIL, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// targetUserId is the user named in the path, or the caller on the /me routes.
+func targetUserId(ctx *gin.Context) string {
+	if userId := ctx.Param("id"); userId != "" {
+		return userId
+	}
+	return ctx.MustGet("user_id").(string)
+}
@@ -19,6 +19,10 @@ const (
 	MESSAGE_FAILED_PROSES_REQUEST     = "failed proses request"
 	MESSAGE_FAILED_DENIED_ACCESS      = "denied access"
 	MESSAGE_FAILED_VERIFY_EMAIL       = "failed verify email"
+	MESSAGE_FAILED_UNLOCK_USER        = "failed unlock user"
+	MESSAGE_FAILED_CHANGE_PASSWORD    = "failed change password"
+	MESSAGE_FAILED_CONFIRM_EMAIL      = "failed confirm email change"
+	MESSAGE_FAILED_CANCEL_EMAIL       = "failed cancel email change"
 
 	// Success
 	MESSAGE_SUCCESS_REGISTER_USER           = "success create user"
@@ -29,6 +33,10 @@ const (
 	MESSAGE_SUCCESS_DELETE_USER             = "success delete user"
 	MESSAGE_SEND_VERIFICATION_EMAIL_SUCCESS = "success send verification email"
 	MESSAGE_SUCCESS_VERIFY_EMAIL            = "success verify email"
+	MESSAGE_SUCCESS_UNLOCK_USER             = "success unlock user"
+	MESSAGE_SUCCESS_CHANGE_PASSWORD         = "success change password"
+	MESSAGE_SUCCESS_CONFIRM_EMAIL           = "success confirm email change"
+	MESSAGE_SUCCESS_CANCEL_EMAIL            = "success cancel email change"
 )
 
 var (
@@ -41,6 +49,8 @@ var (
 	ErrEmailNotFound          = errors.New("email not found")
 	ErrDeleteUser             = errors.New("failed to delete user")
 	ErrTokenInvalid           = errors.New("token invalid")
+	ErrPasswordIncorrect      = errors.New("current password is incorrect")
+	ErrNoPendingEmailChange   = errors.New("no pending email change")
 	ErrTokenExpired           = errors.New("token expired")
 	ErrAccountAlreadyVerified = errors.New("account already verified")
 )
@@ -50,18 +60,24 @@ type (
 		Name       string                `json:"name" form:"name" binding:"required,min=2,max=100"`
 		TelpNumber string                `json:"telp_number" form:"telp_number" binding:"omitempty,min=8,max=20"`
 		Email      string                `json:"email" form:"email" binding:"required,email"`
-		Password   string                `json:"password" form:"password" binding:"required,min=8"`
+		Password   string                `json:"password" form:"password" binding:"required"`
 		Image      *multipart.FileHeader `json:"image" form:"image"`
+		// InviteToken redeems an invitation, which is required when
+		// registration is invite only.
+		InviteToken string `json:"invite_token" form:"invite_token"`
 	}
 
 	UserResponse struct {
-		ID         string `json:"id"`
-		Name       string `json:"name"`
-		Email      string `json:"email"`
-		TelpNumber string `json:"telp_number"`
-		Role       string `json:"role"`
-		ImageUrl   string `json:"image_url"`
-		IsVerified bool   `json:"is_verified"`
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		Email        string `json:"email"`
+		PendingEmail string `json:"pending_email,omitempty"`
+		TelpNumber   string `json:"telp_number"`
+		Role         string `json:"role"`
+		ImageUrl     string `json:"image_url"`
+		IsVerified   bool   `json:"is_verified"`
+		// ImpersonatedBy is the admin acting as the user, if any.
+		ImpersonatedBy string `json:"impersonated_by,omitempty"`
 	}
 	UserUpdateRequest struct {
 		Name       string `json:"name" form:"name" binding:"omitempty,min=2,max=100"`
@@ -70,12 +86,13 @@ type (
 	}
 
 	UserUpdateResponse struct {
-		ID         string `json:"id"`
-		Name       string `json:"name"`
-		TelpNumber string `json:"telp_number"`
-		Role       string `json:"role"`
-		Email      string `json:"email"`
-		IsVerified bool   `json:"is_verified"`
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		TelpNumber   string `json:"telp_number"`
+		Role         string `json:"role"`
+		Email        string `json:"email"`
+		PendingEmail string `json:"pending_email,omitempty"`
+		IsVerified   bool   `json:"is_verified"`
 	}
 
 	SendVerificationEmailRequest struct {
@@ -91,8 +108,18 @@ type (
 		IsVerified bool   `json:"is_verified"`
 	}
 
+	EmailChangeTokenRequest struct {
+		Token string `json:"token" form:"token" binding:"required"`
+	}
+
+	ChangePasswordRequest struct {
+		CurrentPassword string `json:"current_password" form:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" form:"new_password" binding:"required"`
+	}
+
 	UserLoginRequest struct {
-		Email    string `json:"email" form:"email" binding:"required"`
-		Password string `json:"password" form:"password" binding:"required"`
+		Email      string `json:"email" form:"email" binding:"required"`
+		Password   string `json:"password" form:"password" binding:"required"`
+		DeviceName string `json:"device_name" form:"device_name" binding:"omitempty,max=100"`
 	}
 )
@@ -0,0 +1,69 @@
+package user
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/scheduler"
+	"github.com/samber/do"
+	"gorm.io/gorm"
+)
+
+const defaultDeletedUserRetention = time.Hour * 24 * 30
+
+// RegisterJobs schedules the user housekeeping jobs. Deleted accounts are
+// purged for good after DELETED_USER_RETENTION. Accounts never verified within
+// UNVERIFIED_USER_RETENTION are soft-deleted, but only when it is set since
+// unverified users can still sign in.
+func RegisterJobs(s *scheduler.Scheduler, injector *do.Injector) error {
+	userRepository := repository.NewUserRepository(do.MustInvokeNamed[*gorm.DB](injector, constants.DB))
+
+	jobs := []scheduler.Job{
+		{
+			Name:     "purge_deleted_users",
+			Schedule: "30 3 * * *",
+			Run: func(ctx context.Context) error {
+				purged, err := PurgeDeletedUsers(ctx, userRepository)
+				if err != nil {
+					return err
+				}
+				log.Printf("scheduler: purged %d deleted users", purged)
+				return nil
+			},
+		},
+	}
+
+	if unverifiedRetention := helpers.GetDurationEnv("UNVERIFIED_USER_RETENTION", 0); unverifiedRetention > 0 {
+		jobs = append(jobs, scheduler.Job{
+			Name:     "delete_unverified_users",
+			Schedule: "0 3 * * *",
+			Run: func(ctx context.Context) error {
+				deleted, err := userRepository.DeleteUnverifiedBefore(ctx, nil, time.Now().Add(-unverifiedRetention))
+				if err != nil {
+					return err
+				}
+				log.Printf("scheduler: deleted %d unverified users", deleted)
+				return nil
+			},
+		})
+	}
+
+	for _, job := range jobs {
+		if err := s.Register(job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeDeletedUsers permanently removes users deleted longer ago than
+// DELETED_USER_RETENTION.
+func PurgeDeletedUsers(ctx context.Context, userRepository repository.UserRepository) (int64, error) {
+	retention := helpers.GetDurationEnv("DELETED_USER_RETENTION", defaultDeletedUserRetention)
+	return userRepository.PurgeDeletedBefore(ctx, nil, time.Now().Add(-retention))
+}
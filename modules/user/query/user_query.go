@@ -1,6 +1,8 @@
 package query
 
 import (
+	"time"
+
 	"github.com/Caknoooo/go-pagination"
 	"gorm.io/gorm"
 )
@@ -13,15 +15,25 @@ type User struct {
 	Role       string `json:"role"`
 	ImageUrl   string `json:"image_url"`
 	IsVerified bool   `json:"is_verified"`
+	IsActive   bool   `json:"is_active"`
+
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
 }
 
 type UserFilter struct {
 	pagination.BaseFilter
+
+	// Deleted lists soft-deleted users instead of live ones. It is set by
+	// the handler, never bound from the query string.
+	Deleted bool `form:"-"`
 }
 
 func (f *UserFilter) ApplyFilters(query *gorm.DB) *gorm.DB {
-	// Apply your filters here
-	return query
+	if f.Deleted {
+		return query.Where("deleted_at IS NOT NULL")
+	}
+	return query.Where("deleted_at IS NULL")
 }
 
 func (f *UserFilter) GetTableName() string {
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
 	"gorm.io/gorm"
@@ -14,7 +15,15 @@ type (
 		GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entities.User, error)
 		CheckEmail(ctx context.Context, tx *gorm.DB, email string) (entities.User, bool, error)
 		Update(ctx context.Context, tx *gorm.DB, user entities.User) (entities.User, error)
+		UpdateEmail(ctx context.Context, tx *gorm.DB, userId string, email string) error
+		UpdatePassword(ctx context.Context, tx *gorm.DB, userId string, hashedPassword string) error
+		ClearPendingEmail(ctx context.Context, tx *gorm.DB, userId string) error
+		Disable(ctx context.Context, tx *gorm.DB, userId string, reason string, disabledAt time.Time) error
+		Enable(ctx context.Context, tx *gorm.DB, userId string) error
 		Delete(ctx context.Context, tx *gorm.DB, userId string) error
+		Restore(ctx context.Context, tx *gorm.DB, userId string) (bool, error)
+		PurgeDeletedBefore(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error)
+		DeleteUnverifiedBefore(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error)
 	}
 
 	userRepository struct {
@@ -91,6 +100,78 @@ func (r *userRepository) Update(ctx context.Context, tx *gorm.DB, user entities.
 	return user, nil
 }
 
+// UpdateEmail switches the user to a confirmed address: the pending email is
+// cleared and the account counts as verified.
+func (r *userRepository) UpdateEmail(ctx context.Context, tx *gorm.DB, userId string, email string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entities.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]any{"email": email, "pending_email": nil, "is_verified": true}).Error
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, tx *gorm.DB, userId string, hashedPassword string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entities.User{}).
+		Where("id = ?", userId).
+		Update("password", hashedPassword).Error
+}
+
+func (r *userRepository) ClearPendingEmail(ctx context.Context, tx *gorm.DB, userId string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entities.User{}).
+		Where("id = ?", userId).
+		Update("pending_email", nil).Error
+}
+
+// Disable deactivates the account and records when and why. An empty reason
+// is stored as NULL.
+func (r *userRepository) Disable(ctx context.Context, tx *gorm.DB, userId string, reason string, disabledAt time.Time) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	var disabledReason *string
+	if reason != "" {
+		disabledReason = &reason
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entities.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]any{
+			"is_active":       false,
+			"disabled_at":     disabledAt,
+			"disabled_reason": disabledReason,
+		}).Error
+}
+
+func (r *userRepository) Enable(ctx context.Context, tx *gorm.DB, userId string) error {
+	if tx == nil {
+		tx = r.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entities.User{}).
+		Where("id = ?", userId).
+		Updates(map[string]any{
+			"is_active":       true,
+			"disabled_at":     nil,
+			"disabled_reason": nil,
+		}).Error
+}
+
 func (r *userRepository) Delete(ctx context.Context, tx *gorm.DB, userId string) error {
 	if tx == nil {
 		tx = r.db
@@ -102,3 +183,51 @@ func (r *userRepository) Delete(ctx context.Context, tx *gorm.DB, userId string)
 
 	return nil
 }
+
+// Restore undoes a soft delete. It reports false when no deleted user has the
+// given id.
+func (r *userRepository) Restore(ctx context.Context, tx *gorm.DB, userId string) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Unscoped().
+		Model(&entities.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", userId).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// PurgeDeletedBefore permanently removes users soft-deleted before the given
+// time, together with everything that cascades from them.
+func (r *userRepository) PurgeDeletedBefore(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&entities.User{})
+
+	return result.RowsAffected, result.Error
+}
+
+// DeleteUnverifiedBefore soft-deletes users who registered before the given
+// time and never verified their email.
+func (r *userRepository) DeleteUnverifiedBefore(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	result := tx.WithContext(ctx).
+		Where("is_verified = ? AND created_at < ?", false, before).
+		Delete(&entities.User{})
+
+	return result.RowsAffected, result.Error
+}
@@ -11,13 +11,23 @@ import (
 
 func RegisterRoutes(server *gin.Engine, injector *do.Injector) {
 	userController := do.MustInvoke[controller.UserController](injector)
-	jwtService := do.MustInvokeNamed[service.JWTService](injector, constants.JWTService)
+	authenticator := do.MustInvokeNamed[service.Authenticator](injector, constants.Authenticator)
 
 	userRoutes := server.Group("/api/user")
 	{
-		userRoutes.GET("", userController.GetAllUser)
-		userRoutes.GET("/me", middlewares.Authenticate(jwtService), userController.Me)
-		userRoutes.PUT("/:id", middlewares.Authenticate(jwtService), userController.Update)
-		userRoutes.DELETE("/:id", middlewares.Authenticate(jwtService), userController.Delete)
+		userRoutes.GET("", middlewares.Authenticate(authenticator), middlewares.RequirePermission(constants.PERMISSION_USER_READ), userController.GetAllUser)
+		userRoutes.GET("/me", middlewares.Authenticate(authenticator), userController.Me)
+		userRoutes.POST("/email/confirm", userController.ConfirmEmailChange)
+		userRoutes.POST("/email/cancel", userController.CancelEmailChange)
+		userRoutes.POST("/me/password", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), middlewares.DenyImpersonation(), userController.ChangePassword)
+		// Personal access tokens may read the profile but not change it: a
+		// new email would let the token holder take over the account.
+		userRoutes.PUT("/me", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), userController.Update)
+		userRoutes.DELETE("/me", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), middlewares.DenyImpersonation(), userController.Delete)
+		// Kept for clients of the id based routes. Callers other than the
+		// user need the user write permission.
+		userRoutes.PUT("/:id", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), middlewares.RequireSelfOrPermission("id", constants.PERMISSION_USER_WRITE), userController.Update)
+		userRoutes.DELETE("/:id", middlewares.Authenticate(authenticator), middlewares.DenyAPIKey(), middlewares.DenyImpersonation(), middlewares.RequireSelfOrPermission("id", constants.PERMISSION_USER_WRITE), userController.Delete)
+		userRoutes.POST("/:id/unlock", middlewares.Authenticate(authenticator), middlewares.RequirePermission(constants.PERMISSION_USER_WRITE), userController.Unlock)
 	}
 }
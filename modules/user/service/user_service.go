@@ -2,30 +2,67 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log"
+	"net/url"
+	"os"
 
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/utils"
 	"gorm.io/gorm"
 )
 
 type UserService interface {
 	GetUserById(ctx context.Context, userId string) (dto.UserResponse, error)
-	Update(ctx context.Context, req dto.UserUpdateRequest, userId string) (dto.UserUpdateResponse, error)
-	Delete(ctx context.Context, userId string) error
+	Update(ctx context.Context, actor authDto.Principal, req dto.UserUpdateRequest, userId string) (dto.UserUpdateResponse, error)
+	Delete(ctx context.Context, actor authDto.Principal, userId string) error
+	Unlock(ctx context.Context, userId string, client authDto.ClientInfo) error
+	ChangePassword(ctx context.Context, userId string, sessionId string, req dto.ChangePasswordRequest) error
+	ConfirmEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) (dto.UserResponse, error)
+	CancelEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) error
 }
 
 type userService struct {
-	userRepository repository.UserRepository
-	db             *gorm.DB
+	userRepository         repository.UserRepository
+	tokenRevocationService authService.TokenRevocationService
+	loginThrottleService   authService.LoginThrottleService
+	passwordPolicyService  authService.PasswordPolicyService
+	sessionService         authService.SessionService
+	oneTimeTokenService    authService.OneTimeTokenService
+	rbacService            rbacService.RBACService
+	emailChangeURL         string
+	emailCancelURL         string
+	db                     *gorm.DB
 }
 
 func NewUserService(
 	userRepo repository.UserRepository,
+	tokenRevocationService authService.TokenRevocationService,
+	loginThrottleService authService.LoginThrottleService,
+	passwordPolicyService authService.PasswordPolicyService,
+	sessionService authService.SessionService,
+	oneTimeTokenService authService.OneTimeTokenService,
+	rbacService rbacService.RBACService,
 	db *gorm.DB,
 ) UserService {
 	return &userService{
-		userRepository: userRepo,
-		db:             db,
+		userRepository:         userRepo,
+		tokenRevocationService: tokenRevocationService,
+		loginThrottleService:   loginThrottleService,
+		passwordPolicyService:  passwordPolicyService,
+		sessionService:         sessionService,
+		oneTimeTokenService:    oneTimeTokenService,
+		rbacService:            rbacService,
+		emailChangeURL:         os.Getenv("EMAIL_CHANGE_URL"),
+		emailCancelURL:         os.Getenv("EMAIL_CHANGE_CANCEL_URL"),
+		db:                     db,
 	}
 }
 
@@ -35,7 +72,11 @@ func (s *userService) GetUserById(ctx context.Context, userId string) (dto.UserR
 		return dto.UserResponse{}, err
 	}
 
-	return dto.UserResponse{
+	return toUserResponse(user), nil
+}
+
+func toUserResponse(user entities.User) dto.UserResponse {
+	response := dto.UserResponse{
 		ID:         user.ID.String(),
 		Name:       user.Name,
 		Email:      user.Email,
@@ -43,40 +84,297 @@ func (s *userService) GetUserById(ctx context.Context, userId string) (dto.UserR
 		Role:       user.Role,
 		ImageUrl:   user.ImageUrl,
 		IsVerified: user.IsVerified,
-	}, nil
+	}
+	if user.PendingEmail != nil {
+		response.PendingEmail = *user.PendingEmail
+	}
+
+	return response
 }
 
-func (s *userService) Update(ctx context.Context, req dto.UserUpdateRequest, userId string) (dto.UserUpdateResponse, error) {
-	user, err := s.userRepository.GetUserById(ctx, s.db, userId)
-	if err != nil {
-		return dto.UserUpdateResponse{}, dto.ErrUserNotFound
-	}
+// Update changes the profile. A new email is not applied right away: it is
+// kept as pending until confirmed through the link sent to it, and the current
+// address is told about the request with a link to cancel it.
+func (s *userService) Update(ctx context.Context, actor authDto.Principal, req dto.UserUpdateRequest, userId string) (dto.UserUpdateResponse, error) {
+	var confirmToken, cancelToken, previousEmail string
+	var updatedUser entities.User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := s.userRepository.GetUserById(ctx, tx, userId)
+		if err != nil {
+			return dto.ErrUserNotFound
+		}
 
-	if req.Name != "" {
-		user.Name = req.Name
-	}
-	if req.Email != "" {
-		user.Email = req.Email
-	}
-	if req.TelpNumber != "" {
-		user.TelpNumber = req.TelpNumber
-	}
+		if err := s.checkActor(ctx, tx, actor, user); err != nil {
+			return err
+		}
 
-	updatedUser, err := s.userRepository.Update(ctx, s.db, user)
+		if req.Name != "" {
+			user.Name = req.Name
+		}
+		if req.TelpNumber != "" {
+			user.TelpNumber = req.TelpNumber
+		}
+
+		if req.Email != "" && req.Email != user.Email {
+			_, isExist, err := s.userRepository.CheckEmail(ctx, tx, req.Email)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if isExist {
+				return dto.ErrEmailAlreadyExists
+			}
+
+			confirmToken, err = s.oneTimeTokenService.IssueWithPayload(ctx, tx, user.ID, constants.TOKEN_PURPOSE_EMAIL_CHANGE, req.Email)
+			if err != nil {
+				return err
+			}
+
+			// A cancel link for an earlier, already confirmed change must stay
+			// valid so the original owner can still restore their address.
+			outstanding, found, err := s.oneTimeTokenService.FindOutstanding(ctx, tx, user.ID, constants.TOKEN_PURPOSE_EMAIL_CHANGE_CANCEL)
+			if err != nil {
+				return err
+			}
+			if !found || outstanding.Payload == user.Email {
+				cancelToken, err = s.oneTimeTokenService.IssueWithPayload(ctx, tx, user.ID, constants.TOKEN_PURPOSE_EMAIL_CHANGE_CANCEL, user.Email)
+				if err != nil {
+					return err
+				}
+			}
+
+			previousEmail = user.Email
+			user.PendingEmail = &req.Email
+		}
+
+		updatedUser, err = s.userRepository.Update(ctx, tx, user)
+		return err
+	})
 	if err != nil {
 		return dto.UserUpdateResponse{}, err
 	}
 
-	return dto.UserUpdateResponse{
+	if confirmToken != "" {
+		if err := s.sendEmailChangeMails(*updatedUser.PendingEmail, previousEmail, confirmToken, cancelToken); err != nil {
+			return dto.UserUpdateResponse{}, err
+		}
+	}
+
+	response := dto.UserUpdateResponse{
 		ID:         updatedUser.ID.String(),
 		Name:       updatedUser.Name,
 		TelpNumber: updatedUser.TelpNumber,
 		Role:       updatedUser.Role,
 		Email:      updatedUser.Email,
 		IsVerified: updatedUser.IsVerified,
-	}, nil
+	}
+	if updatedUser.PendingEmail != nil {
+		response.PendingEmail = *updatedUser.PendingEmail
+	}
+
+	return response, nil
+}
+
+func (s *userService) sendEmailChangeMails(newEmail string, previousEmail string, confirmToken string, cancelToken string) error {
+	confirmBody := "Confirm your new email address using this token: " + confirmToken
+	if s.emailChangeURL != "" {
+		link := s.emailChangeURL + "?token=" + url.QueryEscape(confirmToken)
+		confirmBody = "Click <a href=\"" + link + "\">here</a> to confirm your new email address."
+	}
+
+	if err := utils.SendMail(newEmail, "Confirm Email Change", confirmBody); err != nil {
+		return err
+	}
+
+	cancelBody := "A request was made to change the email of your account to " + newEmail + "."
+	switch {
+	case cancelToken != "" && s.emailCancelURL != "":
+		link := s.emailCancelURL + "?token=" + url.QueryEscape(cancelToken)
+		cancelBody += " If this was not you, click <a href=\"" + link + "\">here</a> to cancel it."
+	case cancelToken != "":
+		cancelBody += " If this was not you, cancel it using this token: " + cancelToken
+	}
+
+	return utils.SendMail(previousEmail, "Email Change Requested", cancelBody)
+}
+
+// ConfirmEmailChange applies the pending email the token was sent to.
+func (s *userService) ConfirmEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) (dto.UserResponse, error) {
+	var user entities.User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		token, err := s.oneTimeTokenService.Consume(ctx, tx, req.Token, constants.TOKEN_PURPOSE_EMAIL_CHANGE)
+		if err != nil {
+			return dto.ErrTokenInvalid
+		}
+
+		user, err = s.userRepository.GetUserById(ctx, tx, token.UserID.String())
+		if err != nil {
+			return dto.ErrUserNotFound
+		}
+
+		if user.PendingEmail == nil || *user.PendingEmail != token.Payload {
+			return dto.ErrNoPendingEmailChange
+		}
+
+		if err := s.userRepository.UpdateEmail(ctx, tx, user.ID.String(), token.Payload); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return dto.ErrEmailAlreadyExists
+			}
+			return err
+		}
+
+		user.Email = token.Payload
+		user.PendingEmail = nil
+		user.IsVerified = true
+		return nil
+	})
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	return toUserResponse(user), nil
+}
+
+// CancelEmailChange drops a pending email change. The cancel link stays
+// valid after the change was confirmed: the previous address is restored and
+// every session is revoked, since the change was not made by the owner.
+func (s *userService) CancelEmailChange(ctx context.Context, req dto.EmailChangeTokenRequest) error {
+	var reverted bool
+	var userId string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		token, err := s.oneTimeTokenService.Consume(ctx, tx, req.Token, constants.TOKEN_PURPOSE_EMAIL_CHANGE_CANCEL)
+		if err != nil {
+			return dto.ErrTokenInvalid
+		}
+
+		user, err := s.userRepository.GetUserById(ctx, tx, token.UserID.String())
+		if err != nil {
+			return dto.ErrUserNotFound
+		}
+		userId = user.ID.String()
+
+		if err := s.oneTimeTokenService.Invalidate(ctx, tx, userId, constants.TOKEN_PURPOSE_EMAIL_CHANGE); err != nil {
+			return err
+		}
+
+		if err := s.oneTimeTokenService.Invalidate(ctx, tx, userId, constants.TOKEN_PURPOSE_EMAIL_CHANGE_CANCEL); err != nil {
+			return err
+		}
+
+		if user.Email == token.Payload {
+			return s.userRepository.ClearPendingEmail(ctx, tx, userId)
+		}
+
+		reverted = true
+		if err := s.userRepository.UpdateEmail(ctx, tx, userId, token.Payload); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return dto.ErrEmailAlreadyExists
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !reverted {
+		return nil
+	}
+
+	return s.sessionService.RevokeAllSessions(ctx, userId)
+}
+
+func (s *userService) Delete(ctx context.Context, actor authDto.Principal, userId string) error {
+	user, err := s.userRepository.GetUserById(ctx, s.db, userId)
+	if err != nil {
+		return dto.ErrUserNotFound
+	}
+
+	if err := s.checkActor(ctx, s.db, actor, user); err != nil {
+		return err
+	}
+
+	if err := s.userRepository.Delete(ctx, s.db, userId); err != nil {
+		return err
+	}
+
+	return s.sessionService.RevokeAllSessions(ctx, userId)
+}
+
+// checkActor lets users manage their own account. Anyone else must hold every
+// permission of the user, so an account cannot be taken over by someone with
+// fewer privileges.
+func (s *userService) checkActor(ctx context.Context, tx *gorm.DB, actor authDto.Principal, user entities.User) error {
+	if actor.UserID == user.ID.String() {
+		return nil
+	}
+	return s.rbacService.CheckUserGrant(ctx, tx, actor, user)
 }
 
-func (s *userService) Delete(ctx context.Context, userId string) error {
-	return s.userRepository.Delete(ctx, s.db, userId)
+// Unlock lifts a login lockout on behalf of the user.
+func (s *userService) Unlock(ctx context.Context, userId string, client authDto.ClientInfo) error {
+	user, err := s.userRepository.GetUserById(ctx, s.db, userId)
+	if err != nil {
+		return dto.ErrUserNotFound
+	}
+
+	return s.loginThrottleService.Unlock(ctx, s.db, user, client)
+}
+
+// ChangePassword replaces the password of a logged in user who proves they
+// know the current one. Outstanding password reset and magic links stop
+// working, every session except the one making the request is signed out
+// and the user is notified by email.
+func (s *userService) ChangePassword(ctx context.Context, userId string, sessionId string, req dto.ChangePasswordRequest) error {
+	var user entities.User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = s.userRepository.GetUserById(ctx, tx, userId)
+		if err != nil {
+			return dto.ErrUserNotFound
+		}
+
+		if valid, _ := helpers.CheckPassword(user.Password, []byte(req.CurrentPassword)); !valid {
+			return dto.ErrPasswordIncorrect
+		}
+
+		if err := s.passwordPolicyService.Validate(ctx, tx, req.NewPassword, user); err != nil {
+			return err
+		}
+
+		hashedPassword, err := helpers.HashPassword(req.NewPassword)
+		if err != nil {
+			return err
+		}
+
+		user.Password = hashedPassword
+		if _, err := s.userRepository.Update(ctx, tx, user); err != nil {
+			return err
+		}
+
+		if err := s.passwordPolicyService.Remember(ctx, tx, user.ID, hashedPassword); err != nil {
+			return err
+		}
+
+		return s.oneTimeTokenService.Invalidate(ctx, tx, userId, constants.TOKEN_PURPOSE_PASSWORD_RESET, constants.TOKEN_PURPOSE_MAGIC_LINK)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessionService.RevokeOtherSessions(ctx, userId, sessionId); err != nil {
+		return err
+	}
+
+	subject := "Password Changed"
+	body := "The password of your account was just changed and your other sessions were signed out. " +
+		"If you did not do this, reset your password immediately."
+
+	// The password has already changed, so a failed notification does not
+	// fail the request.
+	if err := utils.SendMail(user.Email, subject, body); err != nil {
+		log.Printf("user: notify password change for user %s: %v", userId, err)
+	}
+
+	return nil
 }
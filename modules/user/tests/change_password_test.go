@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/helpers"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const currentPassword = "current password"
+
+type stubUserRepository struct {
+	repository.UserRepository
+	user entities.User
+}
+
+func (r *stubUserRepository) GetUserById(context.Context, *gorm.DB, string) (entities.User, error) {
+	return r.user, nil
+}
+
+func (r *stubUserRepository) Update(_ context.Context, _ *gorm.DB, user entities.User) (entities.User, error) {
+	r.user = user
+	return user, nil
+}
+
+// lengthPasswordPolicy only requires twelve characters.
+type lengthPasswordPolicy struct {
+	authService.PasswordPolicyService
+}
+
+func (lengthPasswordPolicy) Validate(_ context.Context, _ *gorm.DB, password string, _ entities.User) error {
+	if len(password) < 12 {
+		return authDto.ErrPasswordTooShort
+	}
+	return nil
+}
+
+func (lengthPasswordPolicy) Remember(context.Context, *gorm.DB, uuid.UUID, string) error {
+	return nil
+}
+
+// stubRefreshTokenRepository holds one refresh token row per session.
+type stubRefreshTokenRepository struct {
+	authRepo.RefreshTokenRepository
+	familyIDs []string
+}
+
+func (r *stubRefreshTokenRepository) FindFamilyIDsByUserID(context.Context, *gorm.DB, string) ([]string, error) {
+	return r.familyIDs, nil
+}
+
+func (r *stubRefreshTokenRepository) DeleteByUserIDExceptFamilyID(_ context.Context, _ *gorm.DB, _ string, familyID string) error {
+	r.familyIDs = []string{familyID}
+	return nil
+}
+
+// stubOneTimeTokenService knows outstanding tokens by their purpose.
+type stubOneTimeTokenService struct {
+	authService.OneTimeTokenService
+	outstanding map[string]string
+}
+
+func (s *stubOneTimeTokenService) Consume(_ context.Context, _ *gorm.DB, token string, purpose string) (entities.OneTimeToken, error) {
+	if s.outstanding[token] != purpose {
+		return entities.OneTimeToken{}, gorm.ErrRecordNotFound
+	}
+	delete(s.outstanding, token)
+	return entities.OneTimeToken{Purpose: purpose}, nil
+}
+
+func (s *stubOneTimeTokenService) Invalidate(_ context.Context, _ *gorm.DB, _ string, purposes ...string) error {
+	for token, purpose := range s.outstanding {
+		for _, invalidated := range purposes {
+			if purpose == invalidated {
+				delete(s.outstanding, token)
+			}
+		}
+	}
+	return nil
+}
+
+type changePasswordFixture struct {
+	userService       service.UserService
+	users             *stubUserRepository
+	refreshTokens     *stubRefreshTokenRepository
+	oneTimeTokens     *stubOneTimeTokenService
+	revocationService authService.TokenRevocationService
+}
+
+func newChangePasswordFixture(t *testing.T) changePasswordFixture {
+	// Transactions need a connection, but the stubs never run a statement.
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+
+	hashedPassword, err := helpers.HashPassword(currentPassword)
+	require.NoError(t, err)
+
+	users := &stubUserRepository{user: entities.User{ID: uuid.New(), Email: "owner@example.com", Password: hashedPassword}}
+	refreshTokens := &stubRefreshTokenRepository{familyIDs: []string{"current-session", "other-session"}}
+	oneTimeTokens := &stubOneTimeTokenService{outstanding: map[string]string{
+		"reset-token":        constants.TOKEN_PURPOSE_PASSWORD_RESET,
+		"magic-token":        constants.TOKEN_PURPOSE_MAGIC_LINK,
+		"verification-token": constants.TOKEN_PURPOSE_EMAIL_VERIFICATION,
+	}}
+	revocationService := authService.NewTokenRevocationService(authRepo.NewMemoryRevocationStore())
+	sessionService := authService.NewSessionService(refreshTokens, revocationService, db)
+
+	return changePasswordFixture{
+		userService:       service.NewUserService(users, revocationService, nil, lengthPasswordPolicy{}, sessionService, oneTimeTokens, nil, db),
+		users:             users,
+		refreshTokens:     refreshTokens,
+		oneTimeTokens:     oneTimeTokens,
+		revocationService: revocationService,
+	}
+}
+
+func (f changePasswordFixture) sessionRevoked(t *testing.T, sessionID string) bool {
+	claims := &authService.JWTCustomClaim{
+		UserID:           f.users.user.ID.String(),
+		SessionID:        sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+	}
+	revoked, err := f.revocationService.IsRevoked(context.Background(), claims)
+	require.NoError(t, err)
+	return revoked
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	fixture := newChangePasswordFixture(t)
+	previousHash := fixture.users.user.Password
+
+	err := fixture.userService.ChangePassword(context.Background(), fixture.users.user.ID.String(), "current-session", dto.ChangePasswordRequest{
+		CurrentPassword: "wrong password",
+		NewPassword:     "a brand new password",
+	})
+	assert.ErrorIs(t, err, dto.ErrPasswordIncorrect)
+	assert.Equal(t, previousHash, fixture.users.user.Password)
+	assert.False(t, fixture.sessionRevoked(t, "other-session"))
+}
+
+func TestChangePassword_PolicyViolation(t *testing.T) {
+	fixture := newChangePasswordFixture(t)
+	previousHash := fixture.users.user.Password
+
+	err := fixture.userService.ChangePassword(context.Background(), fixture.users.user.ID.String(), "current-session", dto.ChangePasswordRequest{
+		CurrentPassword: currentPassword,
+		NewPassword:     "short",
+	})
+	assert.ErrorIs(t, err, authDto.ErrPasswordTooShort)
+	assert.Equal(t, previousHash, fixture.users.user.Password)
+	assert.Len(t, fixture.oneTimeTokens.outstanding, 3)
+}
+
+func TestChangePassword_RevokesOtherSessionsAndLinks(t *testing.T) {
+	ctx := context.Background()
+	fixture := newChangePasswordFixture(t)
+
+	err := fixture.userService.ChangePassword(ctx, fixture.users.user.ID.String(), "current-session", dto.ChangePasswordRequest{
+		CurrentPassword: currentPassword,
+		NewPassword:     "a brand new password",
+	})
+	require.NoError(t, err)
+
+	valid, err := helpers.CheckPassword(fixture.users.user.Password, []byte("a brand new password"))
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	assert.Equal(t, []string{"current-session"}, fixture.refreshTokens.familyIDs)
+	assert.True(t, fixture.sessionRevoked(t, "other-session"))
+	assert.False(t, fixture.sessionRevoked(t, "current-session"))
+
+	// Links emailed before the change no longer work.
+	_, err = fixture.oneTimeTokens.Consume(ctx, nil, "reset-token", constants.TOKEN_PURPOSE_PASSWORD_RESET)
+	assert.Error(t, err)
+	_, err = fixture.oneTimeTokens.Consume(ctx, nil, "magic-token", constants.TOKEN_PURPOSE_MAGIC_LINK)
+	assert.Error(t, err)
+	_, err = fixture.oneTimeTokens.Consume(ctx, nil, "verification-token", constants.TOKEN_PURPOSE_EMAIL_VERIFICATION)
+	assert.NoError(t, err)
+}
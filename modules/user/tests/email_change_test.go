@@ -0,0 +1,274 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Caknoooo/go-gin-clean-starter/database/entities"
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/dto"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/service"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	previousEmail = "owner@example.com"
+	newEmail      = "new@example.com"
+	takenEmail    = "taken@example.com"
+)
+
+// stubEmailUserRepository holds accounts by ID and enforces unique emails
+// the way the database index does.
+type stubEmailUserRepository struct {
+	repository.UserRepository
+	users map[string]*entities.User
+}
+
+func (r *stubEmailUserRepository) GetUserById(_ context.Context, _ *gorm.DB, userId string) (entities.User, error) {
+	user, ok := r.users[userId]
+	if !ok {
+		return entities.User{}, gorm.ErrRecordNotFound
+	}
+	return *user, nil
+}
+
+func (r *stubEmailUserRepository) CheckEmail(_ context.Context, _ *gorm.DB, email string) (entities.User, bool, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return *user, true, nil
+		}
+	}
+	return entities.User{}, false, gorm.ErrRecordNotFound
+}
+
+func (r *stubEmailUserRepository) Update(_ context.Context, _ *gorm.DB, user entities.User) (entities.User, error) {
+	*r.users[user.ID.String()] = user
+	return user, nil
+}
+
+func (r *stubEmailUserRepository) UpdateEmail(_ context.Context, _ *gorm.DB, userId string, email string) error {
+	for id, user := range r.users {
+		if id != userId && user.Email == email {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	user := r.users[userId]
+	user.Email = email
+	user.PendingEmail = nil
+	user.IsVerified = true
+	return nil
+}
+
+func (r *stubEmailUserRepository) ClearPendingEmail(_ context.Context, _ *gorm.DB, userId string) error {
+	r.users[userId].PendingEmail = nil
+	return nil
+}
+
+// memoryOneTimeTokenRepository keeps one-time tokens in memory so the real
+// OneTimeTokenService can run without a database.
+type memoryOneTimeTokenRepository struct {
+	authRepo.OneTimeTokenRepository
+	tokens map[string]entities.OneTimeToken
+}
+
+func (r *memoryOneTimeTokenRepository) Create(_ context.Context, _ *gorm.DB, token entities.OneTimeToken) (entities.OneTimeToken, error) {
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID.String()] = token
+	return token, nil
+}
+
+func (r *memoryOneTimeTokenRepository) FindByTokenHash(_ context.Context, _ *gorm.DB, tokenHash string, purpose string) (entities.OneTimeToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash && token.Purpose == purpose {
+			return token, nil
+		}
+	}
+	return entities.OneTimeToken{}, gorm.ErrRecordNotFound
+}
+
+func (r *memoryOneTimeTokenRepository) FindLatestByUserIDAndPurpose(_ context.Context, _ *gorm.DB, userID string, purpose string) (entities.OneTimeToken, error) {
+	for _, token := range r.tokens {
+		if token.UserID.String() == userID && token.Purpose == purpose {
+			return token, nil
+		}
+	}
+	return entities.OneTimeToken{}, gorm.ErrRecordNotFound
+}
+
+func (r *memoryOneTimeTokenRepository) MarkConsumed(_ context.Context, _ *gorm.DB, id string) (bool, error) {
+	token, ok := r.tokens[id]
+	if !ok || token.ConsumedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.ConsumedAt = &now
+	r.tokens[id] = token
+	return true, nil
+}
+
+func (r *memoryOneTimeTokenRepository) DeleteByUserIDAndPurpose(_ context.Context, _ *gorm.DB, userID string, purposes ...string) error {
+	for id, token := range r.tokens {
+		for _, purpose := range purposes {
+			if token.UserID.String() == userID && token.Purpose == purpose {
+				delete(r.tokens, id)
+			}
+		}
+	}
+	return nil
+}
+
+// deleteAllRefreshTokenRepository forgets every session of the user.
+type deleteAllRefreshTokenRepository struct {
+	authRepo.RefreshTokenRepository
+	deleted []string
+}
+
+func (r *deleteAllRefreshTokenRepository) DeleteByUserID(_ context.Context, _ *gorm.DB, userId string) error {
+	r.deleted = append(r.deleted, userId)
+	return nil
+}
+
+type emailChangeFixture struct {
+	userService       service.UserService
+	users             *stubEmailUserRepository
+	owner             *entities.User
+	oneTimeTokens     authService.OneTimeTokenService
+	tokens            *memoryOneTimeTokenRepository
+	refreshTokens     *deleteAllRefreshTokenRepository
+	revocationService authService.TokenRevocationService
+}
+
+func newEmailChangeFixture(t *testing.T) emailChangeFixture {
+	// Transactions need a connection, but the stubs never run a statement.
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+
+	owner := &entities.User{ID: uuid.New(), Email: previousEmail, IsVerified: true}
+	other := &entities.User{ID: uuid.New(), Email: takenEmail, IsVerified: true}
+	users := &stubEmailUserRepository{users: map[string]*entities.User{
+		owner.ID.String(): owner,
+		other.ID.String(): other,
+	}}
+	tokens := &memoryOneTimeTokenRepository{tokens: make(map[string]entities.OneTimeToken)}
+	oneTimeTokens := authService.NewOneTimeTokenService(tokens)
+	refreshTokens := &deleteAllRefreshTokenRepository{}
+	revocationService := authService.NewTokenRevocationService(authRepo.NewMemoryRevocationStore())
+	sessionService := authService.NewSessionService(refreshTokens, revocationService, db)
+
+	return emailChangeFixture{
+		userService:       service.NewUserService(users, revocationService, nil, nil, sessionService, oneTimeTokens, nil, db),
+		users:             users,
+		owner:             owner,
+		oneTimeTokens:     oneTimeTokens,
+		tokens:            tokens,
+		refreshTokens:     refreshTokens,
+		revocationService: revocationService,
+	}
+}
+
+// requestChange sets up what Update leaves behind for a change to email:
+// the pending address and the links mailed to the new and previous address.
+func (f emailChangeFixture) requestChange(t *testing.T, email string) (confirmToken string, cancelToken string) {
+	ctx := context.Background()
+
+	confirmToken, err := f.oneTimeTokens.IssueWithPayload(ctx, nil, f.owner.ID, constants.TOKEN_PURPOSE_EMAIL_CHANGE, email)
+	require.NoError(t, err)
+	cancelToken, err = f.oneTimeTokens.IssueWithPayload(ctx, nil, f.owner.ID, constants.TOKEN_PURPOSE_EMAIL_CHANGE_CANCEL, f.owner.Email)
+	require.NoError(t, err)
+
+	f.owner.PendingEmail = &email
+	return confirmToken, cancelToken
+}
+
+func (f emailChangeFixture) sessionsRevoked(t *testing.T) bool {
+	claims := &authService.JWTCustomClaim{
+		UserID:           f.owner.ID.String(),
+		SessionID:        "session",
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+	}
+	revoked, err := f.revocationService.IsRevoked(context.Background(), claims)
+	require.NoError(t, err)
+	return revoked
+}
+
+func TestUpdate_RejectsEmailOfAnotherAccount(t *testing.T) {
+	fixture := newEmailChangeFixture(t)
+	actor := authDto.Principal{UserID: fixture.owner.ID.String()}
+
+	_, err := fixture.userService.Update(context.Background(), actor, dto.UserUpdateRequest{Email: takenEmail}, fixture.owner.ID.String())
+	assert.ErrorIs(t, err, dto.ErrEmailAlreadyExists)
+	assert.Nil(t, fixture.owner.PendingEmail)
+	assert.Empty(t, fixture.tokens.tokens)
+}
+
+func TestConfirmEmailChange_AppliesPendingEmail(t *testing.T) {
+	fixture := newEmailChangeFixture(t)
+	confirmToken, _ := fixture.requestChange(t, newEmail)
+
+	result, err := fixture.userService.ConfirmEmailChange(context.Background(), dto.EmailChangeTokenRequest{Token: confirmToken})
+	require.NoError(t, err)
+	assert.Equal(t, newEmail, result.Email)
+	assert.Equal(t, newEmail, fixture.owner.Email)
+	assert.Nil(t, fixture.owner.PendingEmail)
+}
+
+func TestConfirmEmailChange_RejectsReusedAndExpiredTokens(t *testing.T) {
+	ctx := context.Background()
+	fixture := newEmailChangeFixture(t)
+	confirmToken, _ := fixture.requestChange(t, newEmail)
+
+	_, err := fixture.userService.ConfirmEmailChange(ctx, dto.EmailChangeTokenRequest{Token: confirmToken})
+	require.NoError(t, err)
+
+	_, err = fixture.userService.ConfirmEmailChange(ctx, dto.EmailChangeTokenRequest{Token: confirmToken})
+	assert.ErrorIs(t, err, dto.ErrTokenInvalid)
+
+	confirmToken, _ = fixture.requestChange(t, "later@example.com")
+	for id, token := range fixture.tokens.tokens {
+		token.ExpiresAt = time.Now().Add(-time.Second)
+		fixture.tokens.tokens[id] = token
+	}
+
+	_, err = fixture.userService.ConfirmEmailChange(ctx, dto.EmailChangeTokenRequest{Token: confirmToken})
+	assert.ErrorIs(t, err, dto.ErrTokenInvalid)
+	assert.Equal(t, newEmail, fixture.owner.Email)
+}
+
+func TestConfirmEmailChange_RejectsEmailTakenMeanwhile(t *testing.T) {
+	fixture := newEmailChangeFixture(t)
+
+	// Another account took the address after the change was requested.
+	confirmToken, _ := fixture.requestChange(t, takenEmail)
+
+	_, err := fixture.userService.ConfirmEmailChange(context.Background(), dto.EmailChangeTokenRequest{Token: confirmToken})
+	assert.ErrorIs(t, err, dto.ErrEmailAlreadyExists)
+	assert.Equal(t, previousEmail, fixture.owner.Email)
+}
+
+func TestCancelEmailChange_RevertsConfirmedChange(t *testing.T) {
+	ctx := context.Background()
+	fixture := newEmailChangeFixture(t)
+	confirmToken, cancelToken := fixture.requestChange(t, newEmail)
+
+	_, err := fixture.userService.ConfirmEmailChange(ctx, dto.EmailChangeTokenRequest{Token: confirmToken})
+	require.NoError(t, err)
+	assert.False(t, fixture.sessionsRevoked(t))
+
+	require.NoError(t, fixture.userService.CancelEmailChange(ctx, dto.EmailChangeTokenRequest{Token: cancelToken}))
+	assert.Equal(t, previousEmail, fixture.owner.Email)
+	assert.Equal(t, []string{fixture.owner.ID.String()}, fixture.refreshTokens.deleted)
+	assert.True(t, fixture.sessionsRevoked(t))
+
+	assert.ErrorIs(t, fixture.userService.CancelEmailChange(ctx, dto.EmailChangeTokenRequest{Token: cancelToken}), dto.ErrTokenInvalid)
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authDto "github.com/Caknoooo/go-gin-clean-starter/modules/auth/dto"
+	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/controller"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/do"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAuthenticator signs every caller in as user-id, through a personal
+// access token when one is sent.
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) AuthenticateAccessToken(context.Context, string) (authDto.Principal, error) {
+	return authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER), nil
+}
+
+func (stubAuthenticator) AuthenticateAPIKey(context.Context, string, string) (authDto.Principal, error) {
+	principal := authDto.NewPrincipal("user-id", constants.ENUM_ROLE_USER)
+	principal.APIKeyID = "key-id"
+	return principal, nil
+}
+
+// stubUserController answers every handled request with 200.
+type stubUserController struct {
+	controller.UserController
+}
+
+func (stubUserController) Me(ctx *gin.Context)     { ctx.Status(http.StatusOK) }
+func (stubUserController) Update(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+
+func newUserRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	injector := do.New()
+	do.ProvideNamedValue[authService.Authenticator](injector, constants.Authenticator, stubAuthenticator{})
+	do.ProvideValue[controller.UserController](injector, stubUserController{})
+
+	router := gin.New()
+	user.RegisterRoutes(router, injector)
+	return router
+}
+
+func requestWithAPIKey(router *gin.Engine, method string, path string) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-API-Key", "key")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestUserRoutes_APIKeyCannotChangeProfile(t *testing.T) {
+	router := newUserRouter()
+
+	assert.Equal(t, http.StatusOK, requestWithAPIKey(router, http.MethodGet, "/api/user/me"))
+	assert.Equal(t, http.StatusForbidden, requestWithAPIKey(router, http.MethodPut, "/api/user/me"))
+	assert.Equal(t, http.StatusForbidden, requestWithAPIKey(router, http.MethodPut, "/api/user/user-id"))
+}
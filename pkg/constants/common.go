@@ -10,6 +10,54 @@ const (
 	ENUM_PAGINATION_PER_PAGE = 10
 	ENUM_PAGINATION_PAGE     = 1
 
-	DB         = "db"
-	JWTService = "JWTService"
+	DB              = "db"
+	JWTService      = "JWTService"
+	RevocationStore = "RevocationStore"
+	Authenticator   = "Authenticator"
+	MFAService      = "MFAService"
+	Scheduler       = "Scheduler"
+)
+
+const (
+	TOKEN_TRANSPORT_BEARER = "bearer"
+	TOKEN_TRANSPORT_COOKIE = "cookie"
+	TOKEN_TRANSPORT_BOTH   = "both"
+
+	ACCESS_TOKEN_COOKIE  = "access_token"
+	REFRESH_TOKEN_COOKIE = "refresh_token"
+	CSRF_TOKEN_COOKIE    = "csrf_token"
+	OAUTH_STATE_COOKIE   = "oauth_state"
+	CSRF_TOKEN_HEADER    = "X-CSRF-Token"
+)
+
+const (
+	ORGANIZATION_ROLE_OWNER  = "owner"
+	ORGANIZATION_ROLE_ADMIN  = "admin"
+	ORGANIZATION_ROLE_MEMBER = "member"
+)
+
+const (
+	REGISTRATION_MODE_OPEN        = "open"
+	REGISTRATION_MODE_INVITE_ONLY = "invite_only"
+	REGISTRATION_MODE_DISABLED    = "disabled"
+)
+
+const (
+	TOKEN_PURPOSE_EMAIL_VERIFICATION  = "email_verification"
+	TOKEN_PURPOSE_PASSWORD_RESET      = "password_reset"
+	TOKEN_PURPOSE_MFA_CHALLENGE       = "mfa_challenge"
+	TOKEN_PURPOSE_ACCOUNT_UNLOCK      = "account_unlock"
+	TOKEN_PURPOSE_MAGIC_LINK          = "magic_link"
+	TOKEN_PURPOSE_EMAIL_CHANGE        = "email_change"
+	TOKEN_PURPOSE_EMAIL_CHANGE_CANCEL = "email_change_cancel"
+)
+
+const (
+	SECURITY_EVENT_REFRESH_TOKEN_REUSE   = "refresh_token_reuse"
+	SECURITY_EVENT_MFA_ENABLED           = "mfa_enabled"
+	SECURITY_EVENT_MFA_DISABLED          = "mfa_disabled"
+	SECURITY_EVENT_MFA_RECOVERY_USED     = "mfa_recovery_code_used"
+	SECURITY_EVENT_ACCOUNT_LOCKED        = "account_locked"
+	SECURITY_EVENT_ACCOUNT_UNLOCKED      = "account_unlocked"
+	SECURITY_EVENT_IMPERSONATION_STARTED = "impersonation_started"
 )
@@ -0,0 +1,34 @@
+package constants
+
+const (
+	// PERMISSION_ALL grants every permission. It is only meant for the admin role.
+	PERMISSION_ALL = "*"
+
+	PERMISSION_USER_READ  = "users:read"
+	PERMISSION_USER_WRITE = "users:write"
+
+	PERMISSION_INVITATION_READ  = "invitations:read"
+	PERMISSION_INVITATION_WRITE = "invitations:write"
+
+	PERMISSION_ROLE_READ  = "roles:read"
+	PERMISSION_ROLE_WRITE = "roles:write"
+)
+
+// Permissions lists every permission string. Modules declare their own
+// permission strings and add them here; only listed permissions can be granted
+// to roles or used as scopes of personal access tokens.
+var Permissions = []string{
+	PERMISSION_USER_READ,
+	PERMISSION_USER_WRITE,
+	PERMISSION_INVITATION_READ,
+	PERMISSION_INVITATION_WRITE,
+	PERMISSION_ROLE_READ,
+	PERMISSION_ROLE_WRITE,
+}
+
+// RolePermissions maps each built-in role to the permissions it is seeded
+// with. Further roles are created at runtime and stored in the database.
+var RolePermissions = map[string][]string{
+	ENUM_ROLE_ADMIN: {PERMISSION_ALL},
+	ENUM_ROLE_USER:  {},
+}
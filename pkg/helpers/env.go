@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetDurationEnv parses the environment variable as a duration such as "15m",
+// returning defaultValue when it is unset, invalid or not positive.
+func GetDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// GetIntEnv parses the environment variable as an integer, returning
+// defaultValue when it is unset, invalid or not positive.
+func GetIntEnv(key string, defaultValue int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// GetNonNegativeDurationEnv is GetDurationEnv for settings where zero turns
+// the feature off: only unset, invalid or negative values use defaultValue.
+func GetNonNegativeDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// GetNonNegativeIntEnv is GetIntEnv for settings where zero turns the feature
+// off: only unset, invalid or negative values use defaultValue.
+func GetNonNegativeIntEnv(key string, defaultValue int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -1,18 +1,207 @@
 package helpers
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+const (
+	PASSWORD_HASH_ARGON2ID = "argon2id"
+	PASSWORD_HASH_BCRYPT   = "bcrypt"
+)
+
+var (
+	ErrUnknownPasswordHash = errors.New("unknown password hash format")
+	ErrMalformedHash       = errors.New("malformed password hash")
+)
+
+// PasswordHasher hashes passwords into self-describing strings that carry the
+// algorithm and its parameters, so stored hashes stay verifiable after the
+// configuration changes.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash string, password []byte) (bool, error)
+	// NeedsRehash reports whether hash was produced by another algorithm or
+	// with other parameters than the hasher currently uses.
+	NeedsRehash(hash string) bool
+}
+
+// Argon2idHasher produces PHC strings such as
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>. Memory is in KiB.
+type Argon2idHasher struct {
+	Memory      uint32
+	Time        uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// NewArgon2idHasher reads its parameters from PASSWORD_ARGON2_MEMORY (KiB),
+// PASSWORD_ARGON2_TIME and PASSWORD_ARGON2_PARALLELISM. The defaults of
+// m=64MiB, t=3, p=2 are above the OWASP minimums (m=19MiB, t=2, p=1 or
+// m=46MiB, t=1, p=1); lower them only on hosts that cannot afford them.
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{
+		Memory:      uint32(GetIntEnv("PASSWORD_ARGON2_MEMORY", 64*1024)),
+		Time:        uint32(GetIntEnv("PASSWORD_ARGON2_TIME", 3)),
+		Parallelism: uint8(min(GetIntEnv("PASSWORD_ARGON2_PARALLELISM", 2), 255)),
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Parallelism, h.KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Time, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Verify(hash string, password []byte) (bool, error) {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey(password, salt, params.Time, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != h.Memory ||
+		params.Time != h.Time ||
+		params.Parallelism != h.Parallelism ||
+		uint32(len(salt)) != h.SaltLength ||
+		uint32(len(key)) != h.KeyLength
+}
+
+func decodeArgon2id(hash string) (Argon2idHasher, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PASSWORD_HASH_ARGON2ID {
+		return Argon2idHasher{}, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Argon2idHasher{}, nil, nil, ErrMalformedHash
+	}
+	if version != argon2.Version {
+		return Argon2idHasher{}, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+
+	var params Argon2idHasher
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Parallelism); err != nil {
+		return Argon2idHasher{}, nil, nil, ErrMalformedHash
+	}
+	if params.Time == 0 || params.Parallelism == 0 {
+		return Argon2idHasher{}, nil, nil, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2idHasher{}, nil, nil, ErrMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2idHasher{}, nil, nil, ErrMalformedHash
+	}
+
+	return params, salt, key, nil
+}
+
+// BcryptHasher is kept for existing hashes and deployments that require it.
+// bcrypt only uses the first 72 bytes of a password, so longer passwords are
+// rejected rather than silently truncated.
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher reads the cost from PASSWORD_BCRYPT_COST (default 12).
+func NewBcryptHasher() *BcryptHasher {
+	return &BcryptHasher{Cost: int(GetIntEnv("PASSWORD_BCRYPT_COST", 12))}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
 	return string(bytes), err
 }
 
-func CheckPassword(hashPassword string, plainPassword []byte) (bool, error) {
-	hashPW := []byte(hashPassword)
-	if err := bcrypt.CompareHashAndPassword(hashPW, plainPassword); err != nil {
+func (h *BcryptHasher) Verify(hash string, password []byte) (bool, error) {
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), password); err != nil {
 		return false, err
 	}
 	return true, nil
 }
+
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
+
+// NewPasswordHasher returns the hasher selected by PASSWORD_HASH_ALGORITHM,
+// argon2id by default.
+func NewPasswordHasher() PasswordHasher {
+	if strings.ToLower(os.Getenv("PASSWORD_HASH_ALGORITHM")) == PASSWORD_HASH_BCRYPT {
+		return NewBcryptHasher()
+	}
+	return NewArgon2idHasher()
+}
+
+var defaultPasswordHasher = sync.OnceValue(NewPasswordHasher)
+
+// HashPassword hashes with the configured hasher.
+func HashPassword(password string) (string, error) {
+	return defaultPasswordHasher().Hash(password)
+}
+
+// CheckPassword verifies a password against a hash of any supported
+// algorithm, whichever one is currently configured.
+func CheckPassword(hashPassword string, plainPassword []byte) (bool, error) {
+	hasher, err := hasherFor(hashPassword)
+	if err != nil {
+		return false, err
+	}
+	return hasher.Verify(hashPassword, plainPassword)
+}
+
+// PasswordNeedsRehash reports whether a stored hash should be replaced by a
+// fresh one from the configured hasher, typically right after a successful
+// login while the plaintext is at hand.
+func PasswordNeedsRehash(hashPassword string) bool {
+	return defaultPasswordHasher().NeedsRehash(hashPassword)
+}
+
+func hasherFor(hash string) (PasswordHasher, error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return &Argon2idHasher{}, nil
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return &BcryptHasher{}, nil
+	default:
+		return nil, ErrUnknownPasswordHash
+	}
+}
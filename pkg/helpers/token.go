@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateRandomToken returns a URL-safe random string built from size bytes of entropy.
+func GenerateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex encoded SHA-256 digest of a token, used to store
+// high-entropy secrets without keeping the raw value.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// HMACToken returns the hex encoded HMAC-SHA256 of a token under key. Unlike
+// HashToken, a leaked table cannot be checked against guesses without the key.
+func HMACToken(token string, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
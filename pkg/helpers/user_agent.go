@@ -0,0 +1,47 @@
+package helpers
+
+import "strings"
+
+// DescribeUserAgent turns a User-Agent header into a short label such as
+// "Chrome on Windows". It only recognises common browsers and platforms.
+func DescribeUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	for _, candidate := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"PostmanRuntime/", "Postman"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	platform := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			platform = candidate.name
+			break
+		}
+	}
+
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Locker guarantees a job runs on a single runner at a time.
+type Locker interface {
+	// TryLock acquires the lock for the job without waiting. acquired is false
+	// when someone else holds it; otherwise unlock must be called once done.
+	TryLock(ctx context.Context, name string) (unlock func(), acquired bool, err error)
+}
+
+// NewLocker picks the Postgres advisory lock when the database is Postgres
+// and falls back to a process local lock otherwise, e.g. for SQLite.
+func NewLocker(db *gorm.DB) Locker {
+	if db != nil && db.Dialector.Name() == "postgres" {
+		return NewAdvisoryLocker(db)
+	}
+	return NewLocalLocker()
+}
+
+type advisoryLocker struct {
+	db *gorm.DB
+}
+
+// NewAdvisoryLocker holds a Postgres session level advisory lock per job on a
+// dedicated connection, so only one replica runs a job at a time. The lock is
+// released automatically if the connection dies.
+func NewAdvisoryLocker(db *gorm.DB) Locker {
+	return &advisoryLocker{db: db}
+}
+
+func (l *advisoryLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := advisoryLockKey(name)
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// The job context may already be cancelled, so unlock without it.
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.Printf("scheduler: release lock for %s: %v", name, err)
+		}
+		conn.Close()
+	}
+
+	return unlock, true, nil
+}
+
+func advisoryLockKey(name string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("scheduler:" + name))
+	return int64(hash.Sum64())
+}
+
+type localLocker struct {
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+// NewLocalLocker only prevents overlapping runs within this process.
+func NewLocalLocker() Locker {
+	return &localLocker{running: make(map[string]bool)}
+}
+
+func (l *localLocker) TryLock(_ context.Context, name string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running[name] {
+		return nil, false, nil
+	}
+	l.running[name] = true
+
+	unlock := func() {
+		l.mu.Lock()
+		delete(l.running, name)
+		l.mu.Unlock()
+	}
+
+	return unlock, true, nil
+}
@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// shutdownTimeout is how long Shutdown waits for running jobs to return after
+// their context has been cancelled.
+const shutdownTimeout = time.Second * 30
+
+var (
+	ErrJobNotFound      = errors.New("job not found")
+	ErrJobAlreadyExists = errors.New("job already registered")
+	ErrAlreadyStarted   = errors.New("scheduler already started")
+)
+
+// Job is a unit of recurring work. Run receives a context that is cancelled
+// when the scheduler stops.
+type Job struct {
+	Name     string
+	Schedule string
+	Run      func(ctx context.Context) error
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+}
+
+// Scheduler runs registered jobs on their schedules. A job never overlaps with
+// itself: ticks that pass while it is still running are skipped, and the
+// Locker keeps other replicas from running it at the same time.
+type Scheduler struct {
+	locker  Locker
+	mu      sync.Mutex
+	entries map[string]entry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func New(locker Locker) *Scheduler {
+	return &Scheduler{
+		locker:  locker,
+		entries: make(map[string]entry),
+	}
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return ErrAlreadyStarted
+	}
+	if _, exists := s.entries[job.Name]; exists {
+		return fmt.Errorf("job %s: %w", job.Name, ErrJobAlreadyExists)
+	}
+
+	s.entries[job.Name] = entry{job: job, schedule: schedule}
+	return nil
+}
+
+// Start runs every registered job on its schedule until Stop is called.
+func (s *Scheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return ErrAlreadyStarted
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.loop(ctx, e)
+	}
+
+	return nil
+}
+
+// Stop cancels running jobs and waits for them to return or for ctx to end.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops the scheduler. It is called by the injector.
+func (s *Scheduler) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return s.Stop(ctx)
+}
+
+// RunNow runs a job immediately under the same lock as scheduled runs. It
+// reports false when the job is already running elsewhere.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (bool, error) {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	s.mu.Unlock()
+
+	if !ok {
+		return false, ErrJobNotFound
+	}
+
+	return s.run(ctx, e.job)
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	defer s.wg.Done()
+
+	for {
+		next := e.schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("scheduler: job %s has no upcoming run", e.job.Name)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if _, err := s.run(ctx, e.job); err != nil {
+			log.Printf("scheduler: job %s: %v", e.job.Name, err)
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) (bool, error) {
+	unlock, acquired, err := s.locker.TryLock(ctx, job.Name)
+	if err != nil {
+		return false, err
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer unlock()
+
+	started := time.Now()
+	if err := job.Run(ctx); err != nil {
+		return true, err
+	}
+
+	log.Printf("scheduler: job %s finished in %s", job.Name, time.Since(started).Round(time.Millisecond))
+	return true, nil
+}
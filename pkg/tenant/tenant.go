@@ -0,0 +1,51 @@
+// Package tenant carries the active organization of a request and scopes
+// queries of tenant-owned tables to it.
+//
+// Scoping is opt-in: no callback adds the filter to every query, because
+// tables such as refresh_tokens also carry an organization_id but are read
+// across organizations. Every repository query on a tenant-owned table must
+// go through Scope, or it reads the rows of all organizations.
+package tenant
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNoOrganization = errors.New("no active organization")
+
+type contextKey struct{}
+
+// WithOrganizationID returns a context carrying the active organization.
+func WithOrganizationID(ctx context.Context, organizationID uuid.UUID) context.Context {
+	return context.WithValue(ctx, contextKey{}, organizationID)
+}
+
+// OrganizationID returns the active organization stored in the context.
+func OrganizationID(ctx context.Context) (uuid.UUID, bool) {
+	organizationID, ok := ctx.Value(contextKey{}).(uuid.UUID)
+	return organizationID, ok && organizationID != uuid.Nil
+}
+
+// Scope filters a query on the organization_id column of its table by the
+// organization in ctx, as in tx.Scopes(tenant.Scope(ctx)). Without an
+// organization the statement fails with ErrNoOrganization rather than
+// reading across tenants.
+func Scope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		organizationID, ok := OrganizationID(ctx)
+		if !ok {
+			db.AddError(ErrNoOrganization)
+			return db
+		}
+
+		return db.Where(clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: "organization_id"},
+			Value:  organizationID,
+		})
+	}
+}
@@ -2,13 +2,25 @@ package providers
 
 import (
 	"github.com/Caknoooo/go-gin-clean-starter/config"
+	adminController "github.com/Caknoooo/go-gin-clean-starter/modules/admin/controller"
+	adminService "github.com/Caknoooo/go-gin-clean-starter/modules/admin/service"
 	authController "github.com/Caknoooo/go-gin-clean-starter/modules/auth/controller"
 	authRepo "github.com/Caknoooo/go-gin-clean-starter/modules/auth/repository"
 	authService "github.com/Caknoooo/go-gin-clean-starter/modules/auth/service"
+	invitationController "github.com/Caknoooo/go-gin-clean-starter/modules/invitation/controller"
+	invitationRepo "github.com/Caknoooo/go-gin-clean-starter/modules/invitation/repository"
+	invitationService "github.com/Caknoooo/go-gin-clean-starter/modules/invitation/service"
+	organizationController "github.com/Caknoooo/go-gin-clean-starter/modules/organization/controller"
+	organizationRepo "github.com/Caknoooo/go-gin-clean-starter/modules/organization/repository"
+	orgService "github.com/Caknoooo/go-gin-clean-starter/modules/organization/service"
+	rbacController "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/controller"
+	rbacRepo "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/repository"
+	rbacService "github.com/Caknoooo/go-gin-clean-starter/modules/rbac/service"
 	userController "github.com/Caknoooo/go-gin-clean-starter/modules/user/controller"
 	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
 	userService "github.com/Caknoooo/go-gin-clean-starter/modules/user/service"
 	"github.com/Caknoooo/go-gin-clean-starter/pkg/constants"
+	"github.com/Caknoooo/go-gin-clean-starter/pkg/scheduler"
 	"github.com/samber/do"
 	"gorm.io/gorm"
 )
@@ -23,17 +35,65 @@ func RegisterDependencies(injector *do.Injector) {
 	InitDatabase(injector)
 
 	do.ProvideNamed(injector, constants.JWTService, func(i *do.Injector) (authService.JWTService, error) {
-		return authService.NewJWTService(), nil
+		return authService.NewJWTService()
+	})
+
+	do.ProvideNamed(injector, constants.RevocationStore, func(i *do.Injector) (authRepo.RevocationStore, error) {
+		return authRepo.NewRevocationStore(do.MustInvokeNamed[*gorm.DB](i, constants.DB))
+	})
+
+	do.ProvideNamed(injector, constants.Scheduler, func(i *do.Injector) (*scheduler.Scheduler, error) {
+		return scheduler.New(scheduler.NewLocker(do.MustInvokeNamed[*gorm.DB](i, constants.DB))), nil
+	})
+
+	do.ProvideNamed(injector, constants.MFAService, func(i *do.Injector) (authService.MFAService, error) {
+		db := do.MustInvokeNamed[*gorm.DB](i, constants.DB)
+		return authService.NewMFAService(
+			authRepo.NewMFARepository(db),
+			repository.NewUserRepository(db),
+			authRepo.NewSecurityEventRepository(db),
+			db,
+		)
 	})
 
 	db := do.MustInvokeNamed[*gorm.DB](injector, constants.DB)
 	jwtService := do.MustInvokeNamed[authService.JWTService](injector, constants.JWTService)
+	revocationStore := do.MustInvokeNamed[authRepo.RevocationStore](injector, constants.RevocationStore)
+	mfaService := do.MustInvokeNamed[authService.MFAService](injector, constants.MFAService)
 
 	userRepository := repository.NewUserRepository(db)
 	refreshTokenRepository := authRepo.NewRefreshTokenRepository(db)
+	oneTimeTokenRepository := authRepo.NewOneTimeTokenRepository(db)
+	securityEventRepository := authRepo.NewSecurityEventRepository(db)
+	loginAttemptRepository := authRepo.NewLoginAttemptRepository(db)
+	oauthRepository := authRepo.NewOAuthRepository(db)
+	personalAccessTokenRepository := authRepo.NewPersonalAccessTokenRepository(db)
+	passwordHistoryRepository := authRepo.NewPasswordHistoryRepository(db)
+	invitationRepository := invitationRepo.NewInvitationRepository(db)
+	roleRepository := rbacRepo.NewRoleRepository(db)
+	permissionRepository := rbacRepo.NewPermissionRepository(db)
+	organizationRepository := organizationRepo.NewOrganizationRepository(db)
+	organizationInvitationRepository := organizationRepo.NewOrganizationInvitationRepository(db)
+
+	rbacService := rbacService.NewRBACService(roleRepository, permissionRepository, userRepository, db)
+	oneTimeTokenService := authService.NewOneTimeTokenService(oneTimeTokenRepository)
+	tokenRevocationService := authService.NewTokenRevocationService(revocationStore)
+	loginThrottleService := authService.NewLoginThrottleService(loginAttemptRepository, oneTimeTokenService, securityEventRepository, db)
+	passwordPolicyService := authService.NewPasswordPolicyService(passwordHistoryRepository, authService.NewBreachedPasswordChecker(), authService.NewPasswordPolicy(), db)
+	accountStatusService := authService.NewAccountStatusService(userRepository, db)
+	authenticator := authService.NewAuthenticator(jwtService, tokenRevocationService, personalAccessTokenRepository, rbacService, accountStatusService)
+	sessionService := authService.NewSessionService(refreshTokenRepository, tokenRevocationService, db)
+	invitationService := invitationService.NewInvitationService(invitationRepository, userRepository, rbacService, db)
+	userService := userService.NewUserService(userRepository, tokenRevocationService, loginThrottleService, passwordPolicyService, sessionService, oneTimeTokenService, rbacService, db)
+	authenticationService := authService.NewAuthService(userRepository, refreshTokenRepository, oneTimeTokenService, mfaService, loginThrottleService, passwordPolicyService, securityEventRepository, tokenRevocationService, jwtService, invitationService, rbacService, organizationRepository, db)
+
+	oauthService := authService.NewOAuthService(authService.NewOAuthProviders(), oauthRepository, userRepository, authenticationService, invitationService, db)
+	personalAccessTokenService := authService.NewPersonalAccessTokenService(personalAccessTokenRepository, db)
+	organizationService := orgService.NewOrganizationService(organizationRepository, db)
+	organizationInvitationService := orgService.NewOrganizationInvitationService(organizationService, organizationRepository, organizationInvitationRepository, userRepository, db)
+	adminUserService := adminService.NewAdminService(userRepository, securityEventRepository, passwordPolicyService, oneTimeTokenService, sessionService, accountStatusService, jwtService, rbacService, db)
 
-	userService := userService.NewUserService(userRepository, db)
-	authService := authService.NewAuthService(userRepository, refreshTokenRepository, jwtService, db)
+	do.ProvideNamedValue(injector, constants.Authenticator, authenticator)
 
 	do.Provide(
 		injector, func(i *do.Injector) (userController.UserController, error) {
@@ -43,7 +103,55 @@ func RegisterDependencies(injector *do.Injector) {
 
 	do.Provide(
 		injector, func(i *do.Injector) (authController.AuthController, error) {
-			return authController.NewAuthController(i, authService), nil
+			return authController.NewAuthController(i, authenticationService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (authController.SessionController, error) {
+			return authController.NewSessionController(sessionService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (authController.MFAController, error) {
+			return authController.NewMFAController(mfaService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (authController.OAuthController, error) {
+			return authController.NewOAuthController(oauthService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (authController.PersonalAccessTokenController, error) {
+			return authController.NewPersonalAccessTokenController(personalAccessTokenService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (adminController.AdminController, error) {
+			return adminController.NewAdminController(i, adminUserService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (invitationController.InvitationController, error) {
+			return invitationController.NewInvitationController(i, invitationService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (rbacController.RBACController, error) {
+			return rbacController.NewRBACController(rbacService), nil
+		},
+	)
+
+	do.Provide(
+		injector, func(i *do.Injector) (organizationController.OrganizationController, error) {
+			return organizationController.NewOrganizationController(organizationService, organizationInvitationService), nil
 		},
 	)
 }
@@ -0,0 +1,34 @@
+package script
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user"
+	"github.com/Caknoooo/go-gin-clean-starter/modules/user/repository"
+	"gorm.io/gorm"
+)
+
+type (
+	// PurgeDeletedUsersScript runs the purge_deleted_users job once, e.g. when
+	// the scheduler is disabled.
+	PurgeDeletedUsersScript struct {
+		userRepository repository.UserRepository
+	}
+)
+
+func NewPurgeDeletedUsersScript(db *gorm.DB) *PurgeDeletedUsersScript {
+	return &PurgeDeletedUsersScript{
+		userRepository: repository.NewUserRepository(db),
+	}
+}
+
+func (s *PurgeDeletedUsersScript) Run() error {
+	purged, err := user.PurgeDeletedUsers(context.Background(), s.userRepository)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("purged %d deleted users\n", purged)
+	return nil
+}
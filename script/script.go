@@ -11,6 +11,8 @@ func Script(scriptName string, db *gorm.DB) error {
 	case "example_script":
 		exampleScript := NewExampleScript(db)
 		return exampleScript.Run()
+	case "purge_deleted_users":
+		return NewPurgeDeletedUsersScript(db).Run()
 	default:
 		return errors.New("script not found")
 	}